@@ -1 +1,2 @@
-import "./ss-bot/ss-bot.mjs"
\ No newline at end of file
+import "./ss-bot/ss-bot.mjs"
+import "./ss-timecode/ss-timecode.mjs"
@@ -49,6 +49,15 @@ export class BotComponent extends AbstractComponent  {
   #IsMovement = false
   get IsMovement() { return this.#IsMovement }
 
+  #IsDegraded = false
+  get IsDegraded() { return this.#IsDegraded }
+
+  #DegradedError = ""
+  get DegradedError() { return this.#DegradedError }
+
+  #IsHomeRequired = false
+  get IsHomeRequired() { return this.#IsHomeRequired }
+
   #COM_ACTION = ""
   get COM_ACTION() { return this.#COM_ACTION }
 
@@ -89,6 +98,8 @@ export class BotComponent extends AbstractComponent  {
   
   #infoSectionNode = this.$("#infoSection")
   #moveSectionNode = this.$("#moveSection")
+  #homeSectionNode = this.$("#homeSection")
+  #homeButtonNode = this.$("#homeButton")
 
   constructor() {
     super(HTML, CSS)
@@ -100,6 +111,7 @@ export class BotComponent extends AbstractComponent  {
     }
     this.#infoSectionNode.innerHTML = ""
     this.#infoSectionNode.appendChild(this.#infoTemplate())
+    this.#homeSectionNode.hidden = !this.#IsHomeRequired
     this.#isDataReflected = true
 
     if (this.#isMoveReflected) {
@@ -137,6 +149,10 @@ export class BotComponent extends AbstractComponent  {
       this.#TagId = botData.tagID
       this.#IsMovement = botData.isMovement
 
+      this.#IsDegraded = botData.isDegraded
+      this.#DegradedError = botData.degradedError
+      this.#IsHomeRequired = botData.isHomeRequired
+
       this.#COM_ACTION = botData.COM_ACTION
       this.#COM_ROUNDM = botData.COM_ROUNDM
 
@@ -159,14 +175,21 @@ export class BotComponent extends AbstractComponent  {
     }
   }
 
+  #homeClick = () => this.#botTeam.Home(Number.parseInt(this.id))
+
   async connectedCallback() {
     this.#botTeam = await new BotTeam()
+    this.#homeButtonNode.addEventListener("click", this.#homeClick)
     const id = Number.parseInt(this.id)
     this.#getBotDataLoop(id)
         .then(error => console.error(`[BotTeam ERROR] Data loop then error: ${error}`))
         .catch(error => console.error(`[BotTeam ERROR] Data loop catch error: ${error}`))
     this.#renderLoop()
   }
+
+  disconnectedCallback() {
+    this.#homeButtonNode.removeEventListener("click", this.#homeClick)
+  }
 }
 
 customElements.define("ss-bot", BotComponent)
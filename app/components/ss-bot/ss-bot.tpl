@@ -32,12 +32,21 @@
 		<span><strong>isMovement:</strong>&emsp;&emsp;${this.IsMovement}</span>
 		<span><strong>tagId:</strong>&emsp;&emsp;${this.TagId}</span>
 	</p>
+	<p>
+		<span><strong>isDegraded:</strong>&emsp;&emsp;${this.IsDegraded}</span>
+		<span><strong>isHomeRequired:</strong>&emsp;&emsp;${this.IsHomeRequired}</span>
+		<span><strong>degradedError:</strong>&emsp;&emsp;${this.DegradedError || "Ø"}</span>
+	</p>
 </template>
 
 <section id="infoSection" class="bot-info">
 	
 </section>
 
+<section id="homeSection" class="bot-home" hidden>
+	<button id="homeButton">Drive HOME</button>
+</section>
+
 <section id="moveSection" class="bot-move">
 	
 </section>
\ No newline at end of file
@@ -0,0 +1,82 @@
+import { AbstractComponent } from "../abstract/index.mjs"
+import { BotTeam } from "../../services/bot-team.mjs"
+
+import { Template } from "../../internals/template.mjs"
+
+import CSS from "./ss-timecode.css" with { type: "css" }
+const HTML = await (await fetch(import.meta.resolve("./ss-timecode.tpl"))).text()
+
+export class TimecodeComponent extends AbstractComponent  {
+
+  #botTeam = undefined
+
+  #State = ""
+  get State() { return this.#State }
+
+  #IsLocked = false
+  get IsLocked() { return this.#IsLocked }
+
+  #Source = ""
+  get Source() { return this.#Source }
+
+  #Timecode = ""
+  get Timecode() { return this.#Timecode }
+
+  #FPS = 0
+  get FPS() { return this.#FPS }
+
+  #Timeline = ""
+  get Timeline() { return this.#Timeline }
+
+  #Relocates = 0
+  get Relocates() { return this.#Relocates }
+
+  #isDataReflected = true
+
+  #infoTemplate = Template(this.$("#infoTemplate").innerHTML).bind(this)
+
+  #infoSectionNode = this.$("#infoSection")
+
+  constructor() {
+    super(HTML, CSS)
+  }
+
+  #render = () => {
+    if (this.#isDataReflected) {
+      return
+    }
+    this.#infoSectionNode.innerHTML = ""
+    this.#infoSectionNode.appendChild(this.#infoTemplate())
+    this.hidden = false
+    this.#isDataReflected = true
+  }
+
+  #renderLoop = () => {
+    this.#render()
+    requestAnimationFrame(this.#renderLoop)
+  }
+
+  #getTimecodeDataLoop = async () => {
+    const timecodeIterator = this.#botTeam.TimecodeIterator()
+    for await (const timecodeData of timecodeIterator) {
+      this.#State = timecodeData.state
+      this.#IsLocked = timecodeData.isLocked
+      this.#Source = timecodeData.source
+      this.#Timecode = timecodeData.timecode
+      this.#FPS = timecodeData.fps
+      this.#Timeline = timecodeData.timeline
+      this.#Relocates = timecodeData.relocates
+      this.#isDataReflected = false
+    }
+  }
+
+  async connectedCallback() {
+    this.#botTeam = await new BotTeam()
+    this.#getTimecodeDataLoop()
+        .then(error => console.error(`[BotTeam ERROR] Timecode loop then error: ${error}`))
+        .catch(error => console.error(`[BotTeam ERROR] Timecode loop catch error: ${error}`))
+    this.#renderLoop()
+  }
+}
+
+customElements.define("ss-timecode", TimecodeComponent)
@@ -0,0 +1,17 @@
+<template id="infoTemplate">
+	<h1>Timecode:&emsp;&emsp;<span class="${this.IsLocked ? "locked" : "unlocked"}">${this.State || "Ø"}</span></h1>
+	<p>
+		<span><strong>timecode:</strong>&emsp;&emsp;${this.Timecode || "Ø"}</span>
+		<span><strong>isLocked:</strong>&emsp;&emsp;${this.IsLocked}</span>
+		<span><strong>source:</strong>&emsp;&emsp;${this.Source || "Ø"}</span>
+	</p>
+	<p>
+		<span><strong>timeline:</strong>&emsp;&emsp;${this.Timeline || "Ø"}</span>
+		<span><strong>fps:</strong>&emsp;&emsp;${this.FPS}</span>
+		<span><strong>relocates:</strong>&emsp;&emsp;${this.Relocates}</span>
+	</p>
+</template>
+
+<section id="infoSection" class="timecode-info">
+	
+</section>
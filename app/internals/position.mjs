@@ -51,6 +51,30 @@ export class Position {
   }
 }
 
+export class RawPositionStep {
+  #position = undefined
+
+  constructor(values) {
+    this.#position = new Position(PositionType_NIL, values)
+  }
+
+  toString() {
+    return `{RAW: ${this.#position}}`
+  }
+}
+
+export class InternalActionStep {
+  #name = ""
+
+  constructor(name) {
+    this.#name = name
+  }
+
+  toString() {
+    return `{ACTION: ${this.#name}}`
+  }
+}
+
 export class MoveGroup {
   #id = ""
   get Id() { return this.#id } 
@@ -60,7 +84,15 @@ export class MoveGroup {
   constructor(id, positions) {
     this.#id = id
     if (Array.isArray(positions)) {
-      this.#positions = positions.map(value => new Position(PositionType_NIL, value))
+      this.#positions = positions.map(value => {
+        if (typeof value === "string") {
+          return new InternalActionStep(value)
+        }
+        if (Array.isArray(value) === false) {
+          return new RawPositionStep(value.raw)
+        }
+        return new Position(PositionType_NIL, value)
+      })
     }
   }
 
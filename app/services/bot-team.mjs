@@ -1,7 +1,6 @@
 
-const API_PATH = "/bots"
-const API_REQUEST_TIMEOUT = 10 // 1 * 1000
-const API_ERROR_TIMEOUT = 5 * 1000
+const API_HOME_PATH = "/bots/home"
+const API_STREAM_PATH = "/bots/stream"
 
 class BotIterator {
   #messageQueue = []
@@ -35,37 +34,68 @@ export class BotTeam {
   #botTeam = []
   #botIterators = new Map()
 
+  #timecode = undefined
+  #timecodeIterator = undefined
+
 	constructor() {
     return (BotTeam.#instance = BotTeam.#instance ?? this.#init())
   }
 
   #init = async (offer) => {
-    this.#loop()
-        .catch(error => console.error(`[BotTeam ERROR] Loop catch error: ${error}`))
-        .then(error => console.error(`[BotTeam ERROR] Loop then error: ${error}`))
+    // EventSource reconnects by itself, the server sends full bot snapshots on every connection
+    const eventSource = new EventSource(API_STREAM_PATH)
+    eventSource.addEventListener("bot", this.#onBotEvent)
+    eventSource.addEventListener("timecode", this.#onTimecodeEvent)
+    eventSource.addEventListener("error", () => console.error(`[BotTeam ERROR] Stream error, reconnecting`))
     return this
   }
 
-  #loop = async () => {
-    while (true) {
-      await new Promise(resolve => setTimeout(resolve, API_REQUEST_TIMEOUT))
-      try {
-        const response = await fetch(API_PATH)
-        if (!response.ok) {
-          throw new Error(`HTTP GET error! Status: ${response.status} Body: ${await response.body()}`)
-        }
-        this.#botTeam = await response.json()
-      } catch (error) {
-        console.error(`[BotTeam ERROR] Loop error: ${error}`)
-        await new Promise(resolve => setTimeout(resolve, API_ERROR_TIMEOUT))
-      }
+  #onBotEvent = event => {
+    let frame
+    try {
+      frame = JSON.parse(event.data)
+    } catch (error) {
+      console.error(`[BotTeam ERROR] Stream frame error: ${error}`)
+      return
+    }
 
-      this.#botIterators.forEach((botIterator, id) => {
-        const value = this.#botTeam[id]
-        if (typeof value == "object") {
-          botIterator.Next(value)
-        } 
-      })
+    const { id, data } = frame
+    this.#botTeam[id] = Object.assign(this.#botTeam[id] ?? {}, data)
+    if (this.#botTeam[id].moveGroups === undefined) {
+      return
+    }
+
+    const botIterator = this.#botIterators.get(id)
+    if (botIterator !== undefined) {
+      botIterator.Next(this.#botTeam[id])
+    }
+  }
+
+  #onTimecodeEvent = event => {
+    let frame
+    try {
+      frame = JSON.parse(event.data)
+    } catch (error) {
+      console.error(`[BotTeam ERROR] Timecode frame error: ${error}`)
+      return
+    }
+
+    this.#timecode = Object.assign(this.#timecode ?? {}, frame.timecode)
+    if (this.#timecodeIterator !== undefined) {
+      this.#timecodeIterator.Next(this.#timecode)
+    }
+  }
+
+  Home = async id => {
+    const body = new FormData()
+    body.append("botId", String(id))
+    try {
+      const response = await fetch(API_HOME_PATH, { method: "POST", body })
+      if (!response.ok) {
+        throw new Error(`HTTP POST error! Status: ${response.status} Body: ${await response.text()}`)
+      }
+    } catch (error) {
+      console.error(`[BotTeam ERROR] Home error: ${error}`)
     }
   }
 
@@ -74,4 +104,13 @@ export class BotTeam {
     this.#botIterators.set(id, botIterator)
     return botIterator
   }
+
+  // TimecodeIterator yields the timecode lock state, the stream sends nothing when timecode is not configured
+  TimecodeIterator = () => {
+    this.#timecodeIterator = new BotIterator()
+    if (this.#timecode !== undefined) {
+      this.#timecodeIterator.Next(this.#timecode)
+    }
+    return this.#timecodeIterator
+  }
 }
@@ -20,6 +20,11 @@ case "$1" in
     echo "---> Build KUKA-C3-OSC-Gate for ${TARGET_OS}-${TARGET_ARCH} to build/kuka-c3-osc-gate${TARGET_EXT}"
     eval 'GOOS=${TARGET_OS} GOARCH=${TARGET_ARCH} go build -mod=vendor -ldflags "-w -s -X main.version=${VERSION}" -o ./build/kuka-c3-osc-gate${TARGET_EXT} ./cmd/kuka-c3-osc-gate'
     cp ./kuka-c3-osc-gate.json ./build/kuka-c3-osc-gate.json
+    cp ./kuka-c3-osc-gate.schema.json ./build/kuka-c3-osc-gate.schema.json
+    ;;
+  schema)
+    echo "---> Generate KUKA-C3-OSC-Gate config JSON Schema to kuka-c3-osc-gate.schema.json"
+    eval 'go run -mod=vendor ./cmd/kuka-c3-osc-gate schema > ./kuka-c3-osc-gate.schema.json'
     ;;
   run)
     echo "---> Running KUKA-C3-OSC-Gate"
@@ -1,5 +1,9 @@
 package main
 
+import (
+  "time"
+)
+
 type BotApp struct {
 	Name    string `json:"name"`
   Address string `json:"address"`
@@ -19,6 +23,16 @@ type BotApp struct {
   TagId 		 uint16 `json:"tagID"`
   IsMovement bool   `json:"isMovement"`
 
+  IsConnected    bool   `json:"isConnected"`
+  IsDegraded     bool   `json:"isDegraded"`
+  DegradedError  string `json:"degradedError"`
+  IsHomeRequired bool   `json:"isHomeRequired"`
+
+  State         BotState   `json:"state"`
+  LastError     string     `json:"lastError"`
+  LastErrorTime *time.Time `json:"lastErrorTime"`
+  LastPollTime  *time.Time `json:"lastPollTime"`
+
   COM_ACTION string `json:"COM_ACTION"`
  	COM_ROUNDM string `json:"COM_ROUNDM"`
 
@@ -0,0 +1,73 @@
+package main
+
+import (
+  "fmt"
+  "math"
+  "sort"
+)
+
+// BotLimits are software limits of a robot checked for MoveGroup positions and direct moves.
+// Axes limits are [min, max] of A1..A6 in degrees and E1..E6 in degrees or mm.
+// Reach is the maximum distance of an E6POS target from the robot base in mm, null skips the check.
+type BotLimits struct {
+  Axes  map[string][2]float32 `json:"axes"`
+  Reach *float32              `json:"reach"`
+}
+
+func (l *BotLimits) Check() error {
+  for _, name := range l.axisNames() {
+    target, ok := OSCMapping_Targets[name]
+    if ok == false || target.positionType != PositionType_E6AXIS {
+      return fmt.Errorf("Limits incorrect axis %q", name)
+    }
+    if limit := l.Axes[name]; limit[0] > limit[1] {
+      return fmt.Errorf("Limits axis %s min %f is greater than max %f", name, limit[0], limit[1])
+    }
+  }
+
+  if l.Reach != nil && *l.Reach <= 0 {
+    return fmt.Errorf("Limits incorrect reach %f", *l.Reach)
+  }
+  return nil
+}
+
+// CheckPosition reports the first axis out of limits or an unreachable Cartesian target
+func (l *BotLimits) CheckPosition(p *Position) error {
+  if l == nil || p == nil {
+    return nil
+  }
+
+  switch p.Type() {
+    case PositionType_E6AXIS:
+      for _, name := range l.axisNames() {
+        target, ok := OSCMapping_Targets[name]
+        if ok == false || target.positionType != PositionType_E6AXIS {
+          continue
+        }
+        limit := l.Axes[name]
+        if value := p.Get(target.index); value < limit[0] || value > limit[1] {
+          return fmt.Errorf("Axis %s %f is out of limits [%f, %f]", name, value, limit[0], limit[1])
+        }
+      }
+
+    case PositionType_E6POS:
+      if l.Reach == nil {
+        return nil
+      }
+      x, y, z := float64(p.X(nil)), float64(p.Y(nil)), float64(p.Z(nil))
+      if distance := math.Sqrt(x * x + y * y + z * z); distance > float64(*l.Reach) {
+        return fmt.Errorf("Target %s is %.1f mm from the base, reach is %.1f mm", p.Coords(), distance, *l.Reach)
+      }
+  }
+  return nil
+}
+
+// axisNames keeps reports stable across runs
+func (l *BotLimits) axisNames() []string {
+  names := make([]string, 0, len(l.Axes))
+  for name := range l.Axes {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+  return names
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+  "fmt"
+  "time"
+)
+
+const (
+  Bot_Poll_StaleTimeout = 2 * Bot_C3_Request_Timeout
+)
+
+type BotState string
+
+const (
+  BotState_Connecting BotState = "connecting" // C3 connection is not established yet or is lost
+  BotState_Connected  BotState = "connected"  // Robot is polled and idle
+  BotState_Moving     BotState = "moving"     // Robot is moving
+  BotState_Faulted    BotState = "faulted"    // Robot is connected, but position polling fails or is stale
+  BotState_Degraded   BotState = "degraded"   // Bot failed to start and is out of service until restart or reload
+)
+
+// BotStateApp is the health of a bot for supervisors, times are null until the first error or poll
+type BotStateApp struct {
+  Name          string     `json:"name"`
+  State         BotState   `json:"state"`
+  LastError     string     `json:"lastError"`
+  LastErrorTime *time.Time `json:"lastErrorTime"`
+  LastPollTime  *time.Time `json:"lastPollTime"`
+}
+
+func (bot *Bot) State() BotState {
+  bot.stateMux.RLock()
+  isDegraded, lastPoll, isPollFailed := bot.isDegraded, bot.lastPollTime, bot.isPollFailed
+  bot.stateMux.RUnlock()
+
+  switch {
+    case isDegraded == true:
+      return BotState_Degraded
+    case bot.c3Client == nil || bot.IsConnected() == false:
+      return BotState_Connecting
+    case isPollFailed == true || time.Since(lastPoll) > Bot_Poll_StaleTimeout:
+      return BotState_Faulted
+    case bot.IsMovement() == true:
+      return BotState_Moving
+  }
+  return BotState_Connected
+}
+
+// IsReady is true when the bot can be commanded
+func (bot *Bot) IsReady() bool {
+  switch bot.State() {
+    case BotState_Connected, BotState_Moving:
+      return true
+  }
+  return false
+}
+
+func (bot *Bot) GetStateApp() *BotStateApp {
+  state := bot.State()
+
+  bot.stateMux.RLock()
+  defer bot.stateMux.RUnlock()
+
+  stateApp := &BotStateApp{Name: bot.Name, State: state}
+  if bot.lastError != nil {
+    stateApp.LastError = bot.lastError.Error()
+    lastErrorTime := bot.lastErrorTime
+    stateApp.LastErrorTime = &lastErrorTime
+  }
+  if bot.lastPollTime.IsZero() == false {
+    lastPollTime := bot.lastPollTime
+    stateApp.LastPollTime = &lastPollTime
+  }
+  return stateApp
+}
+
+// setError keeps the last error of the bot for health reports
+func (bot *Bot) setError(err error) {
+  bot.stateMux.Lock()
+  bot.lastError = err
+  bot.lastErrorTime = time.Now()
+  bot.stateMux.Unlock()
+}
+
+// polled records the result of a position poll
+func (bot *Bot) polled(err error) {
+  bot.metrics.positionUpdate(err)
+  if err != nil {
+    bot.setError(fmt.Errorf("Position poll error: %w", err))
+  }
+
+  bot.stateMux.Lock()
+  bot.isPollFailed = err != nil
+  if err == nil {
+    bot.lastPollTime = time.Now()
+  }
+  bot.stateMux.Unlock()
+}
@@ -0,0 +1,529 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "io"
+  "log"
+  "net/http"
+  "sort"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  BotStream_Interval  = 20 * time.Millisecond
+  BotStream_KeepAlive = 15 * time.Second
+)
+
+// BotStreamFrame is a BotApp delta: only fields changed since the previous frame of the bot.
+// The first frame of a subscription is the full BotApp with moveGroups, later frames carry
+// moveGroups only when they are edited.
+type BotStreamFrame struct {
+  Id   int                        `json:"id"`
+  Data map[string]json.RawMessage `json:"data"`
+}
+
+// TimecodeStreamFrame is a TimecodeApp delta, sent to every client when timecode is configured.
+// SSE clients receive it as a timecode event.
+type TimecodeStreamFrame struct {
+  Timecode map[string]json.RawMessage `json:"timecode"`
+}
+
+// BotStreamRequest is a WebSocket client message, SSE clients subscribe with ?bots=0,1
+type BotStreamRequest struct {
+  Subscribe   []int `json:"subscribe"`
+  Unsubscribe []int `json:"unsubscribe"`
+}
+
+// BotStream polls bot state once for all clients and pushes deltas on change
+type BotStream struct {
+  botsTeam *Team
+
+  clients    map[*botStreamClient]struct{}
+  clientsMux sync.Mutex
+
+  last     map[*Bot]map[string]json.RawMessage
+  versions map[*Bot]uint64
+  timecode map[string]json.RawMessage
+
+  doneChan chan struct{}
+  wg       sync.WaitGroup
+}
+
+// botStreamClient keeps one pending delta per bot: a newer delta is merged into a frame
+// which is not written yet, so a slow client drops stale values instead of blocking the stream
+type botStreamClient struct {
+  bots       map[int]bool
+  pending    map[int]map[string]json.RawMessage
+  timecode   map[string]json.RawMessage
+  mux        sync.Mutex
+  notifyChan chan struct{}
+}
+
+func NewBotStream(botsTeam *Team) *BotStream {
+  return &BotStream{
+    botsTeam: botsTeam,
+    clients:  make(map[*botStreamClient]struct{}),
+    last:     make(map[*Bot]map[string]json.RawMessage),
+    versions: make(map[*Bot]uint64),
+    doneChan: make(chan struct{}),
+  }
+}
+
+func (bs *BotStream) Up() {
+  bs.wg.Add(1)
+  go bs.processState()
+}
+
+func (bs *BotStream) Shutdown() {
+  close(bs.doneChan)
+  bs.wg.Wait()
+}
+
+func (bs *BotStream) processState() {
+  defer bs.wg.Done()
+
+  ticker := time.NewTicker(BotStream_Interval)
+  defer ticker.Stop()
+
+  for {
+    select {
+      case <-bs.doneChan:
+        return
+      case <-ticker.C:
+    }
+
+    bs.clientsMux.Lock()
+    isEmpty := len(bs.clients) == 0
+    bs.clientsMux.Unlock()
+    if isEmpty == true {
+      continue
+    }
+
+    bs.processTimecode()
+
+    bots := bs.botsTeam.GetBots()
+    for id, bot := range bots {
+      state, err := botStreamFields(bot.GetStateAppData())
+      if err != nil {
+        log.Printf("[BotStream ERROR] Bot %s state error: %v\n", bot.Name, err)
+        continue
+      }
+      // tagID and lastPollTime advance on every C3 request and poll, they are sent with snapshots only
+      delete(state, "moveGroups")
+      delete(state, "tagID")
+      delete(state, "lastPollTime")
+
+      delta := make(map[string]json.RawMessage)
+      for name, value := range state {
+        if last, ok := bs.last[bot][name]; ok == false || bytes.Equal(last, value) == false {
+          delta[name] = value
+        }
+      }
+      _, isKnown := bs.last[bot]
+      bs.last[bot] = state
+
+      if version := bot.MoveGroupsVersion(); isKnown == false || bs.versions[bot] != version {
+        moveGroups, err := json.Marshal(bot.GetMoveGroups())
+        if err != nil {
+          log.Printf("[BotStream ERROR] Bot %s MoveGroups error: %v\n", bot.Name, err)
+        } else {
+          delta["moveGroups"] = moveGroups
+          bs.versions[bot] = version
+        }
+      }
+
+      if len(delta) > 0 {
+        bs.push(id, delta)
+      }
+    }
+
+    // Bots removed or restarted by a config reload are forgotten
+    if len(bs.last) > len(bots) {
+      current := make(map[*Bot]bool, len(bots))
+      for _, bot := range bots {
+        current[bot] = true
+      }
+      for bot := range bs.last {
+        if current[bot] == false {
+          delete(bs.last, bot)
+          delete(bs.versions, bot)
+        }
+      }
+    }
+  }
+}
+
+// processTimecode pushes timecode lock changes, position is sent with the timecode string only
+func (bs *BotStream) processTimecode() {
+  if bs.botsTeam.Timecode == nil {
+    return
+  }
+
+  state, err := timecodeStreamFields(bs.botsTeam.Timecode)
+  if err != nil {
+    log.Printf("[BotStream ERROR] Timecode state error: %v\n", err)
+    return
+  }
+
+  // Connected clients already got the first state as a snapshot on subscribe
+  if bs.timecode == nil {
+    bs.timecode = state
+    return
+  }
+
+  delta := make(map[string]json.RawMessage)
+  for name, value := range state {
+    if last, ok := bs.timecode[name]; ok == false || bytes.Equal(last, value) == false {
+      delta[name] = value
+    }
+  }
+  bs.timecode = state
+
+  if len(delta) > 0 {
+    bs.clientsMux.Lock()
+    defer bs.clientsMux.Unlock()
+    for client := range bs.clients {
+      client.pushTimecode(delta)
+    }
+  }
+}
+
+func (bs *BotStream) push(id int, delta map[string]json.RawMessage) {
+  bs.clientsMux.Lock()
+  defer bs.clientsMux.Unlock()
+  for client := range bs.clients {
+    client.push(id, delta)
+  }
+}
+
+func (bs *BotStream) addClient(client *botStreamClient) {
+  bs.clientsMux.Lock()
+  bs.clients[client] = struct{}{}
+  bs.clientsMux.Unlock()
+}
+
+func (bs *BotStream) removeClient(client *botStreamClient) {
+  bs.clientsMux.Lock()
+  delete(bs.clients, client)
+  bs.clientsMux.Unlock()
+}
+
+// subscribe queues a full snapshot of every new bot of the client
+func (bs *BotStream) subscribe(client *botStreamClient, ids []int) {
+  for _, id := range ids {
+    bot := bs.botsTeam.GetBot(id)
+    if bot == nil {
+      continue
+    }
+
+    client.mux.Lock()
+    isNew := client.bots[id] == false
+    client.bots[id] = true
+    client.mux.Unlock()
+    if isNew == false {
+      continue
+    }
+
+    snapshot, err := botStreamFields(bot.GetAppData())
+    if err != nil {
+      log.Printf("[BotStream ERROR] Bot %s snapshot error: %v\n", bot.Name, err)
+      continue
+    }
+    client.push(id, snapshot)
+  }
+}
+
+// subscribeTimecode queues a full timecode snapshot for a new client
+func (bs *BotStream) subscribeTimecode(client *botStreamClient) {
+  if bs.botsTeam.Timecode == nil {
+    return
+  }
+
+  snapshot, err := timecodeStreamFields(bs.botsTeam.Timecode)
+  if err != nil {
+    log.Printf("[BotStream ERROR] Timecode snapshot error: %v\n", err)
+    return
+  }
+  client.pushTimecode(snapshot)
+}
+
+func (bs *BotStream) unsubscribe(client *botStreamClient, ids []int) {
+  client.mux.Lock()
+  defer client.mux.Unlock()
+  for _, id := range ids {
+    delete(client.bots, id)
+    delete(client.pending, id)
+  }
+}
+
+func (bs *BotStream) allIds() []int {
+  ids := make([]int, len(bs.botsTeam.GetBots()))
+  for i := range ids {
+    ids[i] = i
+  }
+  return ids
+}
+
+func newBotStreamClient() *botStreamClient {
+  return &botStreamClient{
+    bots:       make(map[int]bool),
+    pending:    make(map[int]map[string]json.RawMessage),
+    notifyChan: make(chan struct{}, 1),
+  }
+}
+
+func (c *botStreamClient) push(id int, delta map[string]json.RawMessage) {
+  c.mux.Lock()
+  if c.bots[id] == false {
+    c.mux.Unlock()
+    return
+  }
+
+  pending, ok := c.pending[id]
+  if ok == false {
+    pending = make(map[string]json.RawMessage, len(delta))
+    c.pending[id] = pending
+  }
+  for name, value := range delta {
+    pending[name] = value
+  }
+  c.mux.Unlock()
+
+  select {
+    case c.notifyChan <- struct{}{}:
+    default:
+  }
+}
+
+func (c *botStreamClient) pushTimecode(delta map[string]json.RawMessage) {
+  c.mux.Lock()
+  if c.timecode == nil {
+    c.timecode = make(map[string]json.RawMessage, len(delta))
+  }
+  for name, value := range delta {
+    c.timecode[name] = value
+  }
+  c.mux.Unlock()
+
+  select {
+    case c.notifyChan <- struct{}{}:
+    default:
+  }
+}
+
+// timecodeFrame takes the pending timecode delta, nil when there is none
+func (c *botStreamClient) timecodeFrame() *TimecodeStreamFrame {
+  c.mux.Lock()
+  defer c.mux.Unlock()
+
+  if c.timecode == nil {
+    return nil
+  }
+  frame := &TimecodeStreamFrame{Timecode: c.timecode}
+  c.timecode = nil
+  return frame
+}
+
+// frames takes every pending delta ordered by bot id
+func (c *botStreamClient) frames() []*BotStreamFrame {
+  c.mux.Lock()
+  defer c.mux.Unlock()
+
+  frames := make([]*BotStreamFrame, 0, len(c.pending))
+  for id, data := range c.pending {
+    frames = append(frames, &BotStreamFrame{Id: id, Data: data})
+  }
+  c.pending = make(map[int]map[string]json.RawMessage)
+
+  sort.Slice(frames, func(i, j int) bool { return frames[i].Id < frames[j].Id })
+  return frames
+}
+
+// Handler serves WebSocket clients and Server-Sent Events clients on the same path
+func (bs *BotStream) Handler(w http.ResponseWriter, r *http.Request) {
+  if r.Method != "GET" {
+    http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+    return
+  }
+
+  ids := bs.allIds()
+  if value := r.URL.Query().Get("bots"); value != "" {
+    var err error
+    if ids, err = botStreamIds(value); err != nil {
+      http.Error(w, err.Error(), http.StatusBadRequest)
+      return
+    }
+  }
+
+  if IsWebSocketRequest(r) {
+    bs.serveWebSocket(w, r, ids)
+    return
+  }
+  bs.serveEvents(w, r, ids)
+}
+
+func (bs *BotStream) serveEvents(w http.ResponseWriter, r *http.Request, ids []int) {
+  flusher, ok := w.(http.Flusher)
+  if ok == false {
+    http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+    return
+  }
+
+  client := newBotStreamClient()
+  bs.addClient(client)
+  defer bs.removeClient(client)
+  bs.subscribe(client, ids)
+  bs.subscribeTimecode(client)
+
+  w.Header().Set("Content-Type", "text/event-stream")
+  w.Header().Set("Cache-Control", "no-cache")
+  w.Header().Set("Connection", "keep-alive")
+  w.WriteHeader(http.StatusOK)
+  flusher.Flush()
+
+  keepAlive := time.NewTicker(BotStream_KeepAlive)
+  defer keepAlive.Stop()
+
+  for {
+    select {
+      case <-bs.doneChan:
+        return
+      case <-r.Context().Done():
+        return
+      case <-keepAlive.C:
+        if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+          return
+        }
+      case <-client.notifyChan:
+        if frame := client.timecodeFrame(); frame != nil {
+          data, err := json.Marshal(frame)
+          if err != nil {
+            log.Printf("[BotStream ERROR] Timecode frame json error: %v\n", err)
+          } else if _, err := fmt.Fprintf(w, "event: timecode\ndata: %s\n\n", data); err != nil {
+            return
+          }
+        }
+        for _, frame := range client.frames() {
+          data, err := json.Marshal(frame)
+          if err != nil {
+            log.Printf("[BotStream ERROR] Frame json error: %v\n", err)
+            continue
+          }
+          if _, err := fmt.Fprintf(w, "event: bot\ndata: %s\n\n", data); err != nil {
+            return
+          }
+        }
+    }
+    flusher.Flush()
+  }
+}
+
+func (bs *BotStream) serveWebSocket(w http.ResponseWriter, r *http.Request, ids []int) {
+  ws, err := UpgradeWebSocket(w, r)
+  if err != nil {
+    log.Printf("[BotStream ERROR] WebSocket upgrade error: %v\n", err)
+    return
+  }
+  defer ws.Close()
+
+  client := newBotStreamClient()
+  bs.addClient(client)
+  defer bs.removeClient(client)
+  bs.subscribe(client, ids)
+  bs.subscribeTimecode(client)
+
+  readDone := make(chan struct{})
+  go func() {
+    defer close(readDone)
+    for {
+      opcode, data, err := ws.ReadMessage()
+      if err != nil {
+        return
+      }
+      if opcode != WebSocket_Text {
+        continue
+      }
+
+      var request BotStreamRequest
+      if err := json.Unmarshal(data, &request); err != nil {
+        log.Printf("[BotStream WARNING] WebSocket request error: %v\n", err)
+        continue
+      }
+      bs.subscribe(client, request.Subscribe)
+      bs.unsubscribe(client, request.Unsubscribe)
+    }
+  }()
+
+  for {
+    select {
+      case <-bs.doneChan:
+        ws.WriteMessage(WebSocket_Close, nil)
+        return
+      case <-readDone:
+        return
+      case <-client.notifyChan:
+        if frame := client.timecodeFrame(); frame != nil {
+          data, err := json.Marshal(frame)
+          if err != nil {
+            log.Printf("[BotStream ERROR] Timecode frame json error: %v\n", err)
+          } else if err := ws.WriteMessage(WebSocket_Text, data); err != nil {
+            return
+          }
+        }
+        for _, frame := range client.frames() {
+          data, err := json.Marshal(frame)
+          if err != nil {
+            log.Printf("[BotStream ERROR] Frame json error: %v\n", err)
+            continue
+          }
+          if err := ws.WriteMessage(WebSocket_Text, data); err != nil {
+            return
+          }
+        }
+    }
+  }
+}
+
+// timecodeStreamFields leaves out age and position, they change on every tick
+func timecodeStreamFields(timecode *Timecode) (map[string]json.RawMessage, error) {
+  fields, err := streamFields(timecode.GetAppData())
+  if err != nil {
+    return nil, err
+  }
+  delete(fields, "age")
+  delete(fields, "position")
+  return fields, nil
+}
+
+func botStreamFields(botApp *BotApp) (map[string]json.RawMessage, error) {
+  return streamFields(botApp)
+}
+
+func streamFields(value any) (map[string]json.RawMessage, error) {
+  data, err := json.Marshal(value)
+  if err != nil {
+    return nil, err
+  }
+  var fields map[string]json.RawMessage
+  if err := json.Unmarshal(data, &fields); err != nil {
+    return nil, err
+  }
+  return fields, nil
+}
+
+func botStreamIds(value string) ([]int, error) {
+  var ids []int
+  for _, part := range strings.Split(value, ",") {
+    id, err := strconv.Atoi(strings.TrimSpace(part))
+    if err != nil {
+      return nil, fmt.Errorf("Incorrect bot id %q", part)
+    }
+    ids = append(ids, id)
+  }
+  return ids, nil
+}
@@ -4,7 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,12 +17,24 @@ const (
   Bot_PacketsBuffer = 512
   Bot_MessagesBuffer = 512
 
-  Bot_C3_Request_Timeout = 3 * time.Second
+  Bot_C3_Request_Timeout = C3Client_RequestTimeout
 
   Bot_Position_Tolerance = 0.0100
   Bot_Position_ReadySteps = 100
 
   Bot_Move_Timeout = 60 * time.Second
+
+  Bot_Home_Tolerance = Bot_Position_Tolerance
+  Bot_Home_Speed = 10
+  Bot_Home_RestoreSpeed = 100 // KRL default of $VEL_AXIS and $ACC_AXIS
+)
+
+type BotStartupPolicy string
+
+const (
+  BotStartupPolicy_Fail BotStartupPolicy = "fail" // Bot is degraded when it is not at HOME
+  BotStartupPolicy_Warn BotStartupPolicy = "warn" // Bot starts with a warning when it is not at HOME
+  BotStartupPolicy_Home BotStartupPolicy = "home" // Bot waits for operator confirmation to drive HOME
 )
 
 type Bot struct {
@@ -29,15 +46,45 @@ type Bot struct {
   OSCRequestPosition *string `json:"oscRequestPositionPath"`
 
   OSCResponseAddress  *string `json:"oscResponseAddress"`
+  OSCReplyToSender    *bool   `json:"oscReplyToSender"`
+
+  OSCDestinations     []*OSCDestination `json:"oscDestinations"`
+  teamOSCDestinations []*OSCDestination
   
   OSCResponseAxes     *string `json:"oscResponseAxes"`
   OSCResponseCoords   *string `json:"oscResponseCoords"`
   OSCResponsePosition *string `json:"oscResponsPosition"`
 
+  Telemetry     []*Telemetry `json:"telemetry"`
+  lastTelemetry *TelemetrySample
+
+  OSCMappings     []*OSCMapping `json:"oscMappings"`
+  mappingPaths    []string
+  mappingGroups   map[string][]*OSCMapping
+  mappingPending  *Position
+  mappingActive   *Position
+  isMappingMove   bool
+  mappingMux      sync.Mutex
+
+  Home             *Position         `json:"home"`
+  HomeTolerance    *float32          `json:"homeTolerance"`
+  HomeSpeed        *uint8            `json:"homeSpeed"`
+  HomeRestoreSpeed *uint8            `json:"homeRestoreSpeed"` // Axis speed after HOME when the previous one can not be read
+  StartupPolicy    *BotStartupPolicy `json:"startupPolicy"`
+  Limits           *BotLimits        `json:"limits"`
+
+  InternalActions     []*InternalAction `json:"internalActions"`
+  teamInternalActions []*InternalAction
+
+  // settingsMux guards OSC paths, outputs, telemetry, HOME, limits and InternalActions
+  // of a running bot, a config reload replaces them while the bot is in use
+  settingsMux sync.RWMutex
+
   MoveGroups []*MoveGroup `json:"moveGroups"`
+  moveGroupsVersion uint64
   moveGroupsMux sync.RWMutex
 
-  oscInput  chan *OSCPacket
+  oscInput  chan *oscCall
   moveInput chan *MoveGroup
 
   tagId    uint16
@@ -45,6 +92,10 @@ type Bot struct {
   
   c3Client  *C3Client
   oscClient *OSCClient
+  metrics   *BotMetrics
+  oscServer *OSCServer
+  oscOutput OSCHandler
+  events    *GateEvents
 
   isMovement bool
   isMovementMux sync.RWMutex
@@ -55,6 +106,7 @@ type Bot struct {
   c3COM_ROUNDM C3VariableComRoundmValues
   c3OFFSET     *Position
   c3POSITION   *Position
+  c3UpdateSeq  uint64
   positionMux sync.RWMutex
 
   c3PROXY_TYPE     string
@@ -64,7 +116,18 @@ type Bot struct {
   c3PROXY_PORT     string
   proxyMux       sync.RWMutex
 
-  isShutdown bool
+  isDegraded     bool
+  degradedError  error
+  isHomeRequired bool
+  lastError      error
+  lastErrorTime  time.Time
+  lastPollTime   time.Time
+  isPollFailed   bool
+  stateMux       sync.RWMutex
+
+  // isShutdown is read by bot goroutines, inputMux keeps senders to oscInput and moveInput off closed channels
+  isShutdown atomic.Bool
+  inputMux   sync.RWMutex
   wg sync.WaitGroup
 
   currentMoveGroupId uint16
@@ -81,6 +144,7 @@ func NewBot() (*Bot, error) {
     c3COM_ACTION: C3Variable_COM_ACTION_EMPTY,
     c3COM_ROUNDM: C3Variable_COM_ROUNDM_NONE,
     MoveGroups:   make([]*MoveGroup, 0),
+    metrics:      NewBotMetrics(),
   }, nil
 }
 
@@ -112,24 +176,60 @@ func (bot *Bot) UnmarshalJSON(data []byte) error {
   bot.c3OFFSET     = NewPosition(PositionType_E6POS)
   bot.c3COM_ACTION = C3Variable_COM_ACTION_EMPTY
   bot.c3COM_ROUNDM = C3Variable_COM_ROUNDM_NONE
+  bot.metrics      = NewBotMetrics()
   return nil
 }
 
 func (bot *Bot) Up() (err error) {
-  bot.oscInput = make(chan *OSCPacket, Bot_PacketsBuffer)
+  bot.oscInput = make(chan *oscCall, Bot_PacketsBuffer)
   bot.moveInput = make(chan *MoveGroup, Bot_PacketsBuffer)
-  bot.isShutdown = false
+  bot.isShutdown.Store(false)
+
+  bot.stateMux.Lock()
+  bot.isDegraded = false
+  bot.degradedError = nil
+  bot.isHomeRequired = false
+  bot.isPollFailed = false
+  bot.stateMux.Unlock()
+
+  defer func() {
+    if err != nil {
+      bot.degrade(err)
+    }
+  }()
 
-  if bot.c3Client, err = NewC3Client(bot.Address); err != nil {
+  if err := bot.Check(); err != nil {
+    return err
+  }
+  bot.lastTelemetry = nil
+
+  for _, moveGroup := range bot.MoveGroups {
+    for _, step := range moveGroup.Positions {
+      if step.IsAction() && bot.GetInternalAction(step.Action) == nil {
+        log.Printf("[Bot %s WARNING] MoveGroup %d InternalAction %s is not found\n", bot.Name, moveGroup.Id, step.Action)
+      }
+    }
+  }
+
+  policy := bot.startupPolicy()
+
+  if bot.c3Client, err = NewC3Client(bot.Address, bot.connectionEvent); err != nil {
     return fmt.Errorf("Bot %s C3Client creation error: %w", bot.Name, err)
   }
 
+  var oscClient *OSCClient
   if bot.OSCResponseAddress != nil {
-    if bot.oscClient, err = NewOSCClient(*bot.OSCResponseAddress); err != nil {
+    if oscClient, err = NewOSCClient(*bot.OSCResponseAddress); err != nil {
       return fmt.Errorf("Bot %s OSCClient creation error: %w", bot.Name, err)
     }
-  } else {
-    bot.oscClient = nil
+  }
+  bot.settingsMux.Lock()
+  bot.oscClient = oscClient
+  bot.settingsMux.Unlock()
+
+  // Destinations are shut down by degrade when a later step fails
+  if err := oscDestinationsUp(bot.OSCDestinations); err != nil {
+    return fmt.Errorf("Bot %s %w", bot.Name, err)
   }
 
   if err := bot.UpdateProxyInfo(); err != nil {
@@ -140,15 +240,21 @@ func (bot *Bot) Up() (err error) {
     return fmt.Errorf("Bot %s Position update error: %w", bot.Name, err)
   }
 
-  HOME := NewPosition(PositionType_E6AXIS)
-  bot.positionMux.RLock()
-  HOME.SetValues([14]float32{0, -90, 90, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
-  if bot.c3AXIS_ACT.Equal(HOME, Bot_Position_Tolerance) != true {
-     return fmt.Errorf("Bot %s is not HOME position", bot.Name)
-  }
+  if bot.IsHome() != true {
+    switch policy {
+      case BotStartupPolicy_Fail:
+        return fmt.Errorf("Bot %s is not HOME position", bot.Name)
 
-  bot.positionMux.RUnlock()
+      case BotStartupPolicy_Warn:
+        log.Printf("[Bot %s WARNING] Bot is not HOME position, offset is taken from the current position\n", bot.Name)
 
+      case BotStartupPolicy_Home:
+        log.Printf("[Bot %s WARNING] Bot is not HOME position, waiting for operator confirmation to drive HOME\n", bot.Name)
+        bot.stateMux.Lock()
+        bot.isHomeRequired = true
+        bot.stateMux.Unlock()
+    }
+  }
 
   if err := bot.ResetOffsetAndPosition(); err != nil {
     return fmt.Errorf("Bot %s Offset and Position update error: %w", bot.Name, err)
@@ -168,19 +274,250 @@ func (bot *Bot) Up() (err error) {
   return nil
 }
 
+// Check validates settings inherited from the team and prepares OSC mappings, it does not connect to the robot
+func (bot *Bot) Check() (err error) {
+  home := bot.homePosition()
+  if home.Type() != PositionType_E6AXIS {
+    return fmt.Errorf("Bot %s HOME position must be of E6AXIS type", bot.Name)
+  }
+
+  for _, action := range append(bot.InternalActions, bot.teamInternalActions...) {
+    if err := action.Check(); err != nil {
+      return fmt.Errorf("Bot %s %w", bot.Name, err)
+    }
+  }
+
+  if err := telemetryCheck(bot.Telemetry); err != nil {
+    return fmt.Errorf("Bot %s %w", bot.Name, err)
+  }
+
+  if bot.mappingPaths, bot.mappingGroups, err = oscMappingPaths(bot.OSCMappings); err != nil {
+    return fmt.Errorf("Bot %s %w", bot.Name, err)
+  }
+
+  if speed := bot.homeSpeed(); speed == 0 || speed > 100 {
+    return fmt.Errorf("Bot %s HOME speed %d is out of 1..100 range", bot.Name, speed)
+  }
+  if speed := bot.homeRestoreSpeed(); speed == 0 || speed > 100 {
+    return fmt.Errorf("Bot %s HOME restore speed %d is out of 1..100 range", bot.Name, speed)
+  }
+
+  policy := bot.startupPolicy()
+  switch policy {
+    case BotStartupPolicy_Fail, BotStartupPolicy_Warn, BotStartupPolicy_Home:
+    default:
+      return fmt.Errorf("Bot %s incorrect startup policy %q", bot.Name, policy)
+  }
+
+  if bot.Limits != nil {
+    if err := bot.Limits.Check(); err != nil {
+      return fmt.Errorf("Bot %s %w", bot.Name, err)
+    }
+  }
+  return nil
+}
+
+func (bot *Bot) degrade(err error) {
+  bot.stateMux.Lock()
+  bot.isDegraded = true
+  bot.degradedError = err
+  bot.stateMux.Unlock()
+  bot.setError(err)
+
+  bot.isShutdown.Store(true)
+  bot.settingsMux.Lock()
+  oscClient, destinations := bot.oscClient, bot.OSCDestinations
+  bot.oscClient = nil
+  bot.settingsMux.Unlock()
+  if oscClient != nil {
+    oscClient.Shutdown()
+  }
+  oscDestinationsShutdown(destinations)
+  if bot.c3Client != nil {
+    bot.c3Client.Shutdown()
+  }
+}
+
+func (bot *Bot) connectionEvent(isConnected bool) {
+  if isConnected == true {
+    log.Printf("[Bot %s INFO] C3 connection is restored\n", bot.Name)
+    bot.events.Event(GateEvent_ConnectionRestored, bot.Name)
+    return
+  }
+  log.Printf("[Bot %s WARNING] C3 connection is lost\n", bot.Name)
+  bot.setError(fmt.Errorf("C3 connection is lost"))
+  bot.events.Event(GateEvent_ConnectionLost, bot.Name)
+}
+
+func (bot *Bot) IsDegraded() bool {
+  bot.stateMux.RLock()
+  defer bot.stateMux.RUnlock()
+  return bot.isDegraded
+}
+
+func (bot *Bot) IsHomeRequired() bool {
+  bot.stateMux.RLock()
+  defer bot.stateMux.RUnlock()
+  return bot.isHomeRequired
+}
+
+func (bot *Bot) IsConnected() bool {
+  return bot.c3Client != nil && bot.c3Client.IsConnected()
+}
+
+func (bot *Bot) IsMovement() bool {
+  bot.isMovementMux.RLock()
+  defer bot.isMovementMux.RUnlock()
+  return bot.isMovement
+}
+
 func (bot *Bot) Shutdown() error {
-  bot.isShutdown = true
-  close(bot.oscInput)
-  close(bot.moveInput)
-  if bot.oscClient != nil {
-    bot.oscClient.Shutdown()
+  if bot.IsDegraded() == true {
+    log.Printf("[Bot %s INFO] Shutdown skipped, bot is degraded\n", bot.Name)
+    return nil
   }
+
+  bot.closeInput()
+  bot.settingsMux.RLock()
+  oscClient, destinations := bot.oscClient, bot.OSCDestinations
+  bot.settingsMux.RUnlock()
+  if oscClient != nil {
+    oscClient.Shutdown()
+  }
+  oscDestinationsShutdown(destinations)
   bot.c3Client.Shutdown()
   bot.wg.Wait()
   log.Printf("[Bot %s INFO] Shutdown successfully\n", bot.Name)
   return nil
 }
 
+// closeInput stops OSC and MoveGroup input, senders holding inputMux see isShutdown before the channels are closed
+func (bot *Bot) closeInput() {
+  bot.inputMux.Lock()
+  defer bot.inputMux.Unlock()
+  bot.isShutdown.Store(true)
+  close(bot.oscInput)
+  close(bot.moveInput)
+}
+
+func (bot *Bot) homePosition() *Position {
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+  if bot.Home != nil {
+    return bot.Home
+  }
+  home := NewPosition(PositionType_E6AXIS)
+  home.SetValues([14]float32{0, -90, 90, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+  return home
+}
+
+func (bot *Bot) homeTolerance() float32 {
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+  if bot.HomeTolerance != nil {
+    return *bot.HomeTolerance
+  }
+  return Bot_Home_Tolerance
+}
+
+func (bot *Bot) homeSpeed() uint8 {
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+  if bot.HomeSpeed != nil {
+    return *bot.HomeSpeed
+  }
+  return Bot_Home_Speed
+}
+
+func (bot *Bot) homeRestoreSpeed() uint8 {
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+  if bot.HomeRestoreSpeed != nil {
+    return *bot.HomeRestoreSpeed
+  }
+  return Bot_Home_RestoreSpeed
+}
+
+func (bot *Bot) startupPolicy() BotStartupPolicy {
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+  if bot.StartupPolicy != nil {
+    return *bot.StartupPolicy
+  }
+  return BotStartupPolicy_Fail
+}
+
+func (bot *Bot) limits() *BotLimits {
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+  return bot.Limits
+}
+
+func (bot *Bot) IsHome() bool {
+  bot.positionMux.RLock()
+  defer bot.positionMux.RUnlock()
+  return bot.c3AXIS_ACT.Equal(bot.homePosition(), bot.homeTolerance())
+}
+
+func (bot *Bot) CurrentAxis() *Position {
+  bot.positionMux.RLock()
+  defer bot.positionMux.RUnlock()
+  return bot.c3AXIS_ACT.Clone()
+}
+
+func (bot *Bot) ConfirmHome() error {
+  if bot.IsDegraded() == true {
+    return fmt.Errorf("Bot %s is degraded", bot.Name)
+  }
+
+  if bot.IsHomeRequired() != true {
+    return fmt.Errorf("Bot %s HOME confirmation is not required", bot.Name)
+  }
+
+  // The previous speed is restored after the drive, so HOME speed never leaks into the next moves.
+  // A program which never set COM_VALUE2 and COM_VALUE4 has no speed to read, the restore speed is set then.
+  previousVelocity, previousAcceleration, err := bot.GetAXISSpeed()
+  if err != nil {
+    previousVelocity = bot.homeRestoreSpeed()
+    previousAcceleration = previousVelocity
+    log.Printf("[Bot %s WARNING] Axis speed before HOME is unknown, %d%% is restored after HOME: %v\n", bot.Name, previousVelocity, err)
+  }
+
+  // Registered before the HOME speed is set, a partly applied speed request is restored too
+  defer func() {
+    if err := bot.SetAXISSpeed(previousVelocity, previousAcceleration); err != nil {
+      log.Printf("[Bot %s ERROR] Axis speed restore after HOME error: %v\n", bot.Name, err)
+      return
+    }
+    log.Printf("[Bot %s INFO] Axis speed is restored to %d%%\n", bot.Name, previousVelocity)
+  }()
+
+  speed := bot.homeSpeed()
+  if err := bot.SetAXISSpeed(speed, speed); err != nil {
+    return fmt.Errorf("Bot %s HOME speed error: %w", bot.Name, err)
+  }
+
+  log.Printf("[Bot %s INFO] Drive HOME confirmed at %d%% axis speed\n", bot.Name, speed)
+  if _, err := bot.move(bot.homePosition()); err != nil {
+    return fmt.Errorf("Bot %s drive HOME error: %w", bot.Name, err)
+  }
+
+  if bot.IsHome() != true {
+    return fmt.Errorf("Bot %s is not HOME position after drive HOME", bot.Name)
+  }
+
+  if err := bot.ResetOffsetAndPosition(); err != nil {
+    return fmt.Errorf("Bot %s Offset and Position update error: %w", bot.Name, err)
+  }
+
+  bot.stateMux.Lock()
+  bot.isHomeRequired = false
+  bot.stateMux.Unlock()
+
+  log.Printf("[Bot %s INFO] Bot is HOME position\n", bot.Name)
+  return nil
+}
+
 func (bot *Bot) LogBot() {
   bot.proxyMux.RLock()
   defer bot.proxyMux.RUnlock()
@@ -190,6 +527,8 @@ func (bot *Bot) LogBot() {
   defer bot.isMovementMux.RUnlock()
   bot.tagIdMux.RLock()
   defer bot.tagIdMux.RUnlock()
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
 
   log.Printf(
     "==========> Bot: %s[%s]\n" +
@@ -353,8 +692,99 @@ func (bot *Bot) SetAXISSpeed(VEL_AXIS uint8, ACC_AXIS uint8) error {
   return nil
 }
 
+// GetAXISSpeed reads the last $VEL_AXIS and $ACC_AXIS values handed to the robot program
+func (bot *Bot) GetAXISSpeed() (VEL_AXIS uint8, ACC_AXIS uint8, err error) {
+  requestVariable := make(map[C3VariableType]*string)
+  requestVariable[C3Variable_COM_VALUE2] = nil
+  requestVariable[C3Variable_COM_VALUE4] = nil
+
+  message, err := NewC3Message(bot.nextTagId(), requestVariable)
+  if err != nil {
+    return 0, 0, fmt.Errorf("Get AXISSpeed new message error: %w", err)
+  }
+
+  resultChan, err := bot.c3Client.Request(message)
+  if err != nil {
+    return 0, 0, fmt.Errorf("Get AXISSpeed message request error: %w", err)
+  }
+
+  select {
+    case message = <-resultChan:
+      err = message.Error()
+      if err != nil {
+        return 0, 0, fmt.Errorf("Get AXISSpeed message result error: %w", err)
+      }
+    case <-time.After(Bot_C3_Request_Timeout):
+      return 0, 0, fmt.Errorf("Get AXISSpeed message request timeout")
+  }
+
+  for _, variable := range message.Variables() {
+    if variable.ErrorCode != C3Message_Error_Success {
+      return 0, 0, fmt.Errorf("Get AXISSpeed message result variable %s error: %s", variable.Name, C3ErrorString[variable.ErrorCode])
+    }
+
+    value, err := strconv.ParseFloat(strings.TrimSpace(variable.Value), 64)
+    if err != nil || value < 1 || value > 100 {
+      return 0, 0, fmt.Errorf("Get AXISSpeed variable %s value %q is out of 1..100 range", variable.Name, variable.Value)
+    }
+
+    switch variable.Name {
+      case C3Variable_COM_VALUE2:
+        VEL_AXIS = uint8(math.Round(value))
+      case C3Variable_COM_VALUE4:
+        ACC_AXIS = uint8(math.Round(value))
+    }
+  }
+
+  if VEL_AXIS == 0 || ACC_AXIS == 0 {
+    return 0, 0, fmt.Errorf("Get AXISSpeed message result is incomplete")
+  }
+  return VEL_AXIS, ACC_AXIS, nil
+}
+
+func (bot *Bot) WriteVariable(name C3VariableType, value string) error {
+  requestVariable := make(map[C3VariableType]*string)
+  requestVariable[name] = &value
+
+  message, err := NewC3Message(bot.nextTagId(), requestVariable)
+  if err != nil {
+    return fmt.Errorf("Write %s new message error: %w", name, err)
+  }
+
+  resultChan, err := bot.c3Client.Request(message)
+  if err != nil {
+    return fmt.Errorf("Write %s message request error: %w", name, err)
+  }
+
+  select {
+    case message = <-resultChan:
+      err = message.Error()
+      if err != nil {
+        return fmt.Errorf("Write %s message result error: %w", name, err)
+      }
+    case <-time.After(Bot_C3_Request_Timeout):
+      return fmt.Errorf("Write %s message request timeout", name)
+  }
+
+  return nil
+}
 
 func (bot *Bot) Move(p *Position) (bool, error) {
+  if bot.IsHomeRequired() == true {
+    return false, fmt.Errorf("Bot is waiting for HOME confirmation")
+  }
+  return bot.move(p)
+}
+
+func (bot *Bot) move(p *Position) (isBreak bool, err error) {
+  start := time.Now()
+  defer func() {
+    bot.metrics.move(start, isBreak, err)
+    if err != nil {
+      bot.setError(fmt.Errorf("Move error: %w", err))
+    }
+  }()
+
   bot.isMovementMux.RLock()
   if bot.isMovement == true {
     bot.isMovementMux.RUnlock()
@@ -499,52 +929,54 @@ func (bot *Bot) Move(p *Position) (bool, error) {
   return false, nil
 }
 
-func (bot *Bot) MovInternal(action uint16) (bool, error) {
-  if action != 100 && action != 200 && action != 300 && action != 400 {
-    return false, fmt.Errorf("Incorrect action & action != 100 && action != 200 && action != 300 && action != 400")
+func (bot *Bot) RunInternalAction(action *InternalAction) (bool, error) {
+  if bot.IsHomeRequired() == true {
+    return false, fmt.Errorf("InternalAction %s. Bot is waiting for HOME confirmation", action.Name)
   }
 
   bot.isMovementMux.RLock()
   if bot.isMovement == true {
     bot.isMovementMux.RUnlock()
-    return false, fmt.Errorf("MovInternal %d. Bot is movement", action)
+    return false, fmt.Errorf("InternalAction %s. Bot is movement", action.Name)
   }
   bot.isMovementMux.RUnlock()
 
   requestComActionVariable := make(map[C3VariableType]*string)
-  comActionValue := fmt.Sprintf("%d", action)
+  comActionValue := string(action.ComAction())
   requestComActionVariable[C3Variable_COM_ACTION] = &comActionValue
 
   comActionMessage, err := NewC3Message(bot.nextTagId(), requestComActionVariable)
   if err != nil {
-    return false, fmt.Errorf("MovInternal %d new COM_ACTION message error: %w", action, err)
+    return false, fmt.Errorf("InternalAction %s new COM_ACTION message error: %w", action.Name, err)
   }
 
   resultComActionChan, err := bot.c3Client.Request(comActionMessage)
   if err != nil {
-    return false, fmt.Errorf("MovInternal %d COM_ACTION message request error: %w", action, err)
+    return false, fmt.Errorf("InternalAction %s COM_ACTION message request error: %w", action.Name, err)
   }
 
   select {
     case comActionMessage = <-resultComActionChan:
       err = comActionMessage.Error()
       if err != nil {
-        return false, fmt.Errorf("MovInternal %d COM_ACTION message result error: %w", action, err)
+        return false, fmt.Errorf("InternalAction %s COM_ACTION message result error: %w", action.Name, err)
       }
     case <-time.After(Bot_C3_Request_Timeout):
-      return false, fmt.Errorf("MovInternal %d COM_ACTION message request timeout", action)
+      return false, fmt.Errorf("InternalAction %s COM_ACTION message request timeout", action.Name)
   }
 
+  bot.positionMux.RLock()
+  startSeq := bot.c3UpdateSeq
+  bot.positionMux.RUnlock()
+
   bot.isMovementMux.Lock()
   bot.isMovement = true
   bot.isMovementMux.Unlock()
-  log.Printf("[Bot %s INFO] MovInternal %d start\n", bot.Name, action)
-  
-  HOME := NewPosition(PositionType_E6AXIS)
-  HOME.SetValues([14]float32{0, -90, 90, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+  log.Printf("[Bot %s INFO] InternalAction %s[%d] start\n", bot.Name, action.Name, action.Action)
 
   var readyFlag uint16 = 0
-  timeout := time.After(Bot_Move_Timeout)
+  timeout := time.After(action.GetTimeout())
+  tolerance := action.GetTolerance()
 
   loop: for {
     select {
@@ -552,12 +984,21 @@ func (bot *Bot) MovInternal(action uint16) (bool, error) {
         bot.isMovementMux.Lock()
         bot.isMovement = false
         bot.isMovementMux.Unlock()
-        return true, fmt.Errorf("Move timeout break")
-      
+        return true, fmt.Errorf("InternalAction %s timeout break", action.Name)
+
       default:
         bot.positionMux.RLock()
-        if bot.c3AXIS_ACT.Equal(HOME, 0.0010) {
-          readyFlag++
+        switch action.Completion {
+          case InternalActionCompletion_Pose:
+            if bot.c3AXIS_ACT.Equal(action.EndPosition, tolerance) {
+              readyFlag++
+            }
+
+          case InternalActionCompletion_Signal:
+            // Polls started before the COM_ACTION write may still report the empty command
+            if bot.c3UpdateSeq > startSeq + 1 && bot.c3COM_ACTION == C3Variable_COM_ACTION_EMPTY {
+              readyFlag = Bot_Position_ReadySteps
+            }
         }
         bot.positionMux.RUnlock()
 
@@ -567,13 +1008,32 @@ func (bot *Bot) MovInternal(action uint16) (bool, error) {
     }
   }
 
-  log.Printf("[Bot %s INFO] MovInternal %d ready\n", bot.Name, action)
+  log.Printf("[Bot %s INFO] InternalAction %s[%d] ready\n", bot.Name, action.Name, action.Action)
   bot.isMovementMux.Lock()
   bot.isMovement = false
   bot.isMovementMux.Unlock()
   return false, nil
 }
 
+func (bot *Bot) GetInternalAction(name string) *InternalAction {
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+
+  for _, action := range bot.InternalActions {
+    if action.Name == name {
+      return action
+    }
+  }
+
+  for _, action := range bot.teamInternalActions {
+    if action.Name == name {
+      return action
+    }
+  }
+
+  return nil
+}
+
 func (bot *Bot) UpdatePosition() error {
   requestVariable := make(map[C3VariableType]*string)
   
@@ -637,6 +1097,7 @@ func (bot *Bot) UpdatePosition() error {
   bot.c3COM_ACTION = COM_ACTION
   bot.c3COM_ROUNDM = COM_ROUNDM
   bot.c3POSITION = POS_ACT.WithOffset(bot.c3OFFSET)
+  bot.c3UpdateSeq++
   bot.positionMux.Unlock()
 
   return nil
@@ -653,11 +1114,13 @@ func (bot *Bot) ResetOffsetAndPosition() error {
 func (bot *Bot) processUpdatePosition() {
   defer bot.wg.Done()
   for {
-    if bot.isShutdown == true {
+    if bot.isShutdown.Load() == true {
       return
     }
-    if err := bot.UpdatePosition(); err != nil {
-      if bot.isShutdown == true {
+    err := bot.UpdatePosition()
+    bot.polled(err)
+    if err != nil {
+      if bot.isShutdown.Load() == true {
         return
       }
       log.Printf("[Bot %s ERROR] UpdatePosition Get position error %v\n", bot.Name, err)
@@ -667,9 +1130,40 @@ func (bot *Bot) processUpdatePosition() {
     if err := bot.oscResponseCurrentCoords(); err != nil {
       log.Printf("[Bot %s ERROR] Response current coords error %v\n", bot.Name, err)
     }
+
+    if err := bot.oscTelemetry(); err != nil {
+      log.Printf("[Bot %s ERROR] Telemetry error %v\n", bot.Name, err)
+    }
   }
 }
 
+// oscTelemetry sends the current sample to oscResponseAddress and every bot (or team) destination
+func (bot *Bot) oscTelemetry() error {
+  bot.positionMux.RLock()
+  sample := NewTelemetrySample(bot.c3UpdateSeq, bot.c3AXIS_ACT.Clone(), bot.c3POSITION.Clone(), bot.lastTelemetry)
+  bot.positionMux.RUnlock()
+  bot.lastTelemetry = sample
+
+  bot.settingsMux.RLock()
+  oscClient, telemetry := bot.oscClient, bot.Telemetry
+  bot.settingsMux.RUnlock()
+
+  var result error
+  if oscClient != nil {
+    result = telemetrySend(telemetry, bot.Name, sample, func(oscPacket *OSCPacket) error {
+      if bot.oscOutput != nil {
+        bot.oscOutput(oscPacket)
+      }
+      return oscClient.Send(oscPacket)
+    })
+  }
+
+  if err := oscDestinationsSendTelemetry(bot.oscDestinations(), bot.Name, sample); err != nil && result == nil {
+    result = err
+  }
+  return result
+}
+
 func (bot *Bot) UpdateProxyInfo() error {
   requestVariable := make(map[C3VariableType]*string)
   
@@ -689,13 +1183,19 @@ func (bot *Bot) UpdateProxyInfo() error {
     return fmt.Errorf("Get Proxy info message request error: %w", err)
   }
 
-  resultMessage := <- resultChan
-  err = resultMessage.Error()
-  if err != nil {
-    return fmt.Errorf("Get Proxy info message result error: %w", err)
+  var resultMessage *C3Message
+  select {
+    case resultMessage = <-resultChan:
+      err = resultMessage.Error()
+      if err != nil {
+        return fmt.Errorf("Get Proxy info message result error: %w", err)
+      }
+    case <-time.After(Bot_C3_Request_Timeout):
+      return fmt.Errorf("Get Proxy info message request timeout")
   }
 
   bot.proxyMux.Lock()
+  defer bot.proxyMux.Unlock()
   for _, variable := range resultMessage.Variables() {
     if variable.ErrorCode != C3Message_Error_Success {
       return fmt.Errorf("Get Proxy info result variable %s error: %s", variable.Name, C3ErrorString[variable.ErrorCode])
@@ -718,37 +1218,58 @@ func (bot *Bot) UpdateProxyInfo() error {
         bot.c3PROXY_PORT = variable.Value
     }
   }
-  bot.proxyMux.Unlock()
 
   return nil
 }
 
-func (bot *Bot) OSCPacket(oscPacket *OSCPacket) {
-  select {
-    case bot.oscInput <- oscPacket:
-    default:
-      log.Printf("[Bot %s WARNING] OSC Input channel is full, discarding packet\n", bot.Name)
+func (bot *Bot) OSCMethods() []*OSCMethod {
+  // processOSCMapping reads limits under mappingMux, so mappingMux is taken first
+  bot.mappingMux.Lock()
+  defer bot.mappingMux.Unlock()
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+
+  var methods []*OSCMethod
+  if bot.OSCRequestAxis != nil {
+    methods = append(methods, NewOSCMotionMethod(*bot.OSCRequestAxis, bot.oscQueue(bot.processOSCAxis)))
+  }
+  if bot.OSCRequestCoords != nil {
+    methods = append(methods, NewOSCMotionMethod(*bot.OSCRequestCoords, bot.oscQueue(bot.processOSCCoords)))
+  }
+  if bot.OSCRequestPosition != nil {
+    methods = append(methods, NewOSCMotionMethod(*bot.OSCRequestPosition, bot.oscQueue(bot.processOSCPosition)))
   }
+  for _, path := range bot.mappingPaths {
+    mappings := bot.mappingGroups[path]
+    methods = append(methods, NewOSCMotionMethod(path, bot.oscQueue(func(oscPacket *OSCPacket) {
+      bot.processOSCMapping(mappings, oscPacket)
+    })))
+  }
+  return methods
 }
 
-func (bot *Bot) processOSCPackets() {
-  defer bot.wg.Done()
-
-  for packet := range bot.oscInput {
-    if bot.OSCRequestAxis != nil && packet.Path == *bot.OSCRequestAxis {
-      bot.processOSCAxis(packet)
-      continue
+func (bot *Bot) oscQueue(handler OSCHandler) OSCHandler {
+  return func(oscPacket *OSCPacket) {
+    bot.inputMux.RLock()
+    defer bot.inputMux.RUnlock()
+    if bot.isShutdown.Load() == true {
+      return
     }
 
-    if bot.OSCRequestCoords != nil && packet.Path == *bot.OSCRequestCoords {
-      bot.processOSCCoords(packet)
-      continue
+    select {
+      case bot.oscInput <- &oscCall{handler: handler, packet: oscPacket}:
+      default:
+        bot.metrics.oscDropped.Add(1)
+        log.Printf("[Bot %s WARNING] OSC Input channel is full, discarding packet\n", bot.Name)
     }
+  }
+}
 
-    if bot.OSCRequestPosition != nil && packet.Path == *bot.OSCRequestPosition {
-      bot.processOSCPosition(packet)
-      continue
-    }
+func (bot *Bot) processOSCPackets() {
+  defer bot.wg.Done()
+
+  for call := range bot.oscInput {
+    call.handler(call.packet)
   }
 }
 
@@ -756,23 +1277,25 @@ func (bot *Bot) processOSCAxis(oscPacket *OSCPacket) {
   values := oscPacket.Values()
   if len(values) != 6 {
     log.Printf("[Bot %s ERROR] Incorrect OSC values length of %+v\n", bot.Name, values)
+    bot.events.Error(oscPacket.Source, GateError_BadArity, bot.Name, fmt.Sprintf("Axis expects 6 values, got %d", len(values)))
     return
   }
 
   position := NewPosition(PositionType_E6AXIS)
   for i, value := range values {
-    switch value.(type) {
-      case float32:
-        position.Set(i, value.(float32))
-      default:
-        log.Printf("[Bot %s ERROR] OSC values[%d] is not of float32 value\n", bot.Name, i)
-        return
+    v, err := OSCFloat32(value)
+    if err != nil {
+      log.Printf("[Bot %s ERROR] OSC values[%d] error: %v\n", bot.Name, i, err)
+      bot.events.Error(oscPacket.Source, GateError_BadType, bot.Name, fmt.Sprintf("Axis values[%d] error: %v", i, err))
+      return
     }
+    position.Set(i, v)
   }
 
   go func(position *Position) {
     if _, err := bot.Move(position); err != nil {
       log.Printf("[Bot %s ERROR] OSC Position %s move error: %v\n", bot.Name, position.Value(), err)
+      bot.events.Error(oscPacket.Source, GateError_MoveFailed, bot.Name, err.Error())
     }
   }(position)
 }
@@ -781,71 +1304,243 @@ func (bot *Bot) processOSCCoords(oscPacket *OSCPacket) {
   values := oscPacket.Values()
   if len(values) != 6 {
     log.Printf("[Bot %s ERROR] Incorrect OSC values length of %+v\n", bot.Name, values)
+    bot.events.Error(oscPacket.Source, GateError_BadArity, bot.Name, fmt.Sprintf("Coords expects 6 values, got %d", len(values)))
     return
   }
 
   position := NewPosition(PositionType_E6POS)
   for i, value := range values {
-    switch value.(type) {
-      case float32:
-        position.Set(i, value.(float32))
-      default:
-        log.Printf("[Bot %s ERROR] OSC values[%d] is not of float32 value\n", bot.Name, i)
-        return
+    v, err := OSCFloat32(value)
+    if err != nil {
+      log.Printf("[Bot %s ERROR] OSC values[%d] error: %v\n", bot.Name, i, err)
+      bot.events.Error(oscPacket.Source, GateError_BadType, bot.Name, fmt.Sprintf("Coords values[%d] error: %v", i, err))
+      return
     }
+    position.Set(i, v)
   }
 
   go func(position *Position) {
     if _, err := bot.Move(position); err != nil {
       log.Printf("[Bot %s ERROR] OSC Position %s move error: %v\n", bot.Name, position.Value(), err)
+      bot.events.Error(oscPacket.Source, GateError_MoveFailed, bot.Name, err.Error())
     }
   }(position)
 }
 
+// processOSCMapping sets mapped components on the pending target, unmapped components hold
+// the target in progress or the current position. Only the latest target is kept while the bot moves.
+func (bot *Bot) processOSCMapping(mappings []*OSCMapping, oscPacket *OSCPacket) {
+  values := oscPacket.Values()
+  inputs := make([]float32, len(mappings))
+  for i, mapping := range mappings {
+    if mapping.Argument >= len(values) {
+      log.Printf("[Bot %s ERROR] OSC Mapping %s argument %d is not found in %+v\n", bot.Name, mapping.Path, mapping.Argument, values)
+      bot.events.Error(oscPacket.Source, GateError_BadArity, bot.Name, fmt.Sprintf("Mapping %s argument %d is not found", oscPacket.Path, mapping.Argument))
+      return
+    }
+
+    v, err := OSCFloat32(values[mapping.Argument])
+    if err != nil {
+      log.Printf("[Bot %s ERROR] OSC Mapping %s values[%d] error: %v\n", bot.Name, mapping.Path, mapping.Argument, err)
+      bot.events.Error(oscPacket.Source, GateError_BadType, bot.Name, fmt.Sprintf("Mapping %s values[%d] error: %v", oscPacket.Path, mapping.Argument, err))
+      return
+    }
+    inputs[i] = v
+  }
+
+  positionType := mappings[0].PositionType()
+
+  bot.mappingMux.Lock()
+  defer bot.mappingMux.Unlock()
+
+  var target *Position
+  switch {
+    case bot.mappingPending != nil && bot.mappingPending.Type() == positionType:
+      target = bot.mappingPending.Clone()
+    case bot.mappingActive != nil && bot.mappingActive.Type() == positionType:
+      target = bot.mappingActive.Clone()
+    default:
+      // Pose targets are in the offset-corrected frame of /coords, move checks them against c3POSITION
+      bot.positionMux.RLock()
+      if positionType == PositionType_E6AXIS {
+        target = bot.c3AXIS_ACT.Clone()
+      } else {
+        target = bot.c3POSITION.Clone()
+      }
+      bot.positionMux.RUnlock()
+  }
+
+  for i, mapping := range mappings {
+    target.Set(mapping.Index(), mapping.Value(inputs[i]))
+  }
+  if err := bot.limits().CheckPosition(target); err != nil {
+    log.Printf("[Bot %s ERROR] OSC Mapping %s target is rejected: %v\n", bot.Name, oscPacket.Path, err)
+    bot.events.Error(oscPacket.Source, GateError_MoveFailed, bot.Name, fmt.Sprintf("Mapping %s target is rejected: %v", oscPacket.Path, err))
+    return
+  }
+  bot.mappingPending = target
+
+  if bot.isMappingMove == false {
+    bot.isMappingMove = true
+    go bot.processMappingMove()
+  }
+}
+
+func (bot *Bot) processMappingMove() {
+  for {
+    bot.mappingMux.Lock()
+    position := bot.mappingPending
+    bot.mappingPending = nil
+    bot.mappingActive = position
+    if position == nil || bot.isShutdown.Load() == true {
+      bot.isMappingMove = false
+      bot.mappingMux.Unlock()
+      return
+    }
+    bot.mappingMux.Unlock()
+
+    if _, err := bot.Move(position); err != nil {
+      log.Printf("[Bot %s ERROR] OSC Mapping %s move error: %v\n", bot.Name, position.Value(), err)
+      bot.events.Error(nil, GateError_MoveFailed, bot.Name, err.Error())
+    }
+  }
+}
+
 func (bot *Bot) GetMoveGroup(id uint16) *MoveGroup {
   bot.moveGroupsMux.RLock()
-  defer bot.moveGroupsMux.RLock()
+  defer bot.moveGroupsMux.RUnlock()
   
-  var moveGroup *MoveGroup = nil
-  for _, moveGroup = range bot.MoveGroups {
+  for _, moveGroup := range bot.MoveGroups {
     if moveGroup.Id == id {
-      break
+      return moveGroup
     }
   }
 
-  return moveGroup
+  return nil
 }
 
-func (bot *Bot) MoveRound(moveGroup *MoveGroup) (bool, error) {
-  if moveGroup.Id == 100 || moveGroup.Id == 200 || moveGroup.Id == 300 || moveGroup.Id == 400 {
-    if isBreak, err := bot.MovInternal(moveGroup.Id); err != nil {
-       return isBreak, fmt.Errorf("MoveGroup %d MoveInternal error: %w", moveGroup.Id, err)
+// GetMoveGroups returns a copy of the list, groups are shared and must not be modified
+func (bot *Bot) GetMoveGroups() []*MoveGroup {
+  bot.moveGroupsMux.RLock()
+  defer bot.moveGroupsMux.RUnlock()
+  return append([]*MoveGroup(nil), bot.MoveGroups...)
+}
+
+// SetMoveGroups replaces the list and returns the previous one. Groups are replaced, never
+// modified in place, so a running MoveRound keeps its own group.
+func (bot *Bot) SetMoveGroups(moveGroups []*MoveGroup) []*MoveGroup {
+  bot.moveGroupsMux.Lock()
+  defer bot.moveGroupsMux.Unlock()
+  previous := bot.MoveGroups
+  bot.MoveGroups = moveGroups
+  bot.moveGroupsVersion++
+  return previous
+}
+
+// MoveGroupsVersion changes on every SetMoveGroups, so observers push MoveGroups only on change
+func (bot *Bot) MoveGroupsVersion() uint64 {
+  bot.moveGroupsMux.RLock()
+  defer bot.moveGroupsMux.RUnlock()
+  return bot.moveGroupsVersion
+}
+
+func (bot *Bot) CheckMoveGroups(moveGroups []*MoveGroup) error {
+  ids := make(map[uint16]bool, len(moveGroups))
+  for _, moveGroup := range moveGroups {
+    if ids[moveGroup.Id] == true {
+      return fmt.Errorf("MoveGroup %d is duplicated", moveGroup.Id)
     }
+    ids[moveGroup.Id] = true
 
-    bot.currentMoveGroupIdMux.Lock()
-    bot.currentMoveGroupId =  moveGroup.Id
-    bot.currentMoveGroupIdMux.Unlock()
-    return false, nil
+    for i, step := range moveGroup.Positions {
+      if err := bot.CheckMoveStep(step); err != nil {
+        return fmt.Errorf("MoveGroup %d position %d error: %w", moveGroup.Id, i, err)
+      }
+    }
   }
+  return nil
+}
 
-  bot.currentMoveGroupIdMux.RLock()
-  if err := BotETest(bot.currentMoveGroupId, moveGroup.Id); err != nil {
-    bot.currentMoveGroupIdMux.RUnlock()
-    return false, fmt.Errorf("MoveGroup %d Error: %w", moveGroup.Id, err)
+func (bot *Bot) CheckMoveStep(step *MoveStep) error {
+  if step == nil {
+    return fmt.Errorf("Step is empty")
+  }
+  if step.IsAction() {
+    if bot.GetInternalAction(step.Action) == nil {
+      return fmt.Errorf("InternalAction %s is not found", step.Action)
+    }
+    return nil
+  }
+  if step.Position == nil {
+    return fmt.Errorf("Position is empty")
+  }
+  if step.IsRaw == true && step.Position.Type() != PositionType_E6POS {
+    return fmt.Errorf("Raw step must be of E6POS type")
+  }
+  switch step.Position.Type() {
+    case PositionType_E6AXIS, PositionType_E6POS:
+      return bot.limits().CheckPosition(step.Position)
+  }
+  return fmt.Errorf("Incorrect position type %d", step.Position.Type())
+}
+
+// stepTarget returns the move target of a position step, a raw pose is taken into the
+// offset-corrected frame, so move completes it when POS_ACT reaches the raw pose
+func (bot *Bot) stepTarget(step *MoveStep) *Position {
+  if step.IsRaw == false {
+    return step.Position
   }
-  bot.currentMoveGroupIdMux.RUnlock()
+  bot.positionMux.RLock()
+  defer bot.positionMux.RUnlock()
+  return step.Position.WithOffset(bot.c3OFFSET)
+}
+
+func (bot *Bot) MoveRound(moveGroup *MoveGroup) (bool, error) {
+  if err := bot.checkMoveGroup(moveGroup); err != nil {
+    return false, err
+  }
+
+  bot.events.Event(GateEvent_MoveStarted, bot.Name, int32(moveGroup.Id))
   
-  for _, position := range moveGroup.Positions {
-    if isBreak, err := bot.Move(position); err != nil {
-      return isBreak, fmt.Errorf("MoveGroup %d Position %s move error: %w", moveGroup.Id, position.Value(), err)
+  for _, step := range moveGroup.Positions {
+    if isBreak, err := bot.MoveStep(step); err != nil {
+      return isBreak, fmt.Errorf("MoveGroup %d Position %s move error: %w", moveGroup.Id, step.Value(), err)
     }
-    time.Sleep(250 * time.Millisecond)
+  }
+
+  bot.setCurrentMoveGroup(moveGroup)
+  bot.events.Event(GateEvent_MoveDone, bot.Name, int32(moveGroup.Id))
+  return false, nil
+}
 
+func (bot *Bot) checkMoveGroup(moveGroup *MoveGroup) error {
+  bot.currentMoveGroupIdMux.RLock()
+  defer bot.currentMoveGroupIdMux.RUnlock()
+  if err := BotETest(bot.currentMoveGroupId, moveGroup.Id); err != nil {
+    return fmt.Errorf("MoveGroup %d Error: %w", moveGroup.Id, err)
   }
+  return nil
+}
 
+func (bot *Bot) setCurrentMoveGroup(moveGroup *MoveGroup) {
   bot.currentMoveGroupIdMux.Lock()
-  bot.currentMoveGroupId =  moveGroup.Id
+  bot.currentMoveGroupId = moveGroup.Id
   bot.currentMoveGroupIdMux.Unlock()
+}
+
+func (bot *Bot) MoveStep(step *MoveStep) (bool, error) {
+  if step.IsAction() {
+    action := bot.GetInternalAction(step.Action)
+    if action == nil {
+      return false, fmt.Errorf("InternalAction %s is not found", step.Action)
+    }
+    return bot.RunInternalAction(action)
+  }
+
+  if isBreak, err := bot.Move(bot.stepTarget(step)); err != nil {
+    return isBreak, err
+  }
+  time.Sleep(250 * time.Millisecond)
   return false, nil
 }
 
@@ -875,27 +1570,49 @@ func (bot *Bot) RunMoveGroup(id uint16) error {
     return fmt.Errorf("MoveGroup %d in not found", id)
   }
 
-  bot.moveInput <- moveGroup
-  return nil
+  bot.inputMux.RLock()
+  defer bot.inputMux.RUnlock()
+  if bot.isShutdown.Load() == true {
+    return fmt.Errorf("Bot %s is shut down", bot.Name)
+  }
+
+  select {
+    case bot.moveInput <- moveGroup:
+      return nil
+    default:
+      return fmt.Errorf("Bot %s MoveGroup input channel is full", bot.Name)
+  }
 }
 
 func (bot *Bot) processOSCPosition(oscPacket *OSCPacket) {
   values := oscPacket.Values()
   if len(values) != 3 {
     log.Printf("[Bot %s ERROR] Incorrect OSC Position values length of %+v\n", bot.Name, values)
+    bot.events.Error(oscPacket.Source, GateError_BadArity, bot.Name, fmt.Sprintf("Position expects 3 values, got %d", len(values)))
     return
   }
 
-  var id uint16 = uint16(values[0].(int32))
-  var _ uint16 = uint16(values[1].(int32)) // Speed
-  var index int32 = values[1].(int32)
+  args, err := oscPositionArgs(values)
+  if err != nil {
+    log.Printf("[Bot %s ERROR] OSC Position %v\n", bot.Name, err)
+    bot.events.Error(oscPacket.Source, GateError_BadType, bot.Name, err.Error())
+    if err := bot.oscResponsePosition(oscPacket.Source, OSCOutputStatus_Error, 0, 0); err != nil {
+      log.Printf("[Bot %s ERROR] OSC Response error %v\n", bot.Name, err)
+    }
+    return
+  }
+
+  var id uint16 = uint16(args[0])
+  var _ uint16 = uint16(args[1]) // Speed
+  var index int32 = args[2]
 
   bot.isMovementMux.RLock()
   if bot.isMovement == true {
     bot.isMovementMux.RUnlock()
     log.Printf("[Bot %s ERROR] OSC MoveGroup error: Arready movement\n", bot.Name)
+    bot.events.Error(oscPacket.Source, GateError_Busy, bot.Name, fmt.Sprintf("MoveGroup %d rejected, bot is moving", id))
     go func() {
-      if err := bot.oscResponsePosition(OSCOutputStatus_Error, index, id); err != nil {
+      if err := bot.oscResponsePosition(oscPacket.Source, OSCOutputStatus_Error, index, id); err != nil {
         log.Printf("[Bot %s ERROR] OSC Response error %v\n", bot.Name, err)
       }
     }()
@@ -906,8 +1623,9 @@ func (bot *Bot) processOSCPosition(oscPacket *OSCPacket) {
   moveGroup := bot.GetMoveGroup(id)
   if moveGroup == nil {
     log.Printf("[Bot %s ERROR] OSC MoveGroup %d in not found\n", bot.Name, id)
+    bot.events.Error(oscPacket.Source, GateError_UnknownMoveGroup, bot.Name, fmt.Sprintf("MoveGroup %d is not found", id))
     go func() {
-      if err := bot.oscResponsePosition(OSCOutputStatus_Error, index, id); err != nil {
+      if err := bot.oscResponsePosition(oscPacket.Source, OSCOutputStatus_Error, index, id); err != nil {
         log.Printf("[Bot %s ERROR] OSC Response error %v\n", bot.Name, err)
       }
     }()
@@ -918,31 +1636,37 @@ func (bot *Bot) processOSCPosition(oscPacket *OSCPacket) {
     if isBreak, err := bot.MoveRound(moveGroup); err != nil {
       log.Printf("[Bot %s ERROR] OSC Process position error: %v\n", bot.Name, err)
       status := OSCOutputStatus_Error
+      kind := GateError_MoveFailed
       if isBreak == true {
         status = OSCOutputStatus_Break
+        kind = GateError_MoveBreak
       }
-      if err := bot.oscResponsePosition(status, index, moveGroup.Id); err != nil {
+      bot.events.Error(oscPacket.Source, kind, bot.Name, err.Error())
+      if err := bot.oscResponsePosition(oscPacket.Source, status, index, moveGroup.Id); err != nil {
         log.Printf("[Bot %s ERROR] OSC error move response error %v\n", bot.Name, err)
       }
       return
     }
 
-    if err := bot.oscResponsePosition(OSCOutputStatus_OK, index, moveGroup.Id); err != nil {
+    if err := bot.oscResponsePosition(oscPacket.Source, OSCOutputStatus_OK, index, moveGroup.Id); err != nil {
       log.Printf("[Bot %s ERROR] OSC sucess move response error %v\n", bot.Name, err)
     }
   }(index, moveGroup)
 }
 
 func (bot *Bot) oscResponseAxis(position *Position) error {
-  if bot.oscClient == nil {
+  bot.settingsMux.RLock()
+  path := bot.OSCResponseAxes
+  bot.settingsMux.RUnlock()
+  if path == nil {
     return nil
   }
 
-  if bot.OSCResponseAxes == nil {
-    return nil
+  oscPacket, err := NewOSCResponseAxis(*path, position)
+  if err != nil {
+    return err
   }
-
-  return bot.oscClient.ResponseAxis(*bot.OSCResponseAxes, position)
+  return bot.oscSend(oscPacket)
 }
 
 func (bot *Bot) oscResponseCurrentAxis() error {
@@ -953,15 +1677,18 @@ func (bot *Bot) oscResponseCurrentAxis() error {
 }
 
 func (bot *Bot) oscResponseCoords(position *Position) error {
-  if bot.oscClient == nil {
+  bot.settingsMux.RLock()
+  path := bot.OSCResponseCoords
+  bot.settingsMux.RUnlock()
+  if path == nil {
     return nil
   }
 
-  if bot.OSCResponseCoords == nil {
-    return nil
+  oscPacket, err := NewOSCResponseCoords(*path, position)
+  if err != nil {
+    return err
   }
-
-  return bot.oscClient.ResponseCoords(*bot.OSCResponseCoords, position)
+  return bot.oscSend(oscPacket)
 }
 
 func (bot *Bot) oscResponseCurrentCoords() error {
@@ -971,19 +1698,77 @@ func (bot *Bot) oscResponseCurrentCoords() error {
   return bot.oscResponseCoords(position)
 }
 
-func (bot *Bot) oscResponsePosition(status OSCOutputStatus, index int32, positionId uint16) error {
-  if bot.oscClient == nil {
+// oscResponsePosition replies to the request sender when oscReplyToSender is set, else to oscResponseAddress
+func (bot *Bot) oscResponsePosition(source net.Addr, status OSCOutputStatus, index int32, positionId uint16) error {
+  bot.settingsMux.RLock()
+  path, replyToSender := bot.OSCResponsePosition, bot.OSCReplyToSender
+  bot.settingsMux.RUnlock()
+  if path == nil {
     return nil
   }
 
-  if bot.OSCResponsePosition == nil {
-    return nil
+  oscPacket, err := NewOSCResponsePosition(*path, status, index, positionId)
+  if err != nil {
+    return err
+  }
+
+  if replyToSender != nil && *replyToSender == true && source != nil && bot.oscServer != nil {
+    if bot.oscOutput != nil {
+      bot.oscOutput(oscPacket)
+    }
+    return bot.oscServer.Reply(source, oscPacket)
+  }
+  return bot.oscSend(oscPacket)
+}
+
+// oscSend sends to oscResponseAddress and every bot (or team) destination
+func (bot *Bot) oscSend(oscPacket *OSCPacket) error {
+  if bot.oscOutput != nil {
+    bot.oscOutput(oscPacket)
+  }
+
+  bot.settingsMux.RLock()
+  oscClient := bot.oscClient
+  bot.settingsMux.RUnlock()
+
+  var result error
+  if oscClient != nil {
+    result = oscClient.Send(oscPacket)
+  }
+
+  if err := oscDestinationsSend(bot.oscDestinations(), oscPacket); err != nil && result == nil {
+    result = err
   }
+  return result
+}
 
-  return bot.oscClient.ResponsePosition(*bot.OSCResponsePosition, status, index, positionId)
+// oscDestinations are the bot's own destinations, an empty list falls back to the team destinations
+func (bot *Bot) oscDestinations() []*OSCDestination {
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+  if len(bot.OSCDestinations) == 0 {
+    return bot.teamOSCDestinations
+  }
+  return bot.OSCDestinations
 }
 
 func (bot *Bot) GetAppData() *BotApp {
+  botApp := bot.GetStateAppData()
+
+  bot.moveGroupsMux.RLock()
+  defer bot.moveGroupsMux.RUnlock()
+  botApp.MoveGroups = make([]*MoveGroup, len(bot.MoveGroups))
+  for i, moveGroup := range bot.MoveGroups {
+    botApp.MoveGroups[i] = moveGroup.Clone()
+  }
+
+  return botApp
+}
+
+// GetStateAppData is GetAppData without MoveGroups, for frequent state polling
+func (bot *Bot) GetStateAppData() *BotApp {
+  stateApp := bot.GetStateApp()
+
   bot.proxyMux.RLock()
   defer bot.proxyMux.RUnlock()
   bot.positionMux.RLock()
@@ -992,6 +1777,15 @@ func (bot *Bot) GetAppData() *BotApp {
   defer bot.isMovementMux.RUnlock()
   bot.tagIdMux.RLock()
   defer bot.tagIdMux.RUnlock()
+  bot.stateMux.RLock()
+  defer bot.stateMux.RUnlock()
+  bot.settingsMux.RLock()
+  defer bot.settingsMux.RUnlock()
+
+  var degradedError string
+  if bot.degradedError != nil {
+    degradedError = bot.degradedError.Error()
+  }
 
   botApp := &BotApp{
     Name:    bot.Name,
@@ -1009,6 +1803,15 @@ func (bot *Bot) GetAppData() *BotApp {
     TagId:      bot.tagId,
     IsMovement: bot.isMovement,
 
+    IsDegraded:     bot.isDegraded,
+    DegradedError:  degradedError,
+    IsHomeRequired: bot.isHomeRequired,
+
+    State:         stateApp.State,
+    LastError:     stateApp.LastError,
+    LastErrorTime: stateApp.LastErrorTime,
+    LastPollTime:  stateApp.LastPollTime,
+
     COM_ACTION: string(bot.c3COM_ACTION),
     COM_ROUNDM: string(bot.c3COM_ROUNDM),
 
@@ -1024,9 +1827,8 @@ func (bot *Bot) GetAppData() *BotApp {
     PROXY_PORT:     bot.c3PROXY_PORT,
   }
 
-  botApp.MoveGroups = make([]*MoveGroup, len(bot.MoveGroups))
-  for i, moveGroup := range bot.MoveGroups {
-    botApp.MoveGroups[i] = moveGroup.Clone()
+  if bot.c3Client != nil {
+    botApp.IsConnected = bot.IsConnected()
   }
 
   return botApp
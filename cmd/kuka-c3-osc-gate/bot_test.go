@@ -0,0 +1,155 @@
+package main
+
+import (
+  "testing"
+)
+
+func testPosition(positionType PositionType, values ...float32) *Position {
+  position := NewPosition(positionType)
+  for i, value := range values {
+    position.Set(i, value)
+  }
+  return position
+}
+
+// testBotAt places a bot at POS_ACT with the OFFSET taken on Up, as updatePosition does
+func testBotAt(t *testing.T, POS_ACT *Position, OFFSET *Position) *Bot {
+  t.Helper()
+  bot, err := NewBot()
+  if err != nil {
+    t.Fatalf("NewBot() error: %v", err)
+  }
+  bot.Name = "Test"
+  bot.c3POS_ACT = POS_ACT
+  bot.c3OFFSET = OFFSET
+  bot.c3POSITION = POS_ACT.WithOffset(OFFSET)
+  return bot
+}
+
+func TestProcessOSCMappingPoseFrame(t *testing.T) {
+  POS_ACT := testPosition(PositionType_E6POS, 1100, -150, 700, 15, 25, 35)
+  tests := []struct {
+    name   string
+    offset *Position
+    input  float32
+  }{
+    {"zero offset", NewPosition(PositionType_E6POS), 250},
+    {"offset", testPosition(PositionType_E6POS, 1000, -200, 500, 10, 20, 30), 250},
+    {"offset at the current pose", testPosition(PositionType_E6POS, 1000, -200, 500, 10, 20, 30), 200},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      bot := testBotAt(t, POS_ACT.Clone(), test.offset)
+      // A running mapping move picks the pending target up, none is started here
+      bot.isMappingMove = true
+
+      oscPacket := NewOSCPacket()
+      oscPacket.Path = "/fader"
+      oscPacket.Append(test.input)
+      bot.processOSCMapping([]*OSCMapping{{Path: "/fader", Target: "Z"}}, oscPacket)
+
+      target := bot.mappingPending
+      if target == nil {
+        t.Fatalf("mappingPending = nil, want a target")
+      }
+
+      // Move checks pose targets against c3POSITION, unmapped components must already match it
+      want := bot.c3POSITION.Clone()
+      want.Set(2, test.input)
+      if target.Equal(want, Bot_Position_Tolerance) == false {
+        t.Errorf("target = %s, want %s", target.Value(), want.Value())
+      }
+      if isReached := bot.c3POSITION.Equal(target, Bot_Position_Tolerance); isReached != (test.input == bot.c3POSITION.Get(2)) {
+        t.Errorf("target %s reached = %v at POSITION %s", target.Value(), isReached, bot.c3POSITION.Value())
+      }
+    })
+  }
+}
+
+func TestStepTargetFrame(t *testing.T) {
+  POS_ACT := testPosition(PositionType_E6POS, 1100, -150, 700, 15, 25, 35)
+  OFFSET := testPosition(PositionType_E6POS, 1000, -200, 500, 10, 20, 30)
+  bot := testBotAt(t, POS_ACT.Clone(), OFFSET)
+
+  tests := []struct {
+    name   string
+    source TeachSource
+  }{
+    {"raw pose", TeachSource_Pos},
+    {"offset-corrected pose", TeachSource_Position},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      position := bot.c3POSITION.Clone()
+      if test.source == TeachSource_Pos {
+        position = bot.c3POS_ACT.Clone()
+      }
+      step := teachStep(test.source, position)
+
+      // A step taught at the current pose is already reached when it is replayed
+      if target := bot.stepTarget(step); bot.c3POSITION.Equal(target, Bot_Position_Tolerance) == false {
+        t.Errorf("stepTarget() = %s, want POSITION %s", target.Value(), bot.c3POSITION.Value())
+      }
+    })
+  }
+}
+
+func TestBotCheckHomeSpeed(t *testing.T) {
+  speed := func(value uint8) *uint8 { return &value }
+  tests := []struct {
+    name         string
+    homeSpeed    *uint8
+    restoreSpeed *uint8
+    wantErr      bool
+  }{
+    {"defaults", nil, nil, false},
+    {"in range", speed(1), speed(100), false},
+    {"zero HOME speed", speed(0), nil, true},
+    {"HOME speed over 100", speed(101), nil, true},
+    {"zero restore speed", nil, speed(0), true},
+    {"restore speed over 100", nil, speed(255), true},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      bot := testBotAt(t, NewPosition(PositionType_E6POS), NewPosition(PositionType_E6POS))
+      bot.HomeSpeed = test.homeSpeed
+      bot.HomeRestoreSpeed = test.restoreSpeed
+      if err := bot.Check(); (err != nil) != test.wantErr {
+        t.Errorf("Check() error = %v, want error %v", err, test.wantErr)
+      }
+    })
+  }
+}
+
+func TestRunMoveGroupInput(t *testing.T) {
+  tests := []struct {
+    name     string
+    buffer   int
+    isClosed bool
+    wantErr  bool
+  }{
+    {"queued", 1, false, false},
+    {"input is full", 0, false, true},
+    {"shut down", 1, true, true},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      bot := testBotAt(t, NewPosition(PositionType_E6POS), NewPosition(PositionType_E6POS))
+      bot.SetMoveGroups([]*MoveGroup{{Id: 1}})
+      bot.oscInput = make(chan *oscCall)
+      bot.moveInput = make(chan *MoveGroup, test.buffer)
+      if test.isClosed == true {
+        bot.closeInput()
+      }
+
+      // A shut down bot refuses instead of sending on the closed channel
+      if err := bot.RunMoveGroup(1); (err != nil) != test.wantErr {
+        t.Errorf("RunMoveGroup() error = %v, want error %v", err, test.wantErr)
+      }
+    })
+  }
+}
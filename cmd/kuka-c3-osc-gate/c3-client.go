@@ -6,6 +6,7 @@ import (
 	"log"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,11 +14,13 @@ const (
   C3Client_PacketsBuffer = 512
   C3Client_TCPBuffer = 2048
   C3Client_RetryTimeout = 5 * time.Second
+  C3Client_RequestTimeout = 3 * time.Second
 )
 
 type AsyncC3Message struct {
   Message    *C3Message
   ResultChan chan *C3Message
+  sentTime   time.Time
 }
 
 type C3Client struct {
@@ -26,6 +29,13 @@ type C3Client struct {
   conn        *net.TCPConn
   connMux     sync.Mutex
   isConnected bool
+  isLost      bool
+
+  // connected mirrors isConnected for readers, connMux is held while dialing
+  connected atomic.Bool
+
+  // onConnection is called with false when an established connection drops and with true when it is restored
+  onConnection func(isConnected bool)
 
   messageStore    map[uint16]*AsyncC3Message
   messageStoreMux sync.Mutex
@@ -33,13 +43,22 @@ type C3Client struct {
   requestPackets  chan []byte
   responsePackets chan []byte
 
+  // Statistics for metrics, a request without response within C3Client_RequestTimeout is a timeout,
+  // a pending request replaced by a new one with the same tagID is a collision
+  latency    *MetricHistogram
+  requests   atomic.Uint64
+  timeouts   atomic.Uint64
+  collisions atomic.Uint64
+  reconnects atomic.Uint64
+  dropped    atomic.Uint64
+
   isShutdown bool
 
   closeOnce sync.Once
   wg sync.WaitGroup
 }
 
-func NewC3Client(address string) (*C3Client, error) {
+func NewC3Client(address string, onConnection func(isConnected bool)) (*C3Client, error) {
   addr, err := net.ResolveTCPAddr("tcp4", address)
   if err != nil {
     return nil, fmt.Errorf("C3Client client failed to resolve TCP address: %w", err)
@@ -47,9 +66,11 @@ func NewC3Client(address string) (*C3Client, error) {
 
   с3 := &C3Client{
     addr: addr,
+    onConnection: onConnection,
     messageStore: make(map[uint16]*AsyncC3Message),
     requestPackets:  make(chan []byte, C3Client_PacketsBuffer),
     responsePackets: make(chan []byte, C3Client_PacketsBuffer),
+    latency: NewMetricHistogram(Metrics_LatencyBuckets),
   }
 
   с3.wg.Add(1)
@@ -68,8 +89,22 @@ func (c3 *C3Client) setMessage(msg *C3Message) *AsyncC3Message {
   c3.messageStoreMux.Lock()
   defer c3.messageStoreMux.Unlock()
   
-  asyncMessage := &AsyncC3Message{Message: msg, ResultChan: make(chan *C3Message)}
+  // Requests which are never answered are dropped here, the caller has already given up on them
+  now := time.Now()
+  for tagID, stored := range c3.messageStore {
+    switch {
+      case now.Sub(stored.sentTime) > C3Client_RequestTimeout:
+        delete(c3.messageStore, tagID)
+        c3.timeouts.Add(1)
+      case tagID == msg.TagID(nil):
+        delete(c3.messageStore, tagID)
+        c3.collisions.Add(1)
+    }
+  }
+
+  asyncMessage := &AsyncC3Message{Message: msg, ResultChan: make(chan *C3Message, 1), sentTime: now}
   c3.messageStore[msg.TagID(nil)] = asyncMessage
+  c3.requests.Add(1)
   return asyncMessage
 }
 
@@ -87,6 +122,10 @@ func (c3 *C3Client) getMessage(tagID uint16) *AsyncC3Message {
 
 func (c3 *C3Client) connect() error {
   for {
+    if c3.isShutdown == true {
+      return fmt.Errorf("C3Client is shutdown")
+    }
+
     c3.connMux.Lock()
     if c3.isConnected {
       c3.connMux.Unlock()
@@ -102,8 +141,36 @@ func (c3 *C3Client) connect() error {
     }
 
     c3.isConnected = true
+    c3.connected.Store(true)
+    isRestored := c3.isLost
+    c3.isLost = false
     c3.connMux.Unlock()
     log.Printf("[C3Client INFO] Connected successfully to %s\n", c3.addr.String())
+
+    if isRestored == true {
+      c3.reconnects.Add(1)
+      if c3.onConnection != nil {
+        c3.onConnection(true)
+      }
+    }
+  }
+}
+
+// disconnect closes the connection, must be called with connMux locked
+func (c3 *C3Client) disconnect() bool {
+  isLost := c3.isConnected == true && c3.isShutdown == false
+  c3.conn.Close()
+  c3.isConnected = false
+  c3.connected.Store(false)
+  if isLost == true {
+    c3.isLost = true
+  }
+  return isLost
+}
+
+func (c3 *C3Client) lost() {
+  if c3.onConnection != nil {
+    c3.onConnection(false)
   }
 }
 
@@ -116,12 +183,16 @@ func (c3 *C3Client) processRequest() {
     }
 
     c3.connMux.Lock()
+    var isLost bool = false
     if _, err := c3.conn.Write(packet); err != nil {
       log.Printf("[C3Client ERROR] Failed to send data: %v\n", err)
-      c3.conn.Close()
-      c3.isConnected = false
+      isLost = c3.disconnect()
     }
     c3.connMux.Unlock()
+
+    if isLost == true {
+      c3.lost()
+    }
   }
 }
 
@@ -147,8 +218,12 @@ func (c3 *C3Client) processResponse() {
         if c3.isShutdown == false {
           log.Printf("[C3Client ERROR] Failed to read response: %v\n", err)
         }
-        c3.conn.Close()
-        c3.isConnected = false
+        c3.connMux.Lock()
+        isLost := c3.disconnect()
+        c3.connMux.Unlock()
+        if isLost == true {
+          c3.lost()
+        }
         break
       }
       packet := make([]byte, n)
@@ -156,6 +231,7 @@ func (c3 *C3Client) processResponse() {
       select {
         case c3.responsePackets <- packet:
         default:
+          c3.dropped.Add(1)
           log.Printf("[C3Client WARNING] Response packets channel is full, discarding packet\n")
       }
     }
@@ -182,6 +258,7 @@ func (c3 *C3Client) processMessage() {
       continue
     }
 
+    c3.latency.Observe(time.Since(asyncMessage.sentTime).Seconds())
     asyncMessage.ResultChan <- asyncMessage.Message
     close(asyncMessage.ResultChan)
   }
@@ -194,6 +271,10 @@ func (c3 *C3Client) closePackets() {
   })
 }
 
+func (c3 *C3Client) IsConnected() bool {
+  return c3.connected.Load()
+}
+
 func (c3 *C3Client) Request(message *C3Message) (chan *C3Message, error) {
   packet, err := message.Request()
   if err != nil {
@@ -207,7 +288,11 @@ func (c3 *C3Client) Request(message *C3Message) (chan *C3Message, error) {
 
 func (c3 *C3Client) Shutdown() {
   c3.isShutdown = true
-  c3.conn.Close()
+  c3.connMux.Lock()
+  if c3.conn != nil {
+    c3.conn.Close()
+  }
+  c3.connMux.Unlock()
   c3.closePackets()
   c3.wg.Wait()
   log.Printf("[C3Client INFO] Client shutdown successfully\n")
@@ -28,6 +28,10 @@ type C3Emelate struct {
   COM_ROUNDM     C3VariableComRoundmValues
   COM_E6AXIS     *Position
   COM_E6POS      *Position
+  COM_VALUE1     string
+  COM_VALUE2     string
+  COM_VALUE3     string
+  COM_VALUE4     string
   
   POSITION       *Position
 
@@ -245,6 +249,14 @@ func (c3 *C3Emelate) processMessage(request []byte) ([]byte, error) {
           c3.COM_E6AXIS.Parse(variableValue)
         case C3Variable_COM_E6POS:
           c3.COM_E6POS.Parse(variableValue)
+        case C3Variable_COM_VALUE1:
+          c3.COM_VALUE1 = variableValue
+        case C3Variable_COM_VALUE2:
+          c3.COM_VALUE2 = variableValue
+        case C3Variable_COM_VALUE3:
+          c3.COM_VALUE3 = variableValue
+        case C3Variable_COM_VALUE4:
+          c3.COM_VALUE4 = variableValue
         default:
           c3Error = C3Message_Error_NotImplemented
       }
@@ -268,6 +280,14 @@ func (c3 *C3Emelate) processMessage(request []byte) ([]byte, error) {
         variableValue = c3.COM_E6AXIS.ValueFull()
       case C3Variable_COM_E6POS:
         variableValue = c3.COM_E6POS.ValueFull()
+      case C3Variable_COM_VALUE1:
+        variableValue = c3.COM_VALUE1
+      case C3Variable_COM_VALUE2:
+        variableValue = c3.COM_VALUE2
+      case C3Variable_COM_VALUE3:
+        variableValue = c3.COM_VALUE3
+      case C3Variable_COM_VALUE4:
+        variableValue = c3.COM_VALUE4
       case C3Variable_PROXY_TYPE:
         variableValue = c3.PROXY_TYPE
       case C3Variable_PROXY_VERSION:
@@ -370,6 +390,14 @@ func (c3 *C3Emelate) processMessage(request []byte) ([]byte, error) {
             c3.COM_E6AXIS.Parse(variableValue)
           case C3Variable_COM_E6POS:
             c3.COM_E6POS.Parse(variableValue)
+          case C3Variable_COM_VALUE1:
+            c3.COM_VALUE1 = variableValue
+          case C3Variable_COM_VALUE2:
+            c3.COM_VALUE2 = variableValue
+          case C3Variable_COM_VALUE3:
+            c3.COM_VALUE3 = variableValue
+          case C3Variable_COM_VALUE4:
+            c3.COM_VALUE4 = variableValue
           default:
             c3Error = C3Message_Error_NotImplemented
         }
@@ -393,6 +421,14 @@ func (c3 *C3Emelate) processMessage(request []byte) ([]byte, error) {
           variableValue = c3.COM_E6AXIS.ValueFull()
         case C3Variable_COM_E6POS:
           variableValue = c3.COM_E6POS.ValueFull()
+        case C3Variable_COM_VALUE1:
+          variableValue = c3.COM_VALUE1
+        case C3Variable_COM_VALUE2:
+          variableValue = c3.COM_VALUE2
+        case C3Variable_COM_VALUE3:
+          variableValue = c3.COM_VALUE3
+        case C3Variable_COM_VALUE4:
+          variableValue = c3.COM_VALUE4
         case C3Variable_PROXY_TYPE:
           variableValue = c3.PROXY_TYPE
         case C3Variable_PROXY_VERSION:
@@ -0,0 +1,166 @@
+package main
+
+import (
+  "encoding/json"
+  "reflect"
+)
+
+const (
+  ConfigSchema_Draft = "https://json-schema.org/draft/2020-12/schema"
+  ConfigSchema_Id    = "https://github.com/Lit3D/lit3d-kuka-c3-gate/kuka-c3-osc-gate.schema.json"
+)
+
+// ConfigSchema_Enums are string types with a closed set of values
+var ConfigSchema_Enums = map[reflect.Type][]string{
+  reflect.TypeOf(CueActionType("")): {
+    string(CueActionType_MoveGroup), string(CueActionType_Speed), string(CueActionType_Variable), string(CueActionType_OSC),
+  },
+  reflect.TypeOf(InternalActionCompletion("")): {
+    string(InternalActionCompletion_Pose), string(InternalActionCompletion_Signal),
+  },
+  reflect.TypeOf(TeamSyncFailure("")): {
+    string(TeamSyncFailure_Abort), string(TeamSyncFailure_Continue),
+  },
+  reflect.TypeOf(BotStartupPolicy("")): {
+    string(BotStartupPolicy_Fail), string(BotStartupPolicy_Warn), string(BotStartupPolicy_Home),
+  },
+  reflect.TypeOf(TelemetryFormat("")): {
+    string(TelemetryFormat_Axes), string(TelemetryFormat_AxesExternal), string(TelemetryFormat_Coords),
+    string(TelemetryFormat_Quaternion), string(TelemetryFormat_Matrix), string(TelemetryFormat_AxesVelocity),
+    string(TelemetryFormat_CoordsVelocity),
+  },
+  reflect.TypeOf(OSCMappingCurve("")): {
+    "", string(OSCMappingCurve_Linear), string(OSCMappingCurve_EaseIn), string(OSCMappingCurve_EaseOut),
+    string(OSCMappingCurve_EaseInOut), string(OSCMappingCurve_EaseInCubic), string(OSCMappingCurve_EaseOutCubic),
+    string(OSCMappingCurve_EaseInOutCubic), string(OSCMappingCurve_Smoothstep),
+  },
+  reflect.TypeOf(GateErrorKind("")): sortedKeys(GateEvents_Codes),
+}
+
+type configSchema struct {
+  defs map[string]any
+}
+
+// ConfigSchema generates the JSON Schema of the config file from the Team type
+func ConfigSchema() ([]byte, error) {
+  s := &configSchema{defs: make(map[string]any)}
+  root := s.object(reflect.TypeOf(Team{}))
+  root["$schema"] = ConfigSchema_Draft
+  root["$id"] = ConfigSchema_Id
+  root["title"] = "KUKA-C3-OSC-Gate config"
+  root["$defs"] = s.defs
+  return json.MarshalIndent(root, "", "  ")
+}
+
+func (s *configSchema) schema(t reflect.Type) map[string]any {
+  if t.Kind() == reflect.Pointer {
+    return nullable(s.schema(t.Elem()))
+  }
+
+  switch t {
+    case reflect.TypeOf(Position{}):
+      return s.ref("Position", func() map[string]any {
+        items := make([]any, 15)
+        items[0] = map[string]any{"enum": []int{int(PositionType_NIL), int(PositionType_E6AXIS), int(PositionType_E6POS)}}
+        for i := 1; i < 15; i++ {
+          items[i] = map[string]any{"type": "number"}
+        }
+        return map[string]any{
+          "description": "Position type (1 E6AXIS, 2 E6POS) and 14 values: A1..A6 or X, Y, Z, A, B, C, S, T, E1..E6",
+          "type":        "array",
+          "prefixItems": items,
+          "minItems":    15,
+          "maxItems":    15,
+        }
+      })
+    case reflect.TypeOf(MoveStep{}):
+      return s.ref("MoveStep", func() map[string]any {
+        return map[string]any{
+          "description": "Position, raw robot frame E6POS or InternalAction name",
+          "oneOf":       []any{
+            map[string]any{"type": "string"},
+            map[string]any{"$ref": "#/$defs/Position"},
+            map[string]any{
+              "type":                 "object",
+              "properties":           map[string]any{"raw": map[string]any{"$ref": "#/$defs/Position"}},
+              "required":             []any{"raw"},
+              "additionalProperties": false,
+            },
+          },
+        }
+      })
+  }
+
+  if values, ok := ConfigSchema_Enums[t]; ok == true {
+    return map[string]any{"type": "string", "enum": values}
+  }
+
+  switch t.Kind() {
+    case reflect.Struct:
+      return s.ref(t.Name(), func() map[string]any {
+        return s.object(t)
+      })
+    case reflect.Slice:
+      return nullable(map[string]any{"type": "array", "items": s.schema(t.Elem())})
+    case reflect.Array:
+      return map[string]any{"type": "array", "items": s.schema(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+    case reflect.Map:
+      schema := map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+      if values, ok := ConfigSchema_Enums[t.Key()]; ok == true {
+        schema["propertyNames"] = map[string]any{"enum": values}
+      }
+      return nullable(schema)
+    case reflect.String:
+      return map[string]any{"type": "string"}
+    case reflect.Bool:
+      return map[string]any{"type": "boolean"}
+    case reflect.Float32, reflect.Float64:
+      return map[string]any{"type": "number"}
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+         reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+      min, max := jsonIntRange(t)
+      return map[string]any{"type": "integer", "minimum": min, "maximum": max}
+  }
+  return map[string]any{}
+}
+
+func (s *configSchema) object(t reflect.Type) map[string]any {
+  fields := jsonFields(t)
+  properties := make(map[string]any, len(fields))
+  for _, name := range sortedKeys(fields) {
+    properties[name] = s.schema(fields[name].Type)
+  }
+  return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+}
+
+// ref adds a named definition once, recursive types see the reference while they are generated
+func (s *configSchema) ref(name string, schema func() map[string]any) map[string]any {
+  if _, ok := s.defs[name]; ok == false {
+    s.defs[name] = true
+    s.defs[name] = schema()
+  }
+  return map[string]any{"$ref": "#/$defs/" + name}
+}
+
+// nullable allows null as encoding/json does for pointers, slices and maps
+func nullable(schema map[string]any) map[string]any {
+  if _, ok := schema["$ref"]; ok == true {
+    return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+  }
+  if kind, ok := schema["type"].(string); ok == true {
+    schema["type"] = []string{kind, "null"}
+    if values, ok := schema["enum"].([]string); ok == true {
+      schema["enum"] = append(append([]any{}, stringsToAny(values)...), nil)
+    }
+    return schema
+  }
+  return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
+
+func stringsToAny(values []string) []any {
+  result := make([]any, len(values))
+  for i, value := range values {
+    result[i] = value
+  }
+  return result
+}
@@ -0,0 +1,672 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "math"
+  "net"
+  "os"
+  "reflect"
+  "sort"
+  "strconv"
+  "strings"
+)
+
+type ConfigIssueLevel string
+
+const (
+  ConfigIssueLevel_Error   ConfigIssueLevel = "error"   // Config is rejected at start and on reload
+  ConfigIssueLevel_Warning ConfigIssueLevel = "warning" // Config is accepted, the setting is probably a mistake
+)
+
+// ConfigIssue is one validation finding, Path is a JSON path like $.bots[0].moveGroups[2].positions[1]
+type ConfigIssue struct {
+  Path    string           `json:"path"`
+  Level   ConfigIssueLevel `json:"level"`
+  Message string           `json:"message"`
+}
+
+func (issue *ConfigIssue) String() string {
+  return fmt.Sprintf("%s %s: %s", issue.Level, issue.Path, issue.Message)
+}
+
+type ConfigIssues []*ConfigIssue
+
+func (issues ConfigIssues) Errors() ConfigIssues {
+  var errors ConfigIssues
+  for _, issue := range issues {
+    if issue.Level == ConfigIssueLevel_Error {
+      errors = append(errors, issue)
+    }
+  }
+  return errors
+}
+
+func (issues ConfigIssues) Warnings() ConfigIssues {
+  var warnings ConfigIssues
+  for _, issue := range issues {
+    if issue.Level == ConfigIssueLevel_Warning {
+      warnings = append(warnings, issue)
+    }
+  }
+  return warnings
+}
+
+// Err summarises config errors, nil when there are only warnings
+func (issues ConfigIssues) Err() error {
+  errors := issues.Errors()
+  switch len(errors) {
+    case 0:
+      return nil
+    case 1:
+      return fmt.Errorf("%s: %s", errors[0].Path, errors[0].Message)
+  }
+  return fmt.Errorf("%s: %s (and %d more errors)", errors[0].Path, errors[0].Message, len(errors) - 1)
+}
+
+// sorted lists errors before warnings, keeping the config order
+func (issues ConfigIssues) sorted() ConfigIssues {
+  sort.SliceStable(issues, func(i, j int) bool {
+    return issues[i].Level == ConfigIssueLevel_Error && issues[j].Level != ConfigIssueLevel_Error
+  })
+  return issues
+}
+
+type configValidator struct {
+  issues   ConfigIssues
+  oscPaths map[string]string // OSC request path to the JSON path which registered it
+}
+
+func (v *configValidator) error(path string, format string, args ...any) {
+  v.issues = append(v.issues, &ConfigIssue{Path: path, Level: ConfigIssueLevel_Error, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *configValidator) warning(path string, format string, args ...any) {
+  v.issues = append(v.issues, &ConfigIssue{Path: path, Level: ConfigIssueLevel_Warning, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *configValidator) check(path string, err error) {
+  if err != nil {
+    v.error(path, "%v", err)
+  }
+}
+
+// oscPath registers an OSC request path, every path must have one owner
+func (v *configValidator) oscPath(path string, oscPath *string) {
+  if oscPath == nil {
+    return
+  }
+  if *oscPath == "" || strings.HasPrefix(*oscPath, "/") == false {
+    v.error(path, "OSC path %q must start with /", *oscPath)
+    return
+  }
+  if owner, ok := v.oscPaths[*oscPath]; ok == true {
+    v.error(path, "OSC path %s is already used by %s", *oscPath, owner)
+    return
+  }
+  v.oscPaths[*oscPath] = path
+}
+
+// oscAddress checks an OSC output address without resolving the host
+func (v *configValidator) oscAddress(path string, address *string) {
+  if address == nil {
+    return
+  }
+  oscAddress, err := ParseOSCAddress(*address)
+  if err != nil {
+    v.error(path, "%v", err)
+    return
+  }
+  v.check(path, checkHostPort(oscAddress.Host))
+}
+
+func (v *configValidator) telemetry(path string, telemetry []*Telemetry) {
+  for i, t := range telemetry {
+    v.check(fmt.Sprintf("%s[%d]", path, i), t.Check())
+  }
+}
+
+func (v *configValidator) destinations(path string, destinations []*OSCDestination) {
+  for i, destination := range destinations {
+    destinationPath := fmt.Sprintf("%s[%d]", path, i)
+    v.oscAddress(destinationPath + ".address", &destination.Address)
+    v.telemetry(destinationPath + ".telemetry", destination.Telemetry)
+  }
+}
+
+func (v *configValidator) internalActions(path string, actions []*InternalAction) {
+  names := make(map[string]bool, len(actions))
+  for i, action := range actions {
+    actionPath := fmt.Sprintf("%s[%d]", path, i)
+    v.check(actionPath, action.Check())
+    if names[action.Name] == true {
+      v.error(actionPath + ".name", "InternalAction %s is duplicated", action.Name)
+    }
+    names[action.Name] = true
+  }
+}
+
+func (v *configValidator) speed(path string, speed *uint8) {
+  if speed != nil && (*speed == 0 || *speed > 100) {
+    v.error(path, "Speed %d is out of 1..100 range", *speed)
+  }
+}
+
+func (v *configValidator) startupPolicy(path string, policy *BotStartupPolicy) {
+  if policy == nil {
+    return
+  }
+  switch *policy {
+    case BotStartupPolicy_Fail, BotStartupPolicy_Warn, BotStartupPolicy_Home:
+    default:
+      v.error(path, "Incorrect startup policy %q", *policy)
+  }
+}
+
+func (v *configValidator) oscGuard(path string, guard *OSCGuard) {
+  for i, rule := range guard.Allow {
+    rulePath := fmt.Sprintf("%s.allow[%d]", path, i)
+    if rule.Path == "" || strings.HasPrefix(rule.Path, "/") == false {
+      v.error(rulePath + ".path", "Allow rule path %q must start with /", rule.Path)
+    }
+    if len(rule.Sources) == 0 {
+      v.error(rulePath + ".sources", "Allow rule %s has no sources, its addresses are denied to everyone", rule.Path)
+    }
+    for j, source := range rule.Sources {
+      if _, err := oscGuardNet(source); err != nil {
+        v.error(fmt.Sprintf("%s.sources[%d]", rulePath, j), "Allow rule %s %v", rule.Path, err)
+      }
+    }
+  }
+  if guard.Secret != nil && *guard.Secret == "" {
+    v.error(path + ".secret", "Secret is empty")
+  }
+  if guard.HMACKey != nil && *guard.HMACKey == "" {
+    v.error(path + ".hmacKey", "HMAC key is empty")
+  }
+  if guard.HMACWindow != nil && *guard.HMACWindow <= 0 {
+    v.error(path + ".hmacWindow", "HMAC window %v must be positive", *guard.HMACWindow)
+  }
+  if guard.HMACWindow != nil && guard.HMACKey == nil {
+    v.warning(path + ".hmacWindow", "HMAC window is set without an HMAC key, it is not used")
+  }
+  v.oscPath(path + ".oscArmPath", guard.OSCArmPath)
+  if guard.RequireArmed != nil && *guard.RequireArmed == true && guard.OSCArmPath == nil {
+    v.warning(path + ".oscArmPath", "Arm path is not set, the gate is armed with the HTTP API only")
+  }
+}
+
+// Validate checks the whole config without connecting to robots or opening sockets.
+// Bots inherit team settings as on start, so bot limits apply to team HOME and MoveGroups.
+func (team *Team) Validate() ConfigIssues {
+  v := &configValidator{oscPaths: make(map[string]string)}
+
+  if team.Events != nil {
+    v.check("$.events", team.Events.Check())
+  }
+  if team.Sync != nil {
+    v.check("$.sync", team.Sync.Check())
+  }
+  if team.OSCGuard != nil {
+    v.oscGuard("$.oscGuard", team.OSCGuard)
+  }
+  if team.OSCBundleMaxDelay != nil && *team.OSCBundleMaxDelay <= 0 {
+    v.error("$.oscBundleMaxDelay", "OSC bundle max delay %v must be positive", *team.OSCBundleMaxDelay)
+  }
+
+  v.oscAddress("$.oscResponseAddress", team.OSCResponseAddress)
+  v.destinations("$.oscDestinations", team.OSCDestinations)
+  v.telemetry("$.telemetry", team.Telemetry)
+
+  if team.Home != nil && team.Home.Type() != PositionType_E6AXIS {
+    v.error("$.home", "HOME position must be of E6AXIS type")
+  }
+  v.speed("$.homeSpeed", team.HomeSpeed)
+  v.speed("$.homeRestoreSpeed", team.HomeRestoreSpeed)
+  v.startupPolicy("$.startupPolicy", team.StartupPolicy)
+  if team.Limits != nil {
+    v.check("$.limits", team.Limits.Check())
+  }
+  v.internalActions("$.internalActions", team.InternalActions)
+
+  v.oscPath("$.oscRequestPositionPath", team.OSCRequestPosition)
+  if team.OSCRequestTeach != nil {
+    v.oscPath("$.oscRequestTeachPath", team.OSCRequestTeach)
+    v.oscPath("$.oscRequestTeachPath", stringPtr(*team.OSCRequestTeach + "/undo"))
+  }
+  if team.OSCRequestTimeline != nil {
+    for _, command := range []TimelineCommand{
+      TimelineCommand_Go, TimelineCommand_Back, TimelineCommand_Jump, TimelineCommand_Play, TimelineCommand_Stop,
+    } {
+      v.oscPath("$.oscRequestTimelinePath", stringPtr(*team.OSCRequestTimeline + "/" + string(command)))
+    }
+  }
+  if team.Timecode != nil {
+    v.oscPath("$.timecode.oscPath", team.Timecode.OSCPath)
+  }
+
+  names := make(map[string]int, len(team.Bots))
+  for i, bot := range team.Bots {
+    path := fmt.Sprintf("$.bots[%d]", i)
+    if bot.Name == "" {
+      v.warning(path + ".name", "Bot name is empty, the bot is addressed by index only")
+    } else if j, ok := names[bot.Name]; ok == true {
+      v.error(path + ".name", "Bot name %q is already used by $.bots[%d]", bot.Name, j)
+    } else {
+      names[bot.Name] = i
+    }
+    team.validateBot(v, path, bot)
+  }
+
+  team.validateTimelines(v)
+
+  return v.issues.sorted()
+}
+
+func (team *Team) validateBot(v *configValidator, path string, bot *Bot) {
+  if bot.Address == "" {
+    v.error(path + ".address", "C3 address is empty")
+  } else {
+    v.check(path + ".address", checkHostPort(bot.Address))
+  }
+
+  // Own settings are checked before the team ones are inherited, so team errors are reported once
+  v.oscAddress(path + ".oscResponseAddress", bot.OSCResponseAddress)
+  v.destinations(path + ".oscDestinations", bot.OSCDestinations)
+  v.telemetry(path + ".telemetry", bot.Telemetry)
+  v.internalActions(path + ".internalActions", bot.InternalActions)
+  v.speed(path + ".homeSpeed", bot.HomeSpeed)
+  v.speed(path + ".homeRestoreSpeed", bot.HomeRestoreSpeed)
+  v.startupPolicy(path + ".startupPolicy", bot.StartupPolicy)
+  if bot.Home != nil && bot.Home.Type() != PositionType_E6AXIS {
+    v.error(path + ".home", "HOME position must be of E6AXIS type")
+  }
+  if bot.Limits != nil {
+    v.check(path + ".limits", bot.Limits.Check())
+  }
+
+  v.oscPath(path + ".oscRequestAxisPath", bot.OSCRequestAxis)
+  v.oscPath(path + ".oscRequestCoordsPath", bot.OSCRequestCoords)
+  v.oscPath(path + ".oscRequestPositionPath", bot.OSCRequestPosition)
+
+  // Mappings of one bot share a path to read several arguments of one message
+  mappingPaths := make(map[string]bool)
+  for i, mapping := range bot.OSCMappings {
+    mappingPath := fmt.Sprintf("%s.oscMappings[%d]", path, i)
+    if err := mapping.Check(); err != nil {
+      v.error(mappingPath, "%v", err)
+      continue
+    }
+    if mappingPaths[mapping.Path] == false {
+      mappingPaths[mapping.Path] = true
+      v.oscPath(mappingPath + ".path", &mapping.Path)
+    }
+  }
+  if _, _, err := oscMappingPaths(bot.OSCMappings); err != nil {
+    v.error(path + ".oscMappings", "%v", err)
+  }
+
+  team.inherit(bot)
+
+  if home := bot.homePosition(); home.Type() == PositionType_E6AXIS {
+    if err := bot.Limits.CheckPosition(home); err != nil {
+      v.error(path + ".home", "HOME %v", err)
+    }
+  }
+
+  ids := make(map[uint16]int, len(bot.MoveGroups))
+  for i, moveGroup := range bot.MoveGroups {
+    groupPath := fmt.Sprintf("%s.moveGroups[%d]", path, i)
+    if moveGroup == nil {
+      v.error(groupPath, "MoveGroup is empty")
+      continue
+    }
+    if j, ok := ids[moveGroup.Id]; ok == true {
+      v.error(groupPath + ".id", "MoveGroup %d is already defined at %s.moveGroups[%d]", moveGroup.Id, path, j)
+    } else {
+      ids[moveGroup.Id] = i
+    }
+    if len(moveGroup.Positions) == 0 {
+      v.warning(groupPath + ".positions", "MoveGroup %d has no positions", moveGroup.Id)
+    }
+    for j, step := range moveGroup.Positions {
+      stepPath := fmt.Sprintf("%s.positions[%d]", groupPath, j)
+      // A missing InternalAction fails the move only, the bot starts with a warning
+      if step != nil && step.IsAction() == true && bot.GetInternalAction(step.Action) == nil {
+        v.warning(stepPath, "InternalAction %s is not found", step.Action)
+        continue
+      }
+      v.check(stepPath, bot.CheckMoveStep(step))
+    }
+  }
+}
+
+func (team *Team) validateTimelines(v *configValidator) {
+  names := make(map[string]bool, len(team.Timelines))
+  for i, timeline := range team.Timelines {
+    path := fmt.Sprintf("$.timelines[%d]", i)
+    if timeline.Name == "" {
+      v.error(path + ".name", "Timeline name is empty")
+    } else if names[timeline.Name] == true {
+      v.error(path + ".name", "Timeline %s is duplicated", timeline.Name)
+    }
+    names[timeline.Name] = true
+
+    for j, cue := range timeline.Cues {
+      cuePath := fmt.Sprintf("%s.cues[%d]", path, j)
+      if cue.At != nil && *cue.At < 0 {
+        v.error(cuePath + ".at", "Negative cue time %f", *cue.At)
+      }
+      if cue.Follow != nil && *cue.Follow < 0 {
+        v.error(cuePath + ".follow", "Negative cue follow %f", *cue.Follow)
+      }
+      for k, action := range cue.Actions {
+        actionPath := fmt.Sprintf("%s.actions[%d]", cuePath, k)
+        if err := action.Check(team); err != nil {
+          v.error(actionPath, "%v", err)
+          continue
+        }
+        if action.Type == CueActionType_OSC {
+          v.oscAddress(actionPath + ".address", action.Address)
+        }
+        if action.Type == CueActionType_MoveGroup {
+          for _, bot := range team.cueActionBots(action) {
+            if bot.GetMoveGroup(*action.MoveGroupId) == nil {
+              v.warning(actionPath + ".moveGroupId", "Bot %s has no MoveGroup %d", bot.Name, *action.MoveGroupId)
+            }
+          }
+        }
+      }
+    }
+  }
+
+  if team.Timecode != nil {
+    if team.Timecode.fps() <= 0 {
+      v.error("$.timecode.fps", "Incorrect fps %f", team.Timecode.fps())
+    }
+    if team.GetTimeline(team.Timecode.Timeline) == nil {
+      v.error("$.timecode.timeline", "Timeline %s is not found", team.Timecode.Timeline)
+    }
+    for i, source := range team.Timecode.Sources {
+      if _, err := oscGuardNet(source); err != nil {
+        v.error(fmt.Sprintf("$.timecode.sources[%d]", i), "Timecode %v", err)
+      }
+    }
+    if team.Timecode.Sources != nil && team.Timecode.UDPPort == nil {
+      v.warning("$.timecode.sources", "Sources only apply to UDP timecode, udpPort is not set")
+    }
+  }
+}
+
+// cueActionBots lists bots of an action, an action without bots runs on the whole team
+func (team *Team) cueActionBots(action *CueAction) []*Bot {
+  if len(action.Bots) == 0 {
+    return team.Bots
+  }
+  bots := make([]*Bot, 0, len(action.Bots))
+  for _, name := range action.Bots {
+    if bot := team.GetBotByName(name); bot != nil {
+      bots = append(bots, bot)
+    }
+  }
+  return bots
+}
+
+// checkHostPort checks host:port with a numeric port, the host is not resolved
+func checkHostPort(address string) error {
+  host, port, err := net.SplitHostPort(address)
+  if err != nil {
+    return fmt.Errorf("Incorrect address %q: %w", address, err)
+  }
+  if host == "" {
+    return fmt.Errorf("Incorrect address %q: host is empty", address)
+  }
+  if value, err := strconv.ParseUint(port, 10, 16); err != nil || value == 0 {
+    return fmt.Errorf("Incorrect address %q: port must be 1..65535", address)
+  }
+  return nil
+}
+
+func stringPtr(value string) *string {
+  return &value
+}
+
+// ValidateConfigFile checks JSON syntax, unknown fields and value types with JSON paths,
+// then decodes the config and runs Team.Validate
+func ValidateConfigFile(filePath string) (ConfigIssues, error) {
+  data, err := os.ReadFile(filePath)
+  if err != nil {
+    return nil, fmt.Errorf("Config %s read error: %w", filePath, err)
+  }
+
+  var value any
+  if err := json.Unmarshal(data, &value); err != nil {
+    var syntaxError *json.SyntaxError
+    if errors.As(err, &syntaxError) == true {
+      line, column := jsonLineColumn(data, syntaxError.Offset)
+      return ConfigIssues{{Path: fmt.Sprintf("$ (line %d, column %d)", line, column), Level: ConfigIssueLevel_Error, Message: err.Error()}}, nil
+    }
+    return ConfigIssues{{Path: "$", Level: ConfigIssueLevel_Error, Message: err.Error()}}, nil
+  }
+
+  v := &configValidator{}
+  v.value("$", reflect.TypeOf(Team{}), value)
+  if len(v.issues.Errors()) > 0 {
+    return v.issues.sorted(), nil
+  }
+
+  team := NewTeam(filePath)
+  if err := json.NewDecoder(bytes.NewReader(data)).Decode(team); err != nil {
+    return append(v.issues, &ConfigIssue{Path: "$", Level: ConfigIssueLevel_Error, Message: err.Error()}).sorted(), nil
+  }
+  return append(team.Validate(), v.issues...).sorted(), nil
+}
+
+// jsonLineColumn points at the offending byte, json.SyntaxError.Offset counts it as read
+func jsonLineColumn(data []byte, offset int64) (int, int) {
+  if offset > int64(len(data)) {
+    offset = int64(len(data))
+  }
+  if offset > 0 {
+    offset--
+  }
+  before := data[:offset]
+  line := bytes.Count(before, []byte("\n")) + 1
+  column := int(offset) - bytes.LastIndexByte(before, '\n')
+  return line, column
+}
+
+// value walks a decoded JSON value along the Go config type, it reports what json.Decoder
+// rejects or silently drops with the JSON path of the value
+func (v *configValidator) value(path string, t reflect.Type, value any) {
+  if t.Kind() == reflect.Pointer {
+    if value == nil {
+      return
+    }
+    t = t.Elem()
+  }
+
+  switch t {
+    case reflect.TypeOf(Position{}):
+      v.position(path, value)
+      return
+    case reflect.TypeOf(MoveStep{}):
+      switch step := value.(type) {
+        case string:
+        case map[string]any:
+          for _, key := range sortedKeys(step) {
+            if key != "raw" {
+              v.warning(path + "." + key, "Unknown field is ignored")
+            }
+          }
+          v.position(path + ".raw", step["raw"])
+        default:
+          v.position(path, value)
+      }
+      return
+  }
+
+  switch t.Kind() {
+    case reflect.Interface:
+
+    case reflect.Struct:
+      object, ok := value.(map[string]any)
+      if ok == false {
+        v.error(path, "Must be an object, got %s", jsonKind(value))
+        return
+      }
+      fields := jsonFields(t)
+      for _, key := range sortedKeys(object) {
+        field, ok := fields[key]
+        if ok == false {
+          for name, f := range fields {
+            if strings.EqualFold(name, key) == true {
+              field, ok = f, true
+              break
+            }
+          }
+        }
+        if ok == false {
+          v.warning(path + "." + key, "Unknown field is ignored")
+          continue
+        }
+        v.value(path + "." + key, field.Type, object[key])
+      }
+
+    case reflect.Slice, reflect.Array:
+      if value == nil && t.Kind() == reflect.Slice {
+        return
+      }
+      array, ok := value.([]any)
+      if ok == false {
+        v.error(path, "Must be an array, got %s", jsonKind(value))
+        return
+      }
+      if t.Kind() == reflect.Array && len(array) != t.Len() {
+        v.error(path, "Must have %d items, got %d", t.Len(), len(array))
+        return
+      }
+      for i, item := range array {
+        v.value(fmt.Sprintf("%s[%d]", path, i), t.Elem(), item)
+      }
+
+    case reflect.Map:
+      if value == nil {
+        return
+      }
+      object, ok := value.(map[string]any)
+      if ok == false {
+        v.error(path, "Must be an object, got %s", jsonKind(value))
+        return
+      }
+      for _, key := range sortedKeys(object) {
+        v.value(fmt.Sprintf("%s[%q]", path, key), t.Elem(), object[key])
+      }
+
+    case reflect.String:
+      if _, ok := value.(string); ok == false {
+        v.error(path, "Must be a string, got %s", jsonKind(value))
+      }
+
+    case reflect.Bool:
+      if _, ok := value.(bool); ok == false {
+        v.error(path, "Must be a boolean, got %s", jsonKind(value))
+      }
+
+    case reflect.Float32, reflect.Float64:
+      if _, ok := value.(float64); ok == false {
+        v.error(path, "Must be a number, got %s", jsonKind(value))
+      }
+
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+         reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+      number, ok := value.(float64)
+      if ok == false {
+        v.error(path, "Must be an integer, got %s", jsonKind(value))
+        return
+      }
+      min, max := jsonIntRange(t)
+      if number != math.Trunc(number) || number < min || number > max {
+        v.error(path, "Must be an integer in %.0f..%.0f range, got %v", min, max, number)
+      }
+  }
+}
+
+func (v *configValidator) position(path string, value any) {
+  array, ok := value.([]any)
+  if ok == false {
+    v.error(path, "Position must be an array of numbers, got %s", jsonKind(value))
+    return
+  }
+  if len(array) != 15 {
+    v.error(path, "Position must have type and 14 values, got %d items", len(array))
+    return
+  }
+  for i, item := range array {
+    if _, ok := item.(float64); ok == false {
+      v.error(fmt.Sprintf("%s[%d]", path, i), "Must be a number, got %s", jsonKind(item))
+      return
+    }
+  }
+  switch PositionType(array[0].(float64)) {
+    case PositionType_NIL, PositionType_E6AXIS, PositionType_E6POS:
+    default:
+      v.error(path + "[0]", "Incorrect position type %v, must be 1 (E6AXIS) or 2 (E6POS)", array[0])
+  }
+}
+
+func jsonKind(value any) string {
+  switch value.(type) {
+    case nil:
+      return "null"
+    case bool:
+      return "boolean"
+    case float64:
+      return "number"
+    case string:
+      return "string"
+    case []any:
+      return "array"
+  }
+  return "object"
+}
+
+func jsonIntRange(t reflect.Type) (float64, float64) {
+  bits := t.Bits()
+  switch t.Kind() {
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+      return 0, math.Pow(2, float64(bits)) - 1
+  }
+  return -math.Pow(2, float64(bits - 1)), math.Pow(2, float64(bits - 1)) - 1
+}
+
+// jsonFields maps JSON names of exported struct fields to the fields, as encoding/json does
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+  fields := make(map[string]reflect.StructField)
+  for i := 0; i < t.NumField(); i++ {
+    field := t.Field(i)
+    if field.IsExported() == false {
+      continue
+    }
+    name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+    switch name {
+      case "-":
+        continue
+      case "":
+        name = field.Name
+    }
+    fields[name] = field
+  }
+  return fields
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []string {
+  keys := make([]string, 0, len(m))
+  for key := range m {
+    keys = append(keys, string(key))
+  }
+  sort.Strings(keys)
+  return keys
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+func testValidateConfig(t *testing.T, config string) ConfigIssues {
+  t.Helper()
+  filePath := filepath.Join(t.TempDir(), "kuka-c3-osc-gate.json")
+  if err := os.WriteFile(filePath, []byte(config), 0o644); err != nil {
+    t.Fatalf("WriteFile() error: %v", err)
+  }
+  issues, err := ValidateConfigFile(filePath)
+  if err != nil {
+    t.Fatalf("ValidateConfigFile() error: %v", err)
+  }
+  return issues
+}
+
+func TestValidateConfigFileValid(t *testing.T) {
+  issues := testValidateConfig(t, `{
+    "bots": [{
+      "name": "Left",
+      "address": "127.0.0.1:7000",
+      "moveGroups": [{ "id": 1, "positions": [[1, 0, -90, 90, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0]] }]
+    }]
+  }`)
+  if len(issues) != 0 {
+    t.Errorf("ValidateConfigFile() = %v, want no issues", issues)
+  }
+}
+
+func TestValidateConfigFilePaths(t *testing.T) {
+  tests := []struct {
+    name    string
+    config  string
+    path    string
+    level   ConfigIssueLevel
+    message string
+  }{
+    // JSON syntax and value types, reported before the config is decoded
+    {"syntax error", "{\n  \"bots\": [,]\n}", "$ (line 2, column 12)", ConfigIssueLevel_Error, "invalid character"},
+    {"not an object", `[]`, "$", ConfigIssueLevel_Error, "Must be an object, got array"},
+    {"unknown field", `{"bots": [{"name": "Left", "address": "127.0.0.1:7000", "colour": 1}]}`,
+      "$.bots[0].colour", ConfigIssueLevel_Warning, "Unknown field is ignored"},
+    {"string type", `{"bots": [{"name": 1, "address": "127.0.0.1:7000"}]}`,
+      "$.bots[0].name", ConfigIssueLevel_Error, "Must be a string, got number"},
+    {"boolean type", `{"oscReplyToSender": "yes"}`, "$.oscReplyToSender", ConfigIssueLevel_Error, "Must be a boolean, got string"},
+    {"array type", `{"bots": {}}`, "$.bots", ConfigIssueLevel_Error, "Must be an array, got object"},
+    {"integer range", `{"timecode": {"udpPort": 70000}}`, "$.timecode.udpPort", ConfigIssueLevel_Error, "Must be an integer in 0..65535 range"},
+    {"integer fraction", `{"bots": [{"address": "127.0.0.1:7000", "moveGroups": [{"id": 1.5}]}]}`,
+      "$.bots[0].moveGroups[0].id", ConfigIssueLevel_Error, "Must be an integer"},
+    {"map value", `{"events": {"codes": {"badArity": "1"}}}`, `$.events.codes["badArity"]`, ConfigIssueLevel_Error, "Must be an integer"},
+    {"position length", `{"home": [1, 0, 0]}`, "$.home", ConfigIssueLevel_Error, "Position must have type and 14 values, got 3 items"},
+    {"position type", `{"home": [3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0]}`, "$.home[0]", ConfigIssueLevel_Error, "Incorrect position type 3"},
+    {"position value", `{"home": [1, 0, "0", 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0]}`, "$.home[2]", ConfigIssueLevel_Error, "Must be a number, got string"},
+    {"home speed", `{"homeSpeed": 0}`, "$.homeSpeed", ConfigIssueLevel_Error, "Speed 0 is out of 1..100 range"},
+    {"bot home restore speed", `{"bots": [{"name": "Left", "address": "127.0.0.1:7000", "homeRestoreSpeed": 150}]}`,
+      "$.bots[0].homeRestoreSpeed", ConfigIssueLevel_Error, "Speed 150 is out of 1..100 range"},
+    {"move step", `{"bots": [{"address": "127.0.0.1:7000", "moveGroups": [{"id": 1, "positions": [[1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0], true]}]}]}`,
+      "$.bots[0].moveGroups[0].positions[1]", ConfigIssueLevel_Error, "Position must be an array of numbers, got boolean"},
+
+    // Team.Validate, run on the decoded config
+    {"empty address", `{"bots": [{"name": "Left", "address": ""}]}`, "$.bots[0].address", ConfigIssueLevel_Error, "C3 address is empty"},
+    {"empty bot name", `{"bots": [{"address": "127.0.0.1:7000"}]}`, "$.bots[0].name", ConfigIssueLevel_Warning, "Bot name is empty"},
+    {"duplicated bot name", `{"bots": [{"name": "Left", "address": "127.0.0.1:7000"}, {"name": "Left", "address": "127.0.0.1:7001"}]}`,
+      "$.bots[1].name", ConfigIssueLevel_Error, "already used by $.bots[0]"},
+    {"duplicated OSC path", `{"oscRequestPositionPath": "/pos", "bots": [{"name": "Left", "address": "127.0.0.1:7000", "oscRequestAxisPath": "/pos"}]}`,
+      "$.bots[0].oscRequestAxisPath", ConfigIssueLevel_Error, "already used by $.oscRequestPositionPath"},
+    {"duplicated MoveGroup", `{"bots": [{"name": "Left", "address": "127.0.0.1:7000", "moveGroups": [{"id": 1, "positions": []}, {"id": 1, "positions": []}]}]}`,
+      "$.bots[0].moveGroups[1].id", ConfigIssueLevel_Error, "already defined at $.bots[0].moveGroups[0]"},
+    {"empty MoveGroup", `{"bots": [{"name": "Left", "address": "127.0.0.1:7000", "moveGroups": [{"id": 1, "positions": []}]}]}`,
+      "$.bots[0].moveGroups[0].positions", ConfigIssueLevel_Warning, "has no positions"},
+    {"negative cue time", `{"timelines": [{"name": "show", "cues": [{"name": "home", "at": -1}]}]}`,
+      "$.timelines[0].cues[0].at", ConfigIssueLevel_Error, "Negative cue time"},
+    {"timecode timeline", `{"timecode": {"timeline": "show"}}`, "$.timecode.timeline", ConfigIssueLevel_Error, "Timeline show is not found"},
+    {"timecode source", `{"timecode": {"udpPort": 9100, "sources": ["10.0.0.0/33"]}}`, "$.timecode.sources[0]", ConfigIssueLevel_Error, "incorrect source"},
+    {"timecode sources without UDP", `{"timecode": {"sources": ["10.0.0.1"]}}`, "$.timecode.sources", ConfigIssueLevel_Warning, "only apply to UDP timecode"},
+    {"bundle max delay", `{"oscBundleMaxDelay": 0}`, "$.oscBundleMaxDelay", ConfigIssueLevel_Error, "must be positive"},
+    {"guard HMAC window", `{"oscGuard": {"hmacWindow": 5}}`, "$.oscGuard.hmacWindow", ConfigIssueLevel_Warning, "without an HMAC key"},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      issues := testValidateConfig(t, test.config)
+      for _, issue := range issues {
+        if issue.Path == test.path && issue.Level == test.level && strings.Contains(issue.Message, test.message) == true {
+          return
+        }
+      }
+      t.Errorf("ValidateConfigFile() = %v, want %s %s: %s", issues, test.level, test.path, test.message)
+    })
+  }
+}
+
+func TestConfigIssuesSorted(t *testing.T) {
+  issues := testValidateConfig(t, `{"bots": [{"address": "127.0.0.1:7000", "colour": 1}, {"name": "Right", "address": ""}]}`)
+  if len(issues) != 3 {
+    t.Fatalf("ValidateConfigFile() = %v, want 3 issues", issues)
+  }
+  if issues[0].Level != ConfigIssueLevel_Error || issues[0].Path != "$.bots[1].address" {
+    t.Errorf("issues[0] = %v, want the error first", issues[0])
+  }
+  if err := issues.Err(); err == nil || strings.HasPrefix(err.Error(), "$.bots[1].address: ") == false {
+    t.Errorf("Err() = %v, want $.bots[1].address error", err)
+  }
+  if len(issues.Warnings()) != 2 {
+    t.Errorf("Warnings() = %v, want 2 warnings", issues.Warnings())
+  }
+}
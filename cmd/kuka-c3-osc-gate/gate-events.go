@@ -0,0 +1,202 @@
+package main
+
+import (
+  "fmt"
+  "log"
+  "net"
+  "sort"
+  "sync"
+)
+
+const (
+  GateEvents_ErrorPath = "/gate/error"
+  GateEvents_EventPath = "/gate/event"
+)
+
+// GateErrorKind is a class of OSC input or execution error, sent as a configurable int32 code
+type GateErrorKind string
+
+const (
+  GateError_BadArity         GateErrorKind = "badArity"         // Wrong number of OSC arguments
+  GateError_BadType          GateErrorKind = "badType"          // OSC argument of a wrong type
+  GateError_UnknownMoveGroup GateErrorKind = "unknownMoveGroup" // MoveGroup id is not configured
+  GateError_UnknownTimeline  GateErrorKind = "unknownTimeline"  // Timeline name or index is not configured
+  GateError_Busy             GateErrorKind = "busy"             // Bot is already moving
+  GateError_MoveFailed       GateErrorKind = "moveFailed"       // Move is rejected or failed on the robot
+  GateError_MoveBreak        GateErrorKind = "moveBreak"        // Move is broken by the robot
+  GateError_CommandFailed    GateErrorKind = "commandFailed"    // Timeline or timecode command failed
+  GateError_Disarmed         GateErrorKind = "disarmed"         // Motion command while the gate is disarmed
+)
+
+var GateEvents_Codes = map[GateErrorKind]int32{
+  GateError_BadArity:         1,
+  GateError_BadType:          2,
+  GateError_UnknownMoveGroup: 3,
+  GateError_UnknownTimeline:  4,
+  GateError_Busy:             5,
+  GateError_MoveFailed:       6,
+  GateError_MoveBreak:        7,
+  GateError_CommandFailed:    8,
+  GateError_Disarmed:         9,
+}
+
+var GateEvents_Descriptions = map[GateErrorKind]string{
+  GateError_BadArity:         "Wrong number of OSC arguments",
+  GateError_BadType:          "OSC argument of a wrong type",
+  GateError_UnknownMoveGroup: "MoveGroup id is not configured",
+  GateError_UnknownTimeline:  "Timeline name or index is not configured",
+  GateError_Busy:             "Bot is already moving",
+  GateError_MoveFailed:       "Move is rejected or failed on the robot",
+  GateError_MoveBreak:        "Move is broken by the robot",
+  GateError_CommandFailed:    "Timeline, timecode or teach command failed",
+  GateError_Disarmed:         "Motion command is rejected, the gate is disarmed",
+}
+
+// GateEventType is the first argument of an event message: /gate/event <type> <bot> [args]
+type GateEventType string
+
+const (
+  GateEvent_MoveStarted        GateEventType = "moveStarted"        // MoveGroup id started on bot
+  GateEvent_MoveDone           GateEventType = "moveDone"           // MoveGroup id finished on bot
+  GateEvent_ConnectionLost     GateEventType = "connectionLost"     // C3 connection of bot is lost
+  GateEvent_ConnectionRestored GateEventType = "connectionRestored" // C3 connection of bot is restored
+  GateEvent_Taught             GateEventType = "taught"             // Live pose is inserted into MoveGroup id at index
+  GateEvent_TeachUndone        GateEventType = "teachUndone"        // Last teach into MoveGroup id is reverted
+  GateEvent_Armed              GateEventType = "armed"              // Motion commands are armed 1 or disarmed 0
+)
+
+var GateEvents_Types = []GateEventType{
+  GateEvent_MoveStarted, GateEvent_MoveDone, GateEvent_ConnectionLost, GateEvent_ConnectionRestored,
+  GateEvent_Taught, GateEvent_TeachUndone, GateEvent_Armed,
+}
+
+// GateEvents emits /gate/error <code> <bot> <message> and /gate/event <type> <bot> [args].
+// Bot is an empty string for team level messages.
+type GateEvents struct {
+  ErrorPath *string                 `json:"errorPath"`
+  EventPath *string                 `json:"eventPath"`
+  Codes     map[GateErrorKind]int32 `json:"codes"`
+
+  // mux guards paths and codes, Update replaces them while events are sent
+  mux sync.RWMutex
+
+  send func(source net.Addr, oscPacket *OSCPacket) error
+}
+
+type GateEventsApp struct {
+  ErrorPath string                `json:"errorPath"`
+  EventPath string                `json:"eventPath"`
+  Errors    []*GateErrorCodeApp   `json:"errors"`
+  Events    []GateEventType       `json:"events"`
+}
+
+type GateErrorCodeApp struct {
+  Kind        GateErrorKind `json:"kind"`
+  Code        int32         `json:"code"`
+  Description string        `json:"description"`
+}
+
+func (ge *GateEvents) Check() error {
+  for kind := range ge.Codes {
+    if _, ok := GateEvents_Codes[kind]; ok == false {
+      return fmt.Errorf("GateEvents unknown error kind %q", kind)
+    }
+  }
+  return nil
+}
+
+// Update copies paths and codes of next, the send function is kept
+func (ge *GateEvents) Update(next *GateEvents) {
+  ge.mux.Lock()
+  defer ge.mux.Unlock()
+  ge.ErrorPath = next.ErrorPath
+  ge.EventPath = next.EventPath
+  ge.Codes = next.Codes
+}
+
+func (ge *GateEvents) errorPath() string {
+  ge.mux.RLock()
+  defer ge.mux.RUnlock()
+  if ge.ErrorPath != nil {
+    return *ge.ErrorPath
+  }
+  return GateEvents_ErrorPath
+}
+
+func (ge *GateEvents) eventPath() string {
+  ge.mux.RLock()
+  defer ge.mux.RUnlock()
+  if ge.EventPath != nil {
+    return *ge.EventPath
+  }
+  return GateEvents_EventPath
+}
+
+func (ge *GateEvents) Code(kind GateErrorKind) int32 {
+  ge.mux.RLock()
+  defer ge.mux.RUnlock()
+  if code, ok := ge.Codes[kind]; ok {
+    return code
+  }
+  return GateEvents_Codes[kind]
+}
+
+// Error is sent to the request source when reply-to-sender is enabled, nil source uses configured outputs
+func (ge *GateEvents) Error(source net.Addr, kind GateErrorKind, bot string, message string) {
+  if ge == nil || ge.send == nil || ge.errorPath() == "" {
+    return
+  }
+
+  oscPacket := NewOSCPacket()
+  oscPacket.Path = ge.errorPath()
+  oscPacket.Append(ge.Code(kind))
+  oscPacket.Append(bot)
+  oscPacket.Append(message)
+
+  if err := ge.send(source, oscPacket); err != nil {
+    log.Printf("[GateEvents ERROR] Error %s send error: %v\n", kind, err)
+  }
+}
+
+func (ge *GateEvents) Event(event GateEventType, bot string, args ...any) {
+  if ge == nil || ge.send == nil || ge.eventPath() == "" {
+    return
+  }
+
+  oscPacket := NewOSCPacket()
+  oscPacket.Path = ge.eventPath()
+  oscPacket.Append(string(event))
+  oscPacket.Append(bot)
+  for _, arg := range args {
+    if err := oscPacket.Append(arg); err != nil {
+      log.Printf("[GateEvents ERROR] Event %s argument error: %v\n", event, err)
+      return
+    }
+  }
+
+  if err := ge.send(nil, oscPacket); err != nil {
+    log.Printf("[GateEvents ERROR] Event %s send error: %v\n", event, err)
+  }
+}
+
+func (ge *GateEvents) GetAppData() *GateEventsApp {
+  gateEventsApp := &GateEventsApp{
+    ErrorPath: ge.errorPath(),
+    EventPath: ge.eventPath(),
+    Errors:    make([]*GateErrorCodeApp, 0, len(GateEvents_Codes)),
+    Events:    GateEvents_Types,
+  }
+
+  for kind := range GateEvents_Codes {
+    gateEventsApp.Errors = append(gateEventsApp.Errors, &GateErrorCodeApp{
+      Kind:        kind,
+      Code:        ge.Code(kind),
+      Description: GateEvents_Descriptions[kind],
+    })
+  }
+  sort.Slice(gateEventsApp.Errors, func(i, j int) bool {
+    return gateEventsApp.Errors[i].Code < gateEventsApp.Errors[j].Code
+  })
+
+  return gateEventsApp
+}
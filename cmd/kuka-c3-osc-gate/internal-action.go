@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type InternalActionCompletion string
+
+const (
+  InternalActionCompletion_Pose   InternalActionCompletion = "pose"   // End position is reached
+  InternalActionCompletion_Signal InternalActionCompletion = "signal" // COM_ACTION is reset to the empty command by KRL
+)
+
+type InternalAction struct {
+  Name        string                   `json:"name"`
+  Action      uint16                   `json:"action"`
+  Completion  InternalActionCompletion `json:"completion"`
+  EndPosition *Position                `json:"endPosition"`
+  Timeout     *float64                 `json:"timeout"`
+  Tolerance   *float32                 `json:"tolerance"`
+}
+
+func (ia *InternalAction) Check() error {
+  if ia.Name == "" {
+    return fmt.Errorf("InternalAction name is empty")
+  }
+
+  switch C3VariableComActionValues(fmt.Sprintf("%d", ia.Action)) {
+    case C3Variable_COM_ACTION_EMPTY, C3Variable_COM_ACTION_E6AXIS, C3Variable_COM_ACTION_E6POS,
+         C3Variable_COM_ACTION_VELCP, C3Variable_COM_ACTION_VEL_AXIS:
+      return fmt.Errorf("InternalAction %s COM_ACTION %d is reserved", ia.Name, ia.Action)
+  }
+
+  switch ia.Completion {
+    case InternalActionCompletion_Pose:
+      if ia.EndPosition == nil {
+        return fmt.Errorf("InternalAction %s end position is not set", ia.Name)
+      }
+      if ia.EndPosition.Type() != PositionType_E6AXIS {
+        return fmt.Errorf("InternalAction %s end position must be of E6AXIS type", ia.Name)
+      }
+    case InternalActionCompletion_Signal:
+    default:
+      return fmt.Errorf("InternalAction %s incorrect completion %q", ia.Name, ia.Completion)
+  }
+
+  return nil
+}
+
+func (ia *InternalAction) ComAction() C3VariableComActionValues {
+  return C3VariableComActionValues(fmt.Sprintf("%d", ia.Action))
+}
+
+func (ia *InternalAction) GetTimeout() time.Duration {
+  if ia.Timeout != nil {
+    return time.Duration(*ia.Timeout * float64(time.Second))
+  }
+  return Bot_Move_Timeout
+}
+
+func (ia *InternalAction) GetTolerance() float32 {
+  if ia.Tolerance != nil {
+    return *ia.Tolerance
+  }
+  return Bot_Position_Tolerance
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -26,7 +27,7 @@ var (
   logPath  = filepath.Join(os.TempDir(), execName + ".log")
 )
 
-func cli() (verboseFlag bool, oscAddr net.UDPAddr, configFile string, appPort PortValue, botInit uint, emulateC3 bool) {
+func cli() (verboseFlag bool, oscAddr net.UDPAddr, oscTCPPort PortValue, oscFraming OSCFraming, oscMulticast string, oscMulticastIf string, oscQueryPort PortValue, configFile string, appPort PortValue, appOptions *ServiceOptions, appAuthFile string, botInit uint, emulateC3 bool) {
 	var printHelp bool
   var printVersion bool
   flag.BoolVar(&printHelp, "help", false, "Print help and usage information")
@@ -36,19 +37,39 @@ func cli() (verboseFlag bool, oscAddr net.UDPAddr, configFile string, appPort Po
 
   var oscPort PortValue = defaultOSCPort
   flag.Var(&oscPort, "osc", "OSC listening port")
-  oscAddr = oscPort.UDPAddr()
+
+  oscTCPPort = PortValue_NIL
+  flag.Var(&oscTCPPort, "osc-tcp", "OSC TCP listening port")
+
+  oscFraming = OSCFraming_SLIP
+  flag.Var(&oscFraming, "osc-framing", "OSC TCP framing: slip or length")
+
+  flag.StringVar(&oscMulticast, "osc-multicast", "", "OSC multicast group to join for incoming commands")
+  flag.StringVar(&oscMulticastIf, "osc-multicast-if", "", "OSC multicast interface name")
+
+  oscQueryPort = PortValue_NIL
+  flag.Var(&oscQueryPort, "oscquery", "OSCQuery HTTP/WebSocket listening port")
 
   appPort = PortValue_NIL
   flag.Var(&appPort, "app", "App listening port")
 
+  appOptions = &ServiceOptions{}
+  flag.BoolVar(&appOptions.IsLocal, "app-local", false, "App listens on localhost only")
+  flag.StringVar(&appAuthFile, "app-auth", "", "App users file with tokens, passwords and roles, no authentication when empty")
+  flag.StringVar(&appOptions.CertFile, "app-cert", "", "App TLS certificate file")
+  flag.StringVar(&appOptions.KeyFile, "app-key", "", "App TLS private key file")
+
   flag.UintVar(&botInit, "i", 0, "Bots config init with bot count")
 
   flag.BoolVar(&emulateC3, "e", false, "Emolate C3 Server")
 
-  configFile = filepath.Clean(*flag.String("cfg", defaultConfig, "Config file"))
+  configFlag := flag.String("cfg", defaultConfig, "Config file")
 
   flag.Parse()
 
+  oscAddr = oscPort.UDPAddr()
+  configFile = filepath.Clean(*configFlag)
+
   if printVersion {
     fmt.Print(versionString)
     os.Exit(0)
@@ -57,7 +78,9 @@ func cli() (verboseFlag bool, oscAddr net.UDPAddr, configFile string, appPort Po
   if printHelp {
     fmt.Print(versionString)
     fmt.Printf("Lit3D KUKA-C3-Gate\n")
-    fmt.Printf("usage: %s [options]\n\n", execName)
+    fmt.Printf("usage: %s [options]\n", execName)
+    fmt.Printf("       %s validate [-cfg file] [-json]\n", execName)
+    fmt.Printf("       %s schema\n\n", execName)
     fmt.Println("options:")
     flag.PrintDefaults()
     os.Exit(0)
@@ -70,8 +93,59 @@ func cli() (verboseFlag bool, oscAddr net.UDPAddr, configFile string, appPort Po
   return
 }
 
+// subcommand runs "validate" and "schema" commands, they exit without starting the gate
+func subcommand(args []string) {
+  switch args[0] {
+    case "validate":
+      flags := flag.NewFlagSet(execName + " validate", flag.ExitOnError)
+      configFlag := flags.String("cfg", defaultConfig, "Config file")
+      jsonFlag := flags.Bool("json", false, "Print issues as JSON")
+      flags.Parse(args[1:])
+      os.Exit(validateCommand(filepath.Clean(*configFlag), *jsonFlag))
+
+    case "schema":
+      schema, err := ConfigSchema()
+      if err != nil {
+        fmt.Fprintf(os.Stderr, "[FATAL] Config schema error: %v\n", err)
+        os.Exit(1)
+      }
+      fmt.Println(string(schema))
+      os.Exit(0)
+  }
+}
+
+func validateCommand(configFile string, jsonOutput bool) int {
+  issues, err := ValidateConfigFile(configFile)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "[FATAL] %v\n", err)
+    return 1
+  }
+
+  if jsonOutput == true {
+    if issues == nil {
+      issues = ConfigIssues{}
+    }
+    jsonData, _ := json.MarshalIndent(issues, "", "  ")
+    fmt.Println(string(jsonData))
+  } else {
+    for _, issue := range issues {
+      fmt.Println(issue.String())
+    }
+    fmt.Printf("%s: %d errors, %d warnings\n", configFile, len(issues.Errors()), len(issues.Warnings()))
+  }
+
+  if len(issues.Errors()) > 0 {
+    return 1
+  }
+  return 0
+}
+
 func main() {
-  verboseFlag, oscAddr, configFile, appPort, botInit, emulateC3 := cli()
+  if len(os.Args) > 1 {
+    subcommand(os.Args[1:])
+  }
+
+  verboseFlag, oscAddr, oscTCPPort, oscFraming, oscMulticast, oscMulticastIf, oscQueryPort, configFile, appPort, appOptions, appAuthFile, botInit, emulateC3 := cli()
 
   if botInit > 0 {
     if err := botsConfigInit(configFile, int(botInit)); err != nil {
@@ -92,6 +166,22 @@ func main() {
     log.SetOutput(logFile)
   }
 
+  if _, err := os.Stat(configFile); err == nil {
+    issues, err := ValidateConfigFile(configFile)
+    if err != nil {
+      log.Fatalf("[FATAL] Config validation error: %v\n", err)
+    }
+    for _, issue := range issues.Warnings() {
+      log.Printf("[WARNING] Config %s: %s\n", issue.Path, issue.Message)
+    }
+    for _, issue := range issues.Errors() {
+      log.Printf("[ERROR] Config %s: %s\n", issue.Path, issue.Message)
+    }
+    if err := issues.Err(); err != nil {
+      log.Fatalf("[FATAL] Config %s is invalid: %v\n", configFile, err)
+    }
+  }
+
   botsTeam := NewTeam(configFile)
   if err := botsTeam.Read(); err != nil {
   	log.Fatalf("[FATAL] BotTeam read error: %v\n", err)
@@ -108,25 +198,82 @@ func main() {
     log.Fatalf("[FATAL] OSC Server start error: %v\n", err)
   }
 
+  if oscMulticast != "" {
+    if err := oscServer.JoinMulticast(oscMulticast, oscMulticastIf); err != nil {
+      log.Fatalf("[FATAL] OSC Server multicast error: %v\n", err)
+    }
+  }
+
+  if oscTCPPort != PortValue_NIL {
+    if err := oscServer.ListenTCP(oscTCPPort.TCPAddr(), oscFraming); err != nil {
+      log.Fatalf("[FATAL] OSC TCP Server start error: %v\n", err)
+    }
+  }
+
   if err := botsTeam.Up(oscServer); err != nil {
     log.Fatalf("[FATAL] BotTeam start error: %v\n", err)
   }
 
   var app *Service = nil
   if appPort != PortValue_NIL {
-    app = NewService(appPort, botsTeam)
+    if (appOptions.CertFile == "") != (appOptions.KeyFile == "") {
+      log.Fatalf("[FATAL] App TLS requires both -app-cert and -app-key\n")
+    }
+    if appAuthFile != "" {
+      auth, err := LoadServiceAuth(appAuthFile)
+      if err != nil {
+        log.Fatalf("[FATAL] App %v\n", err)
+      }
+      appOptions.Auth = auth
+    }
+    app = NewService(appPort, botsTeam, appOptions)
     if err := app.ListenAndServe(); err != nil {
       log.Fatalf("[FATAL] App Server start error: %v\n", err)
     }
   }
 
+  var oscQuery *OSCQueryServer = nil
+  if oscQueryPort != PortValue_NIL {
+    oscQuery = NewOSCQueryServer(oscQueryPort, PortValue(oscAddr.Port), botsTeam)
+    if err := oscQuery.ListenAndServe(); err != nil {
+      log.Fatalf("[FATAL] OSCQuery Server start error: %v\n", err)
+    }
+  }
+
+  if err := SDNotify("READY=1"); err != nil {
+    log.Printf("[ERROR] %v\n", err)
+  }
+  watchdogDone := make(chan struct{})
+  go SDWatchdog(func() bool {
+    return botsTeam.Alive(Service_Health_Timeout)
+  }, watchdogDone)
+
   sigChan := make(chan os.Signal, 1)
   signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
-  <-sigChan
+  for sig := range sigChan {
+    if sig != syscall.SIGHUP {
+      break
+    }
+    log.Printf("[INFO] SIGHUP received, reloading config %s\n", configFile)
+    SDNotify("RELOADING=1")
+    if _, err := botsTeam.Reload(); err != nil {
+      log.Printf("[ERROR] Config reload error: %v\n", err)
+    }
+    SDNotify("READY=1")
+  }
+
+  SDNotify("STOPPING=1")
+  close(watchdogDone)
 
   oscServer.UnSubscribeAll()
   oscServer.Shutdown()
 
+  if oscQuery != nil {
+    if err := oscQuery.Shutdown(); err != nil {
+      log.Printf("[ERROR] OSCQuery Server stop error: %v\n", err)
+    }
+  }
+
   if err := botsTeam.Shutdown(); err != nil {
     log.Printf("[ERROR] BotTeam stop error: %v\n", err)
   }
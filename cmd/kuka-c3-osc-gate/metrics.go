@@ -0,0 +1,215 @@
+package main
+
+import (
+  "fmt"
+  "io"
+  "math"
+  "sort"
+  "strconv"
+  "strings"
+  "sync"
+  "sync/atomic"
+  "time"
+)
+
+const (
+  Metrics_Namespace = "kuka_c3_gate"
+
+  Metrics_RateWindow = 1 * time.Second
+)
+
+var (
+  Metrics_LatencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+  Metrics_MoveBuckets    = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60}
+)
+
+// MetricHistogram counts observations in cumulative buckets as Prometheus histograms do
+type MetricHistogram struct {
+  buckets []float64
+  counts  []uint64
+  count   uint64
+  sum     float64
+  mux     sync.Mutex
+}
+
+func NewMetricHistogram(buckets []float64) *MetricHistogram {
+  return &MetricHistogram{
+    buckets: buckets,
+    counts:  make([]uint64, len(buckets)),
+  }
+}
+
+func (h *MetricHistogram) Observe(value float64) {
+  h.mux.Lock()
+  defer h.mux.Unlock()
+  for i, bucket := range h.buckets {
+    if value <= bucket {
+      h.counts[i]++
+    }
+  }
+  h.count++
+  h.sum += value
+}
+
+func (h *MetricHistogram) snapshot() ([]uint64, uint64, float64) {
+  h.mux.Lock()
+  defer h.mux.Unlock()
+  return append([]uint64(nil), h.counts...), h.count, h.sum
+}
+
+// MetricRate is the number of events per second over the last complete window.
+// A window which is not completed in time decays the rate down to zero when events stop.
+type MetricRate struct {
+  windowStart time.Time
+  windowCount uint64
+  rate        float64
+  mux         sync.Mutex
+}
+
+func (r *MetricRate) Add() {
+  r.mux.Lock()
+  defer r.mux.Unlock()
+
+  now := time.Now()
+  if r.windowStart.IsZero() == true {
+    r.windowStart = now
+  }
+  r.windowCount++
+  if elapsed := now.Sub(r.windowStart); elapsed >= Metrics_RateWindow {
+    r.rate = float64(r.windowCount) / elapsed.Seconds()
+    r.windowStart = now
+    r.windowCount = 0
+  }
+}
+
+func (r *MetricRate) Value() float64 {
+  r.mux.Lock()
+  defer r.mux.Unlock()
+
+  if r.windowStart.IsZero() == true {
+    return 0
+  }
+  if elapsed := time.Since(r.windowStart); elapsed >= Metrics_RateWindow {
+    return float64(r.windowCount) / elapsed.Seconds()
+  }
+  return r.rate
+}
+
+// BotMetrics are moves and position polling statistics of a bot, C3 statistics are kept by its C3Client
+type BotMetrics struct {
+  moveDuration *MetricHistogram
+  moveSuccess  atomic.Uint64
+  moveBreak    atomic.Uint64
+  moveError    atomic.Uint64
+
+  positionUpdates      atomic.Uint64
+  positionUpdateErrors atomic.Uint64
+  positionRate         MetricRate
+
+  oscDropped atomic.Uint64
+}
+
+func NewBotMetrics() *BotMetrics {
+  return &BotMetrics{moveDuration: NewMetricHistogram(Metrics_MoveBuckets)}
+}
+
+func (m *BotMetrics) move(start time.Time, isBreak bool, err error) {
+  m.moveDuration.Observe(time.Since(start).Seconds())
+  switch {
+    case err == nil:
+      m.moveSuccess.Add(1)
+    case isBreak == true:
+      m.moveBreak.Add(1)
+    default:
+      m.moveError.Add(1)
+  }
+}
+
+func (m *BotMetrics) positionUpdate(err error) {
+  if err != nil {
+    m.positionUpdateErrors.Add(1)
+    return
+  }
+  m.positionUpdates.Add(1)
+  m.positionRate.Add()
+}
+
+// metricsWriter writes the Prometheus text format, or OpenMetrics when isOpenMetrics is set.
+// Samples of a family must follow its header, so families are written one by one for all bots.
+type metricsWriter struct {
+  w             io.Writer
+  isOpenMetrics bool
+}
+
+func (mw *metricsWriter) family(name string, kind string, help string) {
+  if mw.isOpenMetrics == true && kind == "counter" {
+    name = strings.TrimSuffix(name, "_total")
+  }
+  fmt.Fprintf(mw.w, "# HELP %s_%s %s\n", Metrics_Namespace, name, help)
+  fmt.Fprintf(mw.w, "# TYPE %s_%s %s\n", Metrics_Namespace, name, kind)
+}
+
+func (mw *metricsWriter) sample(name string, labels []string, value float64) {
+  fmt.Fprintf(mw.w, "%s_%s%s %s\n", Metrics_Namespace, name, metricsLabels(labels), metricsValue(value))
+}
+
+func (mw *metricsWriter) histogram(name string, labels []string, h *MetricHistogram) {
+  counts, count, sum := h.snapshot()
+  for i, bucket := range h.buckets {
+    mw.sample(name + "_bucket", metricsWith(labels, "le", metricsValue(bucket)), float64(counts[i]))
+  }
+  mw.sample(name + "_bucket", metricsWith(labels, "le", "+Inf"), float64(count))
+  mw.sample(name + "_sum", labels, sum)
+  mw.sample(name + "_count", labels, float64(count))
+}
+
+func (mw *metricsWriter) end() {
+  if mw.isOpenMetrics == true {
+    fmt.Fprint(mw.w, "# EOF\n")
+  }
+}
+
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsLabels formats name, value pairs
+func metricsLabels(labels []string) string {
+  if len(labels) == 0 {
+    return ""
+  }
+  pairs := make([]string, 0, len(labels) / 2)
+  for i := 0; i + 1 < len(labels); i += 2 {
+    pairs = append(pairs, labels[i] + "=\"" + metricsLabelEscaper.Replace(labels[i + 1]) + "\"")
+  }
+  return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// metricsWith returns a copy of labels with an extra pair
+func metricsWith(labels []string, name string, value string) []string {
+  return append(append(make([]string, 0, len(labels) + 2), labels...), name, value)
+}
+
+func metricsValue(value float64) string {
+  switch {
+    case math.IsInf(value, 1):
+      return "+Inf"
+    case math.IsInf(value, -1):
+      return "-Inf"
+    case math.IsNaN(value):
+      return "NaN"
+  }
+  return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// metricsAxes are E6AXIS components exported as joint gauges, in position order
+func metricsAxes() []string {
+  axes := make([]string, 0, 12)
+  for name, target := range OSCMapping_Targets {
+    if target.positionType == PositionType_E6AXIS {
+      axes = append(axes, name)
+    }
+  }
+  sort.Slice(axes, func(i, j int) bool {
+    return OSCMapping_Targets[axes[i]].index < OSCMapping_Targets[axes[j]].index
+  })
+  return axes
+}
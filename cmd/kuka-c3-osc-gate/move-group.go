@@ -1,21 +1,109 @@
 package main
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MoveStep is a MoveGroup position or a reference to an InternalAction by name.
+// A raw step is an E6POS in the robot frame, written as {"raw": position}, other positions are
+// offset-corrected as /coords reports them.
+type MoveStep struct {
+  Position *Position
+  Action   string
+  IsRaw    bool
+}
+
+type moveStepRaw struct {
+  Raw *Position `json:"raw"`
+}
+
+func NewPositionStep(position *Position) *MoveStep {
+  return &MoveStep{Position: position}
+}
+
+func NewRawPositionStep(position *Position) *MoveStep {
+  return &MoveStep{Position: position, IsRaw: true}
+}
+
+func NewActionStep(action string) *MoveStep {
+  return &MoveStep{Action: action}
+}
+
+func (s MoveStep) MarshalJSON() ([]byte, error) {
+  if s.Action != "" {
+    return json.Marshal(s.Action)
+  }
+  if s.IsRaw == true {
+    return json.Marshal(&moveStepRaw{Raw: s.Position})
+  }
+  return json.Marshal(s.Position)
+}
+
+func (s *MoveStep) UnmarshalJSON(input []byte) error {
+  data := bytes.TrimSpace(input)
+  if len(data) > 0 && data[0] == '"' {
+    s.Position = nil
+    s.IsRaw = false
+    return json.Unmarshal(data, &s.Action)
+  }
+
+  s.Action = ""
+  s.IsRaw = len(data) > 0 && data[0] == '{'
+  if s.IsRaw == false {
+    s.Position = NewPosition(PositionType_NIL)
+    return json.Unmarshal(input, s.Position)
+  }
+
+  raw := &moveStepRaw{}
+  if err := json.Unmarshal(data, raw); err != nil {
+    return err
+  }
+  if raw.Raw == nil {
+    return fmt.Errorf("Raw step position is empty")
+  }
+  s.Position = raw.Raw
+  return nil
+}
+
+func (s *MoveStep) IsAction() bool {
+  return s.Action != ""
+}
+
+func (s *MoveStep) Value() string {
+  if s.Action != "" {
+    return fmt.Sprintf("{ACTION: %s}", s.Action)
+  }
+  if s.IsRaw == true {
+    return fmt.Sprintf("{RAW: %s}", s.Position.Value())
+  }
+  return s.Position.Value()
+}
+
+func (s *MoveStep) Clone() *MoveStep {
+  if s.Action != "" {
+    return NewActionStep(s.Action)
+  }
+  return &MoveStep{Position: s.Position.Clone(), IsRaw: s.IsRaw}
+}
+
 type MoveGroup struct {
   Id         uint16      `json:"id"`
-  Positions  []*Position `json:"positions"`
+  Positions  []*MoveStep `json:"positions"`
 }
 
 func NewMoveGroup(id uint16) *MoveGroup {
   return &MoveGroup{
     Id: id,
-    Positions: make([]*Position, 0),
+    Positions: make([]*MoveStep, 0),
   }
 }
 
 func (mg *MoveGroup) Clone() *MoveGroup {
   newMG := &MoveGroup{
     Id: mg.Id,
-    Positions: make([]*Position, len(mg.Positions)),
+    Positions: make([]*MoveStep, len(mg.Positions)),
   }
 
   for i, position := range mg.Positions {
@@ -23,4 +111,4 @@ func (mg *MoveGroup) Clone() *MoveGroup {
   }
 
   return newMG
-}
\ No newline at end of file
+}
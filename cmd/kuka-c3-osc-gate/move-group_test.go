@@ -0,0 +1,50 @@
+package main
+
+import (
+  "encoding/json"
+  "testing"
+)
+
+func TestMoveStepJSON(t *testing.T) {
+  tests := []struct {
+    name    string
+    input   string
+    action  string
+    isRaw   bool
+    wantErr bool
+  }{
+    {"action", `"action100"`, "action100", false, false},
+    {"position", `[2, 1, 2, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0]`, "", false, false},
+    {"raw position", `{"raw": [2, 1, 2, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0]}`, "", true, false},
+    {"raw without position", `{"pos": [2, 1, 2, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0]}`, "", true, true},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      step := &MoveStep{}
+      err := json.Unmarshal([]byte(test.input), step)
+      if test.wantErr == true {
+        if err == nil {
+          t.Errorf("Unmarshal() = %s, want error", step.Value())
+        }
+        return
+      }
+      if err != nil {
+        t.Fatalf("Unmarshal() error: %v", err)
+      }
+      if step.Action != test.action || step.IsRaw != test.isRaw {
+        t.Errorf("Unmarshal() = %s raw %v, want action %q raw %v", step.Value(), step.IsRaw, test.action, test.isRaw)
+      }
+
+      data, err := json.Marshal(step)
+      if err != nil {
+        t.Fatalf("Marshal() error: %v", err)
+      }
+      var want any
+      json.Unmarshal([]byte(test.input), &want)
+      if wantData, _ := json.Marshal(want); string(wantData) != string(data) {
+        t.Errorf("Marshal() = %s, want %s", data, wantData)
+      }
+    })
+  }
+}
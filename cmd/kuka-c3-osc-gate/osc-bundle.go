@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+  OSCBundle_Tag = "#bundle"
+
+  // Seconds between NTP epoch 1900-01-01 and Unix epoch 1970-01-01
+  OSCTimetag_UnixOffset = 2208988800
+  OSCTimetag_Immediate OSCTimetag = 1
+)
+
+// OSCElement is an OSC message (*OSCPacket) or an OSC bundle (*OSCBundle)
+type OSCElement interface {
+  Bytes() ([]byte, error)
+}
+
+// OSCTimetag is a 64-bit NTP timestamp: 32 bits of seconds since 1900 and 32 bits of fraction
+type OSCTimetag uint64
+
+func NewOSCTimetag(t time.Time) OSCTimetag {
+  seconds := uint64(t.Unix() + OSCTimetag_UnixOffset)
+  fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+  return OSCTimetag(seconds << 32 | fraction)
+}
+
+func (tt OSCTimetag) IsImmediate() bool {
+  return tt == OSCTimetag_Immediate
+}
+
+func (tt OSCTimetag) Time() time.Time {
+  if tt.IsImmediate() {
+    return time.Now()
+  }
+  seconds := int64(tt >> 32) - OSCTimetag_UnixOffset
+  nanoseconds := int64((uint64(tt) & 0xFFFFFFFF) * uint64(time.Second) >> 32)
+  return time.Unix(seconds, nanoseconds)
+}
+
+// Delay returns time left until the timetag, zero for immediate and past timetags
+func (tt OSCTimetag) Delay() time.Duration {
+  if tt.IsImmediate() {
+    return 0
+  }
+  if delay := time.Until(tt.Time()); delay > 0 {
+    return delay
+  }
+  return 0
+}
+
+type OSCBundle struct {
+  Timetag  OSCTimetag
+  Elements []OSCElement
+}
+
+func NewOSCBundle(timetag OSCTimetag) *OSCBundle {
+  return &OSCBundle{
+    Timetag: timetag,
+    Elements: make([]OSCElement, 0),
+  }
+}
+
+func (b *OSCBundle) Append(element OSCElement) error {
+  switch t := element.(type) {
+    case *OSCPacket, *OSCBundle:
+      b.Elements = append(b.Elements, element)
+    default:
+      return fmt.Errorf("Unsupported bundle element: %T", t)
+  }
+  return nil
+}
+
+func (b *OSCBundle) Parse(data []byte) error {
+  if oscIsBundle(data) == false {
+    return fmt.Errorf("Bundle does not start with %s", OSCBundle_Tag)
+  }
+  if len(data) < 16 {
+    return fmt.Errorf("Bundle of %d bytes is too short", len(data))
+  }
+
+  timetag := OSCTimetag(binary.BigEndian.Uint64(data[8:16]))
+  var elements []OSCElement
+
+  for start := 16; start < len(data); {
+    if len(data) - start < 4 {
+      return fmt.Errorf("Bundle element size at %d is truncated", start)
+    }
+    size := int(binary.BigEndian.Uint32(data[start:start + 4]))
+    start += 4
+
+    if size <= 0 || size % 4 != 0 || start + size > len(data) {
+      return fmt.Errorf("Bundle element size %d at %d is incorrect", size, start)
+    }
+
+    element, err := ParseOSCElement(data[start:start + size])
+    if err != nil {
+      return err
+    }
+
+    // Nested bundle must not be scheduled before its enclosing bundle
+    if nested, ok := element.(*OSCBundle); ok && timetag.IsImmediate() == false &&
+      (nested.Timetag.IsImmediate() || nested.Timetag < timetag) {
+      nested.Timetag = timetag
+    }
+
+    elements = append(elements, element)
+    start += size
+  }
+
+  b.Timetag = timetag
+  b.Elements = elements
+  return nil
+}
+
+func (b *OSCBundle) Bytes() ([]byte, error) {
+  data := new(bytes.Buffer)
+
+  if _, err := oscWritePaddedString(OSCBundle_Tag, data); err != nil {
+    return nil, err
+  }
+  if err := binary.Write(data, binary.BigEndian, uint64(b.Timetag)); err != nil {
+    return nil, err
+  }
+
+  for _, element := range b.Elements {
+    elementData, err := element.Bytes()
+    if err != nil {
+      return nil, err
+    }
+    if err := binary.Write(data, binary.BigEndian, int32(len(elementData))); err != nil {
+      return nil, err
+    }
+    if _, err := data.Write(elementData); err != nil {
+      return nil, err
+    }
+  }
+
+  return data.Bytes(), nil
+}
+
+// ParseOSCElement parses a datagram as an OSC message or a (recursive) OSC bundle
+func ParseOSCElement(data []byte) (OSCElement, error) {
+  if oscIsBundle(data) {
+    bundle := NewOSCBundle(OSCTimetag_Immediate)
+    if err := bundle.Parse(data); err != nil {
+      return nil, err
+    }
+    return bundle, nil
+  }
+
+  oscPacket := NewOSCPacket()
+  if err := oscPacket.Parse(data); err != nil {
+    return nil, err
+  }
+  return oscPacket, nil
+}
+
+func oscIsBundle(data []byte) bool {
+  return len(data) >= 8 && string(data[:8]) == OSCBundle_Tag + "\x00"
+}
+
+// oscSetSource marks every message of a received element with the sender address
+func oscSetSource(element OSCElement, source net.Addr) {
+  switch t := element.(type) {
+    case *OSCPacket:
+      t.Source = source
+    case *OSCBundle:
+      for _, nested := range t.Elements {
+        oscSetSource(nested, source)
+      }
+  }
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+  "encoding/binary"
+  "net"
+  "strings"
+  "testing"
+  "time"
+)
+
+func testOSCMessage(t *testing.T, path string, values ...any) *OSCPacket {
+  t.Helper()
+  oscPacket := NewOSCPacket()
+  oscPacket.Path = path
+  for _, value := range values {
+    if err := oscPacket.Append(value); err != nil {
+      t.Fatalf("Append(%v) error: %v", value, err)
+    }
+  }
+  return oscPacket
+}
+
+func testOSCBytes(t *testing.T, element OSCElement) []byte {
+  t.Helper()
+  data, err := element.Bytes()
+  if err != nil {
+    t.Fatalf("Bytes() error: %v", err)
+  }
+  return data
+}
+
+func TestOSCTimetag(t *testing.T) {
+  tests := []struct {
+    name    string
+    timetag OSCTimetag
+    want    time.Time
+  }{
+    {"unix epoch", OSCTimetag(uint64(OSCTimetag_UnixOffset) << 32), time.Unix(0, 0)},
+    {"half second", OSCTimetag(uint64(OSCTimetag_UnixOffset + 1) << 32 | 1 << 31), time.Unix(1, 500000000)},
+    {"from time", NewOSCTimetag(time.Unix(1700000000, 250000000)), time.Unix(1700000000, 250000000)},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      // The 32-bit fraction resolution is about 233 picoseconds, a nanosecond is lost on round trips
+      if diff := test.timetag.Time().Sub(test.want); diff < -time.Nanosecond || diff > time.Nanosecond {
+        t.Errorf("Time() = %v, want %v", test.timetag.Time(), test.want)
+      }
+    })
+  }
+}
+
+func TestOSCTimetagDelay(t *testing.T) {
+  tests := []struct {
+    name     string
+    timetag  OSCTimetag
+    min, max time.Duration
+  }{
+    {"immediate", OSCTimetag_Immediate, 0, 0},
+    {"past", NewOSCTimetag(time.Now().Add(-time.Second)), 0, 0},
+    {"future", NewOSCTimetag(time.Now().Add(time.Second)), 900 * time.Millisecond, time.Second},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      if delay := test.timetag.Delay(); delay < test.min || delay > test.max {
+        t.Errorf("Delay() = %v, want %v..%v", delay, test.min, test.max)
+      }
+    })
+  }
+}
+
+func TestOSCBundleRoundTrip(t *testing.T) {
+  timetag := NewOSCTimetag(time.Unix(1700000000, 0))
+  nested := NewOSCBundle(timetag + 1 << 32)
+  nested.Append(testOSCMessage(t, "/bot/1/move", int32(2)))
+
+  bundle := NewOSCBundle(timetag)
+  bundle.Append(testOSCMessage(t, "/bot/0/move", int32(1), "home"))
+  bundle.Append(nested)
+
+  element, err := ParseOSCElement(testOSCBytes(t, bundle))
+  if err != nil {
+    t.Fatalf("ParseOSCElement() error: %v", err)
+  }
+  parsed, ok := element.(*OSCBundle)
+  if ok == false {
+    t.Fatalf("ParseOSCElement() = %T, want *OSCBundle", element)
+  }
+  if parsed.Timetag != timetag {
+    t.Errorf("Timetag = %d, want %d", parsed.Timetag, timetag)
+  }
+  if len(parsed.Elements) != 2 {
+    t.Fatalf("Elements length = %d, want 2", len(parsed.Elements))
+  }
+
+  message, ok := parsed.Elements[0].(*OSCPacket)
+  if ok == false || message.Path != "/bot/0/move" || len(message.Values()) != 2 {
+    t.Errorf("Elements[0] = %+v, want /bot/0/move with 2 values", parsed.Elements[0])
+  }
+  parsedNested, ok := parsed.Elements[1].(*OSCBundle)
+  if ok == false || parsedNested.Timetag != nested.Timetag || len(parsedNested.Elements) != 1 {
+    t.Errorf("Elements[1] = %+v, want nested bundle at %d", parsed.Elements[1], nested.Timetag)
+  }
+}
+
+func TestOSCBundleNestedTimetag(t *testing.T) {
+  timetag := NewOSCTimetag(time.Unix(1700000000, 0))
+  tests := []struct {
+    name   string
+    outer  OSCTimetag
+    nested OSCTimetag
+    want   OSCTimetag
+  }{
+    {"nested later is kept", timetag, timetag + 1 << 32, timetag + 1 << 32},
+    {"nested earlier is delayed", timetag, timetag - 1 << 32, timetag},
+    {"nested immediate is delayed", timetag, OSCTimetag_Immediate, timetag},
+    {"outer immediate keeps nested", OSCTimetag_Immediate, timetag, timetag},
+    {"both immediate", OSCTimetag_Immediate, OSCTimetag_Immediate, OSCTimetag_Immediate},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      nested := NewOSCBundle(test.nested)
+      nested.Append(testOSCMessage(t, "/bot/0/move", int32(1)))
+      bundle := NewOSCBundle(test.outer)
+      bundle.Append(nested)
+
+      parsed := NewOSCBundle(OSCTimetag_Immediate)
+      if err := parsed.Parse(testOSCBytes(t, bundle)); err != nil {
+        t.Fatalf("Parse() error: %v", err)
+      }
+      if got := parsed.Elements[0].(*OSCBundle).Timetag; got != test.want {
+        t.Errorf("nested Timetag = %d, want %d", got, test.want)
+      }
+    })
+  }
+}
+
+func TestOSCBundleParseErrors(t *testing.T) {
+  header := testOSCBytes(t, NewOSCBundle(OSCTimetag_Immediate))
+  message := testOSCBytes(t, testOSCMessage(t, "/bot/0/move", int32(1)))
+
+  withElement := func(size uint32, element []byte) []byte {
+    data := append([]byte(nil), header...)
+    data = binary.BigEndian.AppendUint32(data, size)
+    return append(data, element...)
+  }
+
+  tests := []struct {
+    name    string
+    data    []byte
+    wantErr string
+  }{
+    {"not a bundle", message, "does not start with"},
+    {"short header", header[:12], "too short"},
+    {"truncated size", append(append([]byte(nil), header...), 0x00, 0x00), "is truncated"},
+    {"zero size", withElement(0, nil), "is incorrect"},
+    {"unaligned size", withElement(uint32(len(message) - 1), message[:len(message) - 1]), "is incorrect"},
+    {"size over data", withElement(uint32(len(message) + 4), message), "is incorrect"},
+    {"incorrect nested bundle", withElement(8, header[:8]), "too short"},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      err := NewOSCBundle(OSCTimetag_Immediate).Parse(test.data)
+      if err == nil {
+        t.Fatalf("Parse() error = nil, want %q", test.wantErr)
+      }
+      if strings.Contains(err.Error(), test.wantErr) == false {
+        t.Errorf("Parse() error = %v, want %q", err, test.wantErr)
+      }
+    })
+  }
+}
+
+func TestOSCServerScheduleLimits(t *testing.T) {
+  tests := []struct {
+    name      string
+    scheduled int
+    delay     time.Duration
+    want      int
+  }{
+    {"scheduled", 0, time.Hour, 1},
+    {"over max delay", 0, 2 * time.Hour, 0},
+    {"timers are full", OSCServer_MaxScheduled, time.Hour, OSCServer_MaxScheduled},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      osc := NewOSCServer(net.UDPAddr{})
+      osc.SetBundleMaxDelay(time.Hour)
+      defer osc.cancelScheduled()
+
+      for i := 0; i < test.scheduled; i++ {
+        osc.schedule(time.Hour, func() {})
+      }
+      osc.schedule(test.delay, func() {})
+
+      if got := len(osc.timers); got != test.want {
+        t.Errorf("timers length = %d, want %d", got, test.want)
+      }
+    })
+  }
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,27 +16,44 @@ const (
 )
 
 type OSCClient struct {
-  addr *net.UDPAddr
+  addr *OSCAddress
   
-  conn        *net.UDPConn
+  conn        net.Conn
   connMux     sync.Mutex
   isConnected bool
 
-  packets chan []byte
+  packets  chan []byte
+  doneChan chan struct{}
 
+  // Packets are dropped while the queue is full, so an unreachable destination never blocks senders
+  dropped    atomic.Uint64
+  isDropping atomic.Bool
+
+  // sendMux orders Send with Shutdown, a packet is never sent into the closed queue
+  sendMux    sync.RWMutex
   isShutdown bool
   wg sync.WaitGroup
 }
 
+// NewOSCClient sends to "host:port" over UDP, see ParseOSCAddress for TCP addresses
 func NewOSCClient(address string) (*OSCClient, error) {
-  addr, err := net.ResolveUDPAddr("udp", address)
+  addr, err := ParseOSCAddress(address)
   if err != nil {
-    return nil, fmt.Errorf("OSCClient client failed to resolve UDP address: %w", err)
+    return nil, fmt.Errorf("OSCClient address error: %w", err)
+  }
+
+  if addr.Network == "udp" {
+    if _, err := net.ResolveUDPAddr("udp", addr.Host); err != nil {
+      return nil, fmt.Errorf("OSCClient client failed to resolve UDP address: %w", err)
+    }
+  } else if _, _, err := net.SplitHostPort(addr.Host); err != nil {
+    return nil, fmt.Errorf("OSCClient client incorrect TCP address: %w", err)
   }
 
   osc := &OSCClient{
     addr: addr,
     packets: make(chan []byte, OSCClient_PacketsBuffer),
+    doneChan: make(chan struct{}),
   }
   
   osc.wg.Add(1)
@@ -52,17 +70,27 @@ func (osc *OSCClient) connect() error {
       return nil
     }
 
+    select {
+      case <-osc.doneChan:
+        osc.connMux.Unlock()
+        return fmt.Errorf("OSCClient is shutdown")
+      default:
+    }
+
     var err error
-    if osc.conn, err = net.DialUDP("udp", nil, osc.addr); err != nil {
+    if osc.conn, err = net.DialTimeout(osc.addr.Network, osc.addr.Host, OSCClient_RetryTimeout); err != nil {
       osc.connMux.Unlock()
-      log.Printf("[OSCClient ERROR] Failed to reconnect: %v. Retrying in %.6f seconds...\n", err, OSCClient_RetryTimeout.Seconds())
-      time.Sleep(OSCClient_RetryTimeout)
+      log.Printf("[OSCClient ERROR] Failed to reconnect %s: %v. Retrying in %.6f seconds...\n", osc.addr.String(), err, OSCClient_RetryTimeout.Seconds())
+      select {
+        case <-osc.doneChan:
+        case <-time.After(OSCClient_RetryTimeout):
+      }
       continue
     }
 
     osc.isConnected = true
     osc.connMux.Unlock()
-    log.Printf("[OSCClient INFO] Connected successfully.")
+    log.Printf("[OSCClient INFO] Connected successfully to %s.", osc.addr.String())
   }
 }
 
@@ -75,6 +103,10 @@ func (osc *OSCClient) processPackets() {
     }
 
     osc.connMux.Lock()
+    if osc.addr.Network == "tcp" {
+      packet = oscEncodeFrame(osc.addr.Framing, packet)
+      osc.conn.SetWriteDeadline(time.Now().Add(OSCClient_RetryTimeout))
+    }
     if _, err := osc.conn.Write(packet); err != nil {
       log.Printf("[OSCClient ERROR] Failed to send data: %v\n", err)
       osc.conn.Close()
@@ -82,84 +114,142 @@ func (osc *OSCClient) processPackets() {
     }
     osc.connMux.Unlock()
   }
+
+  osc.connMux.Lock()
+  if osc.isConnected {
+    osc.conn.Close()
+    osc.isConnected = false
+  }
+  osc.connMux.Unlock()
 }
 
-func (osc *OSCClient) Send(packet *OSCPacket) error {
+// Send queues an OSC message or an OSC bundle, it never blocks: a packet is dropped when the queue is full
+func (osc *OSCClient) Send(packet OSCElement) error {
   data, err := packet.Bytes()
   if err != nil {
     return fmt.Errorf("OSCClient packet bytes error: %w", err)
   }
-  osc.packets <- data
+
+  osc.sendMux.RLock()
+  defer osc.sendMux.RUnlock()
+
+  if osc.isShutdown == true {
+    return fmt.Errorf("OSCClient %s is shutdown", osc.addr.String())
+  }
+
+  select {
+    case osc.packets <- data:
+      if osc.isDropping.Swap(false) == true {
+        log.Printf("[OSCClient INFO] %s queue is sending again, %d packets dropped in total\n", osc.addr.String(), osc.dropped.Load())
+      }
+    default:
+      osc.dropped.Add(1)
+      if osc.isDropping.Swap(true) == false {
+        log.Printf("[OSCClient WARNING] %s queue is full, packets are dropped\n", osc.addr.String())
+      }
+  }
   return nil
 }
 
 func (osc *OSCClient) Shutdown() {
+  osc.sendMux.Lock()
+  if osc.isShutdown == true {
+    osc.sendMux.Unlock()
+    return
+  }
   osc.isShutdown = true
+  close(osc.doneChan)
   close(osc.packets)
+  osc.sendMux.Unlock()
+
   osc.wg.Wait()
   log.Printf("[OSCClient INFO] Client shutdown successfully\n")
 }
 
 func (osc *OSCClient) ResponseAxis(path string, position *Position) error {
+  oscPacker, err := NewOSCResponseAxis(path, position)
+  if err != nil {
+    return err
+  }
+  return osc.Send(oscPacker)
+}
+
+func NewOSCResponseAxis(path string, position *Position) (*OSCPacket, error) {
   oscPacker := NewOSCPacket()
   oscPacker.Path = path
   if err := oscPacker.Append(position.A1(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.A2(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.A3(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.A4(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.A5(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.A6(nil)); err != nil {
+    return nil, err
+  }
+  return oscPacker, nil
+}
+
+func (osc *OSCClient) ResponseCoords(path string, position *Position) error {
+  oscPacker, err := NewOSCResponseCoords(path, position)
+  if err != nil {
     return err
   }
   return osc.Send(oscPacker)
 }
 
-func (osc *OSCClient) ResponseCoords(path string, position *Position) error {
+func NewOSCResponseCoords(path string, position *Position) (*OSCPacket, error) {
   oscPacker := NewOSCPacket()
   oscPacker.Path = path
   
   if err := oscPacker.Append(position.X(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.Y(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.Z(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.A(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.B(nil)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(position.C(nil)); err != nil {
+    return nil, err
+  }
+  return oscPacker, nil
+}
+
+func (osc *OSCClient) ResponsePosition(path string, status OSCOutputStatus, index int32, positionId uint16) error {
+  oscPacker, err := NewOSCResponsePosition(path, status, index, positionId)
+  if err != nil {
     return err
   }
   return osc.Send(oscPacker)
 }
 
-func (osc *OSCClient) ResponsePosition(path string, status OSCOutputStatus, index int32, positionId uint16) error {
+func NewOSCResponsePosition(path string, status OSCOutputStatus, index int32, positionId uint16) (*OSCPacket, error) {
   oscPacker := NewOSCPacket()
   oscPacker.Path = path
   if err := oscPacker.Append(int32(status)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(int32(index)); err != nil {
-    return err
+    return nil, err
   }
   if err := oscPacker.Append(int32(positionId)); err != nil {
-    return err
+    return nil, err
   }
-  return osc.Send(oscPacker)
-}
\ No newline at end of file
+  return oscPacker, nil
+}
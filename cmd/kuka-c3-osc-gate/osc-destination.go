@@ -0,0 +1,131 @@
+package main
+
+import (
+  "fmt"
+  "log"
+  "net"
+  "sync"
+)
+
+// OSCDestination is an extra telemetry receiver: unicast, broadcast or multicast group address.
+// Paths remaps gate paths to receiver paths, unmapped paths are sent as is, an empty mapped path drops the packet.
+// Telemetry payloads are sent with their own paths, rate caps and deadbands.
+type OSCDestination struct {
+  Address   string            `json:"address"`
+  Paths     map[string]string `json:"paths"`
+  Telemetry []*Telemetry      `json:"telemetry"`
+
+  // client is replaced by Up and Shutdown while bots and the team send to the destination
+  client    *OSCClient
+  clientMux sync.RWMutex
+}
+
+func (d *OSCDestination) Up() (err error) {
+  if err := telemetryCheck(d.Telemetry); err != nil {
+    return fmt.Errorf("OSCDestination %s error: %w", d.Address, err)
+  }
+
+  client, err := NewOSCClient(d.Address)
+  if err != nil {
+    return fmt.Errorf("OSCDestination %s error: %w", d.Address, err)
+  }
+  d.clientMux.Lock()
+  d.client = client
+  d.clientMux.Unlock()
+
+  if addr, err := ParseOSCAddress(d.Address); err == nil && addr.Network == "udp" {
+    if udpAddr, err := net.ResolveUDPAddr("udp", addr.Host); err == nil {
+      switch {
+        case udpAddr.IP.IsMulticast():
+          log.Printf("[OSCDestination INFO] %s is a multicast group\n", d.Address)
+        case udpAddr.IP.Equal(net.IPv4bcast):
+          log.Printf("[OSCDestination INFO] %s is a broadcast address\n", d.Address)
+      }
+    }
+  }
+  return nil
+}
+
+func (d *OSCDestination) Shutdown() {
+  d.clientMux.Lock()
+  client := d.client
+  d.client = nil
+  d.clientMux.Unlock()
+  if client != nil {
+    client.Shutdown()
+  }
+}
+
+func (d *OSCDestination) getClient() *OSCClient {
+  d.clientMux.RLock()
+  defer d.clientMux.RUnlock()
+  return d.client
+}
+
+func (d *OSCDestination) Path(path string) string {
+  if mapped, ok := d.Paths[path]; ok {
+    return mapped
+  }
+  return path
+}
+
+func (d *OSCDestination) Send(oscPacket *OSCPacket) error {
+  client := d.getClient()
+  if client == nil {
+    return fmt.Errorf("OSCDestination %s is not started", d.Address)
+  }
+
+  path := d.Path(oscPacket.Path)
+  if path == "" {
+    return nil
+  }
+
+  remapped := &OSCPacket{Path: path, values: oscPacket.values}
+  return client.Send(remapped)
+}
+
+func (d *OSCDestination) SendTelemetry(bot string, sample *TelemetrySample) error {
+  client := d.getClient()
+  if client == nil {
+    return fmt.Errorf("OSCDestination %s is not started", d.Address)
+  }
+  return telemetrySend(d.Telemetry, bot, sample, func(oscPacket *OSCPacket) error {
+    return client.Send(oscPacket)
+  })
+}
+
+func oscDestinationsUp(destinations []*OSCDestination) error {
+  for i, destination := range destinations {
+    if err := destination.Up(); err != nil {
+      oscDestinationsShutdown(destinations[:i])
+      return err
+    }
+  }
+  return nil
+}
+
+func oscDestinationsShutdown(destinations []*OSCDestination) {
+  for _, destination := range destinations {
+    destination.Shutdown()
+  }
+}
+
+func oscDestinationsSendTelemetry(destinations []*OSCDestination, bot string, sample *TelemetrySample) error {
+  var result error
+  for _, destination := range destinations {
+    if err := destination.SendTelemetry(bot, sample); err != nil && result == nil {
+      result = err
+    }
+  }
+  return result
+}
+
+func oscDestinationsSend(destinations []*OSCDestination, oscPacket *OSCPacket) error {
+  var result error
+  for _, destination := range destinations {
+    if err := destination.Send(oscPacket); err != nil && result == nil {
+      result = err
+    }
+  }
+  return result
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+  "strings"
+)
+
+const (
+  OSCPattern_Chars = "*?[]{}"
+)
+
+type OSCHandler func(oscPacket *OSCPacket)
+
+// OSCMethod is a literal OSC address served by a handler, motion methods move robots and require an armed gate
+type OSCMethod struct {
+  Address  string
+  Handler  OSCHandler
+  IsMotion bool
+}
+
+func NewOSCMethod(address string, handler OSCHandler) *OSCMethod {
+  return &OSCMethod{
+    Address: address,
+    Handler: handler,
+  }
+}
+
+func NewOSCMotionMethod(address string, handler OSCHandler) *OSCMethod {
+  return &OSCMethod{
+    Address:  address,
+    Handler:  handler,
+    IsMotion: true,
+  }
+}
+
+// OSCListener registers its methods on Subscribe, packets are delivered to matched methods only
+type OSCListener interface {
+  OSCMethods() []*OSCMethod
+}
+
+// oscCall is a matched packet queued for the listener's own goroutine
+type oscCall struct {
+  handler OSCHandler
+  packet  *OSCPacket
+}
+
+// OSCDispatcher matches OSC address patterns against registered method addresses
+type OSCDispatcher struct {
+  listeners []OSCListener
+  methods   map[OSCListener][]*OSCMethod
+  addresses map[string][]*OSCMethod
+}
+
+func NewOSCDispatcher() *OSCDispatcher {
+  return &OSCDispatcher{
+    listeners: make([]OSCListener, 0),
+    methods:   make(map[OSCListener][]*OSCMethod),
+    addresses: make(map[string][]*OSCMethod),
+  }
+}
+
+func (d *OSCDispatcher) Add(listener OSCListener) {
+  d.Remove(listener)
+  d.listeners = append(d.listeners, listener)
+  d.methods[listener] = listener.OSCMethods()
+  d.index()
+}
+
+func (d *OSCDispatcher) Remove(listener OSCListener) {
+  for i, other := range d.listeners {
+    if other == listener {
+      d.listeners = append(d.listeners[:i], d.listeners[i+1:]...)
+      delete(d.methods, listener)
+      d.index()
+      return
+    }
+  }
+}
+
+func (d *OSCDispatcher) index() {
+  d.addresses = make(map[string][]*OSCMethod)
+  for _, listener := range d.listeners {
+    for _, method := range d.methods[listener] {
+      d.addresses[method.Address] = append(d.addresses[method.Address], method)
+    }
+  }
+}
+
+// Match returns methods addressed by path, a path without pattern chars is a plain lookup
+func (d *OSCDispatcher) Match(path string) []*OSCMethod {
+  if strings.ContainsAny(path, OSCPattern_Chars) == false {
+    return d.addresses[path]
+  }
+
+  var methods []*OSCMethod
+  for _, listener := range d.listeners {
+    for _, method := range d.methods[listener] {
+      if OSCMatch(path, method.Address) {
+        methods = append(methods, method)
+      }
+    }
+  }
+  return methods
+}
+
+// OSCMatch reports whether an OSC address pattern matches a literal address.
+// Pattern chars are matched within one address part: '*', '?', '[a-z]', '[!a-z]' and '{Left,Right}'.
+func OSCMatch(pattern string, address string) bool {
+  patternParts := strings.Split(pattern, "/")
+  addressParts := strings.Split(address, "/")
+  if len(patternParts) != len(addressParts) {
+    return false
+  }
+
+  for i, part := range patternParts {
+    if oscMatchPart(part, addressParts[i]) == false {
+      return false
+    }
+  }
+  return true
+}
+
+func oscMatchPart(pattern string, str string) bool {
+  for len(pattern) > 0 {
+    switch pattern[0] {
+      case '*':
+        for len(pattern) > 0 && pattern[0] == '*' {
+          pattern = pattern[1:]
+        }
+        if len(pattern) == 0 {
+          return true
+        }
+        for i := 0; i <= len(str); i++ {
+          if oscMatchPart(pattern, str[i:]) {
+            return true
+          }
+        }
+        return false
+
+      case '?':
+        if len(str) == 0 {
+          return false
+        }
+        pattern, str = pattern[1:], str[1:]
+
+      case '[':
+        end := strings.IndexByte(pattern, ']')
+        if end < 0 || len(str) == 0 {
+          return false
+        }
+        if oscMatchSet(pattern[1:end], str[0]) == false {
+          return false
+        }
+        pattern, str = pattern[end + 1:], str[1:]
+
+      case '{':
+        end := strings.IndexByte(pattern, '}')
+        if end < 0 {
+          return false
+        }
+        rest := pattern[end + 1:]
+        for _, alternative := range strings.Split(pattern[1:end], ",") {
+          if strings.HasPrefix(str, alternative) && oscMatchPart(rest, str[len(alternative):]) {
+            return true
+          }
+        }
+        return false
+
+      default:
+        if len(str) == 0 || pattern[0] != str[0] {
+          return false
+        }
+        pattern, str = pattern[1:], str[1:]
+    }
+  }
+  return len(str) == 0
+}
+
+func oscMatchSet(set string, char byte) bool {
+  var negate bool = false
+  if len(set) > 0 && set[0] == '!' {
+    negate = true
+    set = set[1:]
+  }
+
+  var matched bool = false
+  for i := 0; i < len(set); i++ {
+    if i + 2 < len(set) && set[i + 1] == '-' {
+      low, high := set[i], set[i + 2]
+      if low > high {
+        low, high = high, low
+      }
+      matched = matched || (char >= low && char <= high)
+      i += 2
+      continue
+    }
+    matched = matched || set[i] == char
+  }
+
+  return matched != negate
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+  "testing"
+)
+
+func TestOSCMatch(t *testing.T) {
+  tests := []struct {
+    pattern string
+    address string
+    want    bool
+  }{
+    // Literal
+    {"/bot/0/move", "/bot/0/move", true},
+    {"/bot/0/move", "/bot/0/mov", false},
+    {"/bot/0/move", "/bot/0/move/1", false},
+    {"/bot/0", "/bot/0/move", false},
+    {"/", "/", true},
+
+    // '*' stays within one part
+    {"/bot/*/move", "/bot/0/move", true},
+    {"/bot/*/move", "/bot/12/move", true},
+    {"/bot/*", "/bot/0/move", false},
+    {"/bot/*", "/bot/", true},
+    {"/bot/0/mo*", "/bot/0/move", true},
+    {"/bot/0/*ve", "/bot/0/move", true},
+    {"/bot/0/m**e", "/bot/0/move", true},
+    {"/bot/0/*x*", "/bot/0/move", false},
+
+    // '?' is exactly one char
+    {"/bot/?/move", "/bot/0/move", true},
+    {"/bot/?/move", "/bot/10/move", false},
+    {"/bot/?/move", "/bot//move", false},
+
+    // Char sets and ranges
+    {"/bot/[01]/move", "/bot/1/move", true},
+    {"/bot/[01]/move", "/bot/2/move", false},
+    {"/bot/[0-3]/move", "/bot/2/move", true},
+    {"/bot/[3-0]/move", "/bot/2/move", true},
+    {"/bot/[0-3]/move", "/bot/4/move", false},
+    {"/bot/[!0-3]/move", "/bot/4/move", true},
+    {"/bot/[!0-3]/move", "/bot/2/move", false},
+    {"/bot/[a-]/move", "/bot/-/move", true},
+    {"/bot/[0-1/move", "/bot/0/move", false},
+
+    // Alternatives
+    {"/bot/0/{move,home}", "/bot/0/home", true},
+    {"/bot/0/{move,home}", "/bot/0/stop", false},
+    {"/bot/0/{mo,move}", "/bot/0/move", true},
+    {"/bot/0/{m,h}o*", "/bot/0/home", true},
+    {"/bot/0/{move,home", "/bot/0/move", false},
+
+    // Combined
+    {"/bot/[0-9]/{move,home}*", "/bot/7/movegroup", true},
+    {"/*/?/*", "/bot/0/move", true},
+  }
+
+  for _, test := range tests {
+    t.Run(test.pattern + " " + test.address, func(t *testing.T) {
+      if got := OSCMatch(test.pattern, test.address); got != test.want {
+        t.Errorf("OSCMatch(%q, %q) = %v, want %v", test.pattern, test.address, got, test.want)
+      }
+    })
+  }
+}
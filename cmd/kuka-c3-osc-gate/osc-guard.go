@@ -0,0 +1,541 @@
+package main
+
+import (
+  "crypto/hmac"
+  "crypto/sha256"
+  "crypto/subtle"
+  "encoding/hex"
+  "fmt"
+  "log"
+  "net"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  OSCGuard_LogInterval = 10 * time.Second
+  OSCGuard_HMACWindow = 5 * time.Second
+)
+
+type OSCGuardReason string
+
+const (
+  OSCGuardReason_Source   OSCGuardReason = "source"   // Source address is not allowed for the path
+  OSCGuardReason_Secret   OSCGuardReason = "secret"   // Shared secret argument is missing or wrong
+  OSCGuardReason_HMAC     OSCGuardReason = "hmac"     // HMAC argument is missing or wrong
+  OSCGuardReason_Replay   OSCGuardReason = "replay"   // HMAC timetag is out of the window or the message is repeated
+  OSCGuardReason_Disarmed OSCGuardReason = "disarmed" // Motion command while the gate is disarmed
+)
+
+var OSCGuard_Reasons = []OSCGuardReason{
+  OSCGuardReason_Source, OSCGuardReason_Secret, OSCGuardReason_HMAC, OSCGuardReason_Replay, OSCGuardReason_Disarmed,
+}
+
+// OSCGuardRule allows sources to send to OSC addresses under path, "/" is every address
+type OSCGuardRule struct {
+  Path    string   `json:"path"`
+  Sources []string `json:"sources"` // CIDRs or single IPs
+
+  nets []*net.IPNet
+}
+
+// OSCGuard filters OSC input before handlers run:
+// - allow rules restrict sources per path, the rule with the longest path wins and an address without rule is denied;
+// - secret must be the first argument of every message, it is removed before handlers;
+// - hmacKey requires the last two arguments to be the send time as an OSC timetag and HMAC-SHA256
+//   of the message encoded without the HMAC, as a blob or hex string. A timetag farther than hmacWindow seconds
+//   from the gate clock or a message already accepted within the window is a replay;
+// - requireArmed rejects motion commands until the gate is armed with oscArmPath 1 or the HTTP API.
+// Senders rejected by source, secret or HMAC get no reply.
+type OSCGuard struct {
+  Allow        []*OSCGuardRule `json:"allow"`
+  Secret       *string         `json:"secret"`
+  HMACKey      *string         `json:"hmacKey"`
+  HMACWindow   *float64        `json:"hmacWindow"`
+  RequireArmed *bool           `json:"requireArmed"`
+  OSCArmPath   *string         `json:"oscArmPath"`
+
+  events *GateEvents
+  mux    sync.RWMutex
+
+  isArmed      bool
+  rejected     map[OSCGuardReason]uint64
+  lastRejected *OSCGuardRejectApp
+  lastLogs     map[string]time.Time
+  hmacSeen     map[string]time.Time // Accepted HMACs by timetag, pruned out of the window
+  stateMux     sync.Mutex
+}
+
+type OSCGuardRejectApp struct {
+  Time   time.Time      `json:"time"`
+  Reason OSCGuardReason `json:"reason"`
+  Source string         `json:"source"`
+  Path   string         `json:"path"`
+}
+
+type OSCGuardApp struct {
+  RequireArmed bool                      `json:"requireArmed"`
+  Armed        bool                      `json:"armed"`
+  OSCArmPath   *string                   `json:"oscArmPath"`
+  Allow        []*OSCGuardRule           `json:"allow"`
+  IsSecret     bool                      `json:"isSecret"`
+  IsHMAC       bool                      `json:"isHMAC"`
+  Rejected     map[OSCGuardReason]uint64 `json:"rejected"`
+  LastRejected *OSCGuardRejectApp        `json:"lastRejected"`
+}
+
+func (guard *OSCGuard) Check() error {
+  for i, rule := range guard.Allow {
+    if rule.Path == "" || strings.HasPrefix(rule.Path, "/") == false {
+      return fmt.Errorf("OSCGuard allow rule %d path %q must start with /", i, rule.Path)
+    }
+    if len(rule.Sources) == 0 {
+      return fmt.Errorf("OSCGuard allow rule %s has no sources", rule.Path)
+    }
+    for _, source := range rule.Sources {
+      if _, err := oscGuardNet(source); err != nil {
+        return fmt.Errorf("OSCGuard allow rule %s %w", rule.Path, err)
+      }
+    }
+  }
+  if guard.Secret != nil && *guard.Secret == "" {
+    return fmt.Errorf("OSCGuard secret is empty")
+  }
+  if guard.HMACKey != nil && *guard.HMACKey == "" {
+    return fmt.Errorf("OSCGuard HMAC key is empty")
+  }
+  if guard.HMACWindow != nil && *guard.HMACWindow <= 0 {
+    return fmt.Errorf("OSCGuard HMAC window %v must be positive", *guard.HMACWindow)
+  }
+  if guard.OSCArmPath != nil && strings.HasPrefix(*guard.OSCArmPath, "/") == false {
+    return fmt.Errorf("OSCGuard arm path %q must start with /", *guard.OSCArmPath)
+  }
+  return nil
+}
+
+// oscGuardNet parses a CIDR, a single IP is a network of one address
+func oscGuardNet(source string) (*net.IPNet, error) {
+  if strings.Contains(source, "/") == true {
+    _, ipNet, err := net.ParseCIDR(source)
+    if err != nil {
+      return nil, fmt.Errorf("incorrect source %q: %w", source, err)
+    }
+    return ipNet, nil
+  }
+
+  ip := net.ParseIP(source)
+  if ip == nil {
+    return nil, fmt.Errorf("incorrect source %q, must be a CIDR or an IP", source)
+  }
+  if ip4 := ip.To4(); ip4 != nil {
+    return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+  }
+  return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+func (guard *OSCGuard) Up(events *GateEvents) error {
+  if err := guard.Check(); err != nil {
+    return err
+  }
+  guard.compile()
+  guard.events = events
+
+  guard.stateMux.Lock()
+  guard.rejected = make(map[OSCGuardReason]uint64, len(OSCGuard_Reasons))
+  guard.lastLogs = make(map[string]time.Time)
+  guard.hmacSeen = make(map[string]time.Time)
+  guard.isArmed = guard.isRequireArmed() == false
+  guard.stateMux.Unlock()
+
+  if guard.isRequireArmed() == true {
+    log.Printf("[OSCGuard INFO] Motion commands are disarmed until the gate is armed\n")
+  }
+  return nil
+}
+
+func (guard *OSCGuard) compile() {
+  for _, rule := range guard.Allow {
+    rule.nets = make([]*net.IPNet, 0, len(rule.Sources))
+    for _, source := range rule.Sources {
+      if ipNet, err := oscGuardNet(source); err == nil {
+        rule.nets = append(rule.nets, ipNet)
+      }
+    }
+  }
+}
+
+// Update applies reloaded settings, counters are kept and enabling requireArmed disarms the gate
+func (guard *OSCGuard) Update(next *OSCGuard) {
+  next.compile()
+  wasRequired := guard.isRequireArmed()
+
+  guard.mux.Lock()
+  guard.Allow = next.Allow
+  guard.Secret = next.Secret
+  guard.HMACKey = next.HMACKey
+  guard.HMACWindow = next.HMACWindow
+  guard.RequireArmed = next.RequireArmed
+  guard.OSCArmPath = next.OSCArmPath
+  guard.mux.Unlock()
+
+  switch {
+    case wasRequired == false && guard.isRequireArmed() == true:
+      guard.SetArmed(false, "config reload")
+    case guard.isRequireArmed() == false:
+      guard.SetArmed(true, "config reload")
+  }
+}
+
+func (guard *OSCGuard) isRequireArmed() bool {
+  guard.mux.RLock()
+  defer guard.mux.RUnlock()
+  return guard.RequireArmed != nil && *guard.RequireArmed == true
+}
+
+func (guard *OSCGuard) IsArmed() bool {
+  guard.stateMux.Lock()
+  defer guard.stateMux.Unlock()
+  return guard.isArmed
+}
+
+// SetArmed switches motion commands on or off, it can not disarm a gate which does not require arming
+func (guard *OSCGuard) SetArmed(isArmed bool, by string) error {
+  if isArmed == false && guard.isRequireArmed() == false {
+    return fmt.Errorf("Arming is not required by config")
+  }
+
+  guard.stateMux.Lock()
+  isChanged := guard.isArmed != isArmed
+  guard.isArmed = isArmed
+  guard.stateMux.Unlock()
+
+  if isChanged == true {
+    log.Printf("[OSCGuard INFO] Gate is %s by %s\n", oscGuardArmedName(isArmed), by)
+    var value int32
+    if isArmed == true {
+      value = 1
+    }
+    guard.events.Event(GateEvent_Armed, "", value)
+  }
+  return nil
+}
+
+func oscGuardArmedName(isArmed bool) string {
+  if isArmed == true {
+    return "armed"
+  }
+  return "disarmed"
+}
+
+func (guard *OSCGuard) OSCMethods() []*OSCMethod {
+  guard.mux.RLock()
+  defer guard.mux.RUnlock()
+
+  if guard.OSCArmPath == nil {
+    return nil
+  }
+  return []*OSCMethod{NewOSCMethod(*guard.OSCArmPath, guard.processOSCArm)}
+}
+
+// processOSCArm handles <armPath> 1|0 on the dispatch goroutine, so arm and disarm keep their order.
+// The armed event and errors are sent with team oscReply, which writes UDP datagrams and never waits on a queue.
+func (guard *OSCGuard) processOSCArm(oscPacket *OSCPacket) {
+  values := oscPacket.Values()
+  if len(values) != 1 {
+    log.Printf("[OSCGuard ERROR] Incorrect OSC Arm values length of %+v\n", values)
+    guard.events.Error(oscPacket.Source, GateError_BadArity, "", fmt.Sprintf("Arm expects 1 value, got %d", len(values)))
+    return
+  }
+
+  var isArmed bool
+  switch value := values[0].(type) {
+    case int32:
+      isArmed = value != 0
+    case int64:
+      isArmed = value != 0
+    case float32:
+      isArmed = value != 0
+    case float64:
+      isArmed = value != 0
+    case bool:
+      isArmed = value
+    default:
+      log.Printf("[OSCGuard ERROR] Incorrect OSC Arm value type %T\n", values[0])
+      guard.events.Error(oscPacket.Source, GateError_BadType, "", fmt.Sprintf("Arm value %v is not a number or a boolean", values[0]))
+      return
+  }
+
+  if err := guard.SetArmed(isArmed, "OSC from " + oscGuardSource(oscPacket.Source)); err != nil {
+    log.Printf("[OSCGuard ERROR] OSC Arm error: %v\n", err)
+    guard.events.Error(oscPacket.Source, GateError_CommandFailed, "", err.Error())
+  }
+}
+
+// Admit runs the source, HMAC and secret checks when a packet is received, it returns methods the packet
+// may be delivered to and strips the secret and HMAC arguments. A rejected packet is counted once with the first failed check.
+func (guard *OSCGuard) Admit(oscPacket *OSCPacket, methods []*OSCMethod) []*OSCMethod {
+  guard.mux.RLock()
+  defer guard.mux.RUnlock()
+
+  allowed := guard.allowed(oscPacket, methods)
+  if len(allowed) == 0 {
+    guard.reject(OSCGuardReason_Source, oscPacket)
+    return nil
+  }
+
+  if guard.HMACKey != nil {
+    sum, timetag, ok := guard.verifyHMAC(oscPacket)
+    if ok == false {
+      guard.reject(OSCGuardReason_HMAC, oscPacket)
+      return nil
+    }
+    if guard.isReplay(sum, timetag) == true {
+      guard.reject(OSCGuardReason_Replay, oscPacket)
+      return nil
+    }
+    oscPacket.values = oscPacket.values[:len(oscPacket.values) - 2]
+  }
+
+  if guard.Secret != nil {
+    if len(oscPacket.values) == 0 || oscGuardEqual(oscPacket.values[0], *guard.Secret) == false {
+      guard.reject(OSCGuardReason_Secret, oscPacket)
+      return nil
+    }
+    oscPacket.values = oscPacket.values[1:]
+  }
+  return allowed
+}
+
+// Filter returns methods an admitted packet is delivered to, allow rules and the armed state are checked
+// again as a scheduled bundle may be delivered after a reload or disarm
+func (guard *OSCGuard) Filter(oscPacket *OSCPacket, methods []*OSCMethod) []*OSCMethod {
+  guard.mux.RLock()
+  allowed := guard.allowed(oscPacket, methods)
+  guard.mux.RUnlock()
+
+  if len(allowed) == 0 {
+    guard.reject(OSCGuardReason_Source, oscPacket)
+    return nil
+  }
+  return guard.Armed(oscPacket, allowed)
+}
+
+// Armed removes motion methods while the gate is disarmed, the rejected packet is reported to the sender
+func (guard *OSCGuard) Armed(oscPacket *OSCPacket, methods []*OSCMethod) []*OSCMethod {
+  if guard.IsArmed() == true {
+    return methods
+  }
+  armed := make([]*OSCMethod, 0, len(methods))
+  for _, method := range methods {
+    if method.IsMotion == false {
+      armed = append(armed, method)
+    }
+  }
+  if len(armed) < len(methods) && guard.reject(OSCGuardReason_Disarmed, oscPacket) == true {
+    guard.events.Error(oscPacket.Source, GateError_Disarmed, "", fmt.Sprintf("Motion command %s is rejected, the gate is disarmed", oscPacket.Path))
+  }
+  return armed
+}
+
+// Allows checks a source against allow rules of an address, it is used by inputs which are not OSC methods
+func (guard *OSCGuard) Allows(source net.Addr, address string) bool {
+  guard.mux.RLock()
+  defer guard.mux.RUnlock()
+  return guard.isAllowed(oscGuardIP(source), address)
+}
+
+// Reject counts input rejected outside of OSC dispatch, e.g. UDP timecode
+func (guard *OSCGuard) Reject(reason OSCGuardReason, source net.Addr, path string) {
+  guard.reject(reason, &OSCPacket{Path: path, Source: source})
+}
+
+func (guard *OSCGuard) allowed(oscPacket *OSCPacket, methods []*OSCMethod) []*OSCMethod {
+  ip := oscGuardIP(oscPacket.Source)
+  allowed := make([]*OSCMethod, 0, len(methods))
+  for _, method := range methods {
+    if guard.isAllowed(ip, method.Address) == true {
+      allowed = append(allowed, method)
+    }
+  }
+  return allowed
+}
+
+// isAllowed checks the source against the most specific rule of the address, local packets have no source
+func (guard *OSCGuard) isAllowed(ip net.IP, address string) bool {
+  if len(guard.Allow) == 0 || ip == nil {
+    return true
+  }
+
+  var match *OSCGuardRule
+  for _, rule := range guard.Allow {
+    prefix := strings.TrimSuffix(rule.Path, "/")
+    if address != rule.Path && strings.HasPrefix(address, prefix + "/") == false {
+      continue
+    }
+    if match == nil || len(rule.Path) > len(match.Path) {
+      match = rule
+    }
+  }
+  if match == nil {
+    return false
+  }
+
+  for _, ipNet := range match.nets {
+    if ipNet.Contains(ip) == true {
+      return true
+    }
+  }
+  return false
+}
+
+// verifyHMAC checks the HMAC argument, the timetag before it is covered by the HMAC
+func (guard *OSCGuard) verifyHMAC(oscPacket *OSCPacket) (sum []byte, timetag OSCTimetag, ok bool) {
+  n := len(oscPacket.values)
+  if n < 2 {
+    return nil, 0, false
+  }
+
+  switch value := oscPacket.values[n - 1].(type) {
+    case []byte:
+      sum = value
+    case string:
+      var err error
+      if sum, err = hex.DecodeString(value); err != nil {
+        return nil, 0, false
+      }
+    default:
+      return nil, 0, false
+  }
+  if timetag, ok = oscPacket.values[n - 2].(OSCTimetag); ok == false {
+    return nil, 0, false
+  }
+
+  data, err := (&OSCPacket{Path: oscPacket.Path, values: oscPacket.values[:n - 1]}).Bytes()
+  if err != nil {
+    return nil, 0, false
+  }
+  mac := hmac.New(sha256.New, []byte(*guard.HMACKey))
+  mac.Write(data)
+  return sum, timetag, hmac.Equal(sum, mac.Sum(nil))
+}
+
+func (guard *OSCGuard) hmacWindow() time.Duration {
+  if guard.HMACWindow != nil {
+    return time.Duration(*guard.HMACWindow * float64(time.Second))
+  }
+  return OSCGuard_HMACWindow
+}
+
+// isReplay rejects a timetag out of the window and an HMAC already accepted, a repeated HMAC is only
+// remembered while its timetag is in the window as older copies fail the window check
+func (guard *OSCGuard) isReplay(sum []byte, timetag OSCTimetag) bool {
+  window := guard.hmacWindow()
+  now := time.Now()
+  sent := timetag.Time()
+  if timetag.IsImmediate() == true || now.Sub(sent) > window || sent.Sub(now) > window {
+    return true
+  }
+
+  guard.stateMux.Lock()
+  defer guard.stateMux.Unlock()
+
+  for key, seen := range guard.hmacSeen {
+    if now.Sub(seen) > window {
+      delete(guard.hmacSeen, key)
+    }
+  }
+  key := string(sum)
+  if _, ok := guard.hmacSeen[key]; ok == true {
+    return true
+  }
+  guard.hmacSeen[key] = sent
+  return false
+}
+
+func oscGuardEqual(value any, secret string) bool {
+  var str string
+  switch t := value.(type) {
+    case string:
+      str = t
+    case OSCSymbol:
+      str = string(t)
+    default:
+      return false
+  }
+  return subtle.ConstantTimeCompare([]byte(str), []byte(secret)) == 1
+}
+
+// reject counts a rejected packet, a warning is logged once per OSCGuard_LogInterval for a reason and source
+// and isReported tells the caller to report it too
+func (guard *OSCGuard) reject(reason OSCGuardReason, oscPacket *OSCPacket) (isReported bool) {
+  source := oscGuardSource(oscPacket.Source)
+  now := time.Now()
+
+  guard.stateMux.Lock()
+  guard.rejected[reason]++
+  guard.lastRejected = &OSCGuardRejectApp{Time: now, Reason: reason, Source: source, Path: oscPacket.Path}
+  key := string(reason) + " " + oscGuardIP(oscPacket.Source).String()
+  isReported = now.Sub(guard.lastLogs[key]) >= OSCGuard_LogInterval
+  if isReported == true {
+    guard.lastLogs[key] = now
+  }
+  guard.stateMux.Unlock()
+
+  if isReported == true {
+    log.Printf("[OSCGuard WARNING] Packet %s from %s is rejected: %s\n", oscPacket.Path, source, reason)
+  }
+  return isReported
+}
+
+func (guard *OSCGuard) Rejected() map[OSCGuardReason]uint64 {
+  guard.stateMux.Lock()
+  defer guard.stateMux.Unlock()
+
+  rejected := make(map[OSCGuardReason]uint64, len(OSCGuard_Reasons))
+  for _, reason := range OSCGuard_Reasons {
+    rejected[reason] = guard.rejected[reason]
+  }
+  return rejected
+}
+
+func (guard *OSCGuard) GetAppData() *OSCGuardApp {
+  guard.mux.RLock()
+  guardApp := &OSCGuardApp{
+    RequireArmed: guard.RequireArmed != nil && *guard.RequireArmed == true,
+    OSCArmPath:   guard.OSCArmPath,
+    Allow:        guard.Allow,
+    IsSecret:     guard.Secret != nil,
+    IsHMAC:       guard.HMACKey != nil,
+  }
+  guard.mux.RUnlock()
+
+  if guardApp.Allow == nil {
+    guardApp.Allow = make([]*OSCGuardRule, 0)
+  }
+  guardApp.Rejected = guard.Rejected()
+
+  guard.stateMux.Lock()
+  guardApp.Armed = guard.isArmed
+  guardApp.LastRejected = guard.lastRejected
+  guard.stateMux.Unlock()
+
+  return guardApp
+}
+
+func oscGuardIP(source net.Addr) net.IP {
+  switch addr := source.(type) {
+    case *net.UDPAddr:
+      return addr.IP
+    case *net.TCPAddr:
+      return addr.IP
+  }
+  return nil
+}
+
+func oscGuardSource(source net.Addr) string {
+  if source == nil {
+    return "local"
+  }
+  return source.String()
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+  "crypto/hmac"
+  "crypto/sha256"
+  "net"
+  "testing"
+  "time"
+)
+
+const testOSCGuardKey = "key"
+
+type testOSCListener struct {
+  methods []*OSCMethod
+}
+
+func (listener *testOSCListener) OSCMethods() []*OSCMethod {
+  return listener.methods
+}
+
+func testOSCGuard(t *testing.T, guard *OSCGuard) *OSCGuard {
+  t.Helper()
+  if err := guard.Up(nil); err != nil {
+    t.Fatalf("Up() error: %v", err)
+  }
+  return guard
+}
+
+func testOSCSource(ip string) net.Addr {
+  return &net.UDPAddr{IP: net.ParseIP(ip), Port: 9000}
+}
+
+// testOSCSigned appends the send timetag and HMAC-SHA256 of the message as a sender does
+func testOSCSigned(t *testing.T, oscPacket *OSCPacket, sent time.Time) *OSCPacket {
+  t.Helper()
+  if err := oscPacket.Append(NewOSCTimetag(sent)); err != nil {
+    t.Fatalf("Append() error: %v", err)
+  }
+  mac := hmac.New(sha256.New, []byte(testOSCGuardKey))
+  mac.Write(testOSCBytes(t, oscPacket))
+  if err := oscPacket.Append(mac.Sum(nil)); err != nil {
+    t.Fatalf("Append() error: %v", err)
+  }
+  return oscPacket
+}
+
+func TestOSCGuardIsAllowed(t *testing.T) {
+  guard := testOSCGuard(t, &OSCGuard{Allow: []*OSCGuardRule{
+    {Path: "/", Sources: []string{"10.0.0.0/8"}},
+    {Path: "/bot", Sources: []string{"10.0.1.0/24"}},
+    {Path: "/bot/0/move", Sources: []string{"10.0.1.5"}},
+    {Path: "/status/", Sources: []string{"192.168.1.0/24"}},
+  }})
+
+  tests := []struct {
+    name    string
+    ip      string
+    address string
+    want    bool
+  }{
+    {"root rule", "10.2.3.4", "/timecode", true},
+    {"root rule denies", "192.168.1.10", "/timecode", false},
+    {"longer path wins over root", "10.2.3.4", "/bot/1/home", false},
+    {"longer path allows", "10.0.1.20", "/bot/1/home", true},
+    {"longest path wins", "10.0.1.20", "/bot/0/move", false},
+    {"single IP", "10.0.1.5", "/bot/0/move", true},
+    {"path prefix is a whole part", "10.2.3.4", "/botanic", true},
+    {"trailing slash rule", "192.168.1.10", "/status/bot", true},
+    {"trailing slash rule exact", "192.168.1.10", "/status", false},
+    {"local packet", "", "/bot/0/move", true},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      if got := guard.isAllowed(net.ParseIP(test.ip), test.address); got != test.want {
+        t.Errorf("isAllowed(%s, %s) = %v, want %v", test.ip, test.address, got, test.want)
+      }
+    })
+  }
+
+  if guard := testOSCGuard(t, &OSCGuard{}); guard.isAllowed(net.ParseIP("192.168.1.10"), "/bot/0/move") == false {
+    t.Errorf("isAllowed() without rules = false, want true")
+  }
+}
+
+func TestOSCGuardAdmit(t *testing.T) {
+  methods := []*OSCMethod{NewOSCMotionMethod("/bot/0/move", nil)}
+  now := time.Now()
+  hmacWindow := 30.0
+
+  tests := []struct {
+    name     string
+    guard    *OSCGuard
+    packet   func(t *testing.T) *OSCPacket
+    want     []any
+    rejected OSCGuardReason
+  }{
+    {"secret is stripped", &OSCGuard{Secret: stringPtr("s3cret")},
+      func(t *testing.T) *OSCPacket { return testOSCMessage(t, "/bot/0/move", "s3cret", int32(1)) },
+      []any{int32(1)}, ""},
+    {"secret symbol is stripped", &OSCGuard{Secret: stringPtr("s3cret")},
+      func(t *testing.T) *OSCPacket { return testOSCMessage(t, "/bot/0/move", OSCSymbol("s3cret"), int32(1)) },
+      []any{int32(1)}, ""},
+    {"wrong secret", &OSCGuard{Secret: stringPtr("s3cret")},
+      func(t *testing.T) *OSCPacket { return testOSCMessage(t, "/bot/0/move", "secret", int32(1)) },
+      nil, OSCGuardReason_Secret},
+    {"missing secret", &OSCGuard{Secret: stringPtr("s3cret")},
+      func(t *testing.T) *OSCPacket { return testOSCMessage(t, "/bot/0/move") },
+      nil, OSCGuardReason_Secret},
+    {"source is checked first", &OSCGuard{Secret: stringPtr("s3cret"), Allow: []*OSCGuardRule{{Path: "/", Sources: []string{"10.0.0.0/8"}}}},
+      func(t *testing.T) *OSCPacket { return testOSCMessage(t, "/bot/0/move", "secret") },
+      nil, OSCGuardReason_Source},
+    {"HMAC is stripped", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey)},
+      func(t *testing.T) *OSCPacket { return testOSCSigned(t, testOSCMessage(t, "/bot/0/move", int32(1)), now) },
+      []any{int32(1)}, ""},
+    {"HMAC and secret are stripped", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey), Secret: stringPtr("s3cret")},
+      func(t *testing.T) *OSCPacket { return testOSCSigned(t, testOSCMessage(t, "/bot/0/move", "s3cret", int32(1)), now) },
+      []any{int32(1)}, ""},
+    {"HMAC tampered value", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey)},
+      func(t *testing.T) *OSCPacket {
+        oscPacket := testOSCSigned(t, testOSCMessage(t, "/bot/0/move", int32(1)), now)
+        oscPacket.values[0] = int32(2)
+        return oscPacket
+      },
+      nil, OSCGuardReason_HMAC},
+    {"HMAC tampered path", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey)},
+      func(t *testing.T) *OSCPacket {
+        oscPacket := testOSCSigned(t, testOSCMessage(t, "/bot/1/move", int32(1)), now)
+        oscPacket.Path = "/bot/0/move"
+        return oscPacket
+      },
+      nil, OSCGuardReason_HMAC},
+    {"HMAC tampered timetag", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey)},
+      func(t *testing.T) *OSCPacket {
+        oscPacket := testOSCSigned(t, testOSCMessage(t, "/bot/0/move", int32(1)), now)
+        oscPacket.values[1] = NewOSCTimetag(now.Add(time.Second))
+        return oscPacket
+      },
+      nil, OSCGuardReason_HMAC},
+    {"HMAC missing timetag", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey)},
+      func(t *testing.T) *OSCPacket { return testOSCMessage(t, "/bot/0/move", int32(1), []byte{1}) },
+      nil, OSCGuardReason_HMAC},
+    {"HMAC wrong key", &OSCGuard{HMACKey: stringPtr("other")},
+      func(t *testing.T) *OSCPacket { return testOSCSigned(t, testOSCMessage(t, "/bot/0/move", int32(1)), now) },
+      nil, OSCGuardReason_HMAC},
+    {"stale timetag", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey)},
+      func(t *testing.T) *OSCPacket { return testOSCSigned(t, testOSCMessage(t, "/bot/0/move", int32(1)), now.Add(-10 * time.Second)) },
+      nil, OSCGuardReason_Replay},
+    {"future timetag", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey)},
+      func(t *testing.T) *OSCPacket { return testOSCSigned(t, testOSCMessage(t, "/bot/0/move", int32(1)), now.Add(10 * time.Second)) },
+      nil, OSCGuardReason_Replay},
+    {"timetag in a wider window", &OSCGuard{HMACKey: stringPtr(testOSCGuardKey), HMACWindow: &hmacWindow},
+      func(t *testing.T) *OSCPacket { return testOSCSigned(t, testOSCMessage(t, "/bot/0/move", int32(1)), now.Add(-10 * time.Second)) },
+      []any{int32(1)}, ""},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      guard := testOSCGuard(t, test.guard)
+      oscPacket := test.packet(t)
+      oscPacket.Source = testOSCSource("192.168.1.10")
+
+      admitted := guard.Admit(oscPacket, methods)
+      if test.rejected != "" {
+        if len(admitted) != 0 {
+          t.Errorf("Admit() = %d methods, want rejected by %s", len(admitted), test.rejected)
+        }
+        if rejected := guard.Rejected(); rejected[test.rejected] != 1 {
+          t.Errorf("Rejected() = %v, want 1 %s", rejected, test.rejected)
+        }
+        return
+      }
+
+      if len(admitted) != 1 {
+        t.Fatalf("Admit() = %d methods, want 1", len(admitted))
+      }
+      values := oscPacket.Values()
+      if len(values) != len(test.want) {
+        t.Fatalf("Values() = %v, want %v", values, test.want)
+      }
+      for i := range values {
+        if values[i] != test.want[i] {
+          t.Errorf("Values()[%d] = %v, want %v", i, values[i], test.want[i])
+        }
+      }
+    })
+  }
+}
+
+func TestOSCGuardIsReplay(t *testing.T) {
+  guard := testOSCGuard(t, &OSCGuard{HMACKey: stringPtr(testOSCGuardKey)})
+  now := time.Now()
+
+  tests := []struct {
+    name    string
+    sum     string
+    timetag OSCTimetag
+    want    bool
+  }{
+    {"first", "a", NewOSCTimetag(now), false},
+    {"repeated", "a", NewOSCTimetag(now), true},
+    {"other HMAC", "b", NewOSCTimetag(now), false},
+    {"immediate", "c", OSCTimetag_Immediate, true},
+    {"stale", "d", NewOSCTimetag(now.Add(-2 * OSCGuard_HMACWindow)), true},
+    {"ahead of the clock", "e", NewOSCTimetag(now.Add(2 * OSCGuard_HMACWindow)), true},
+    {"stale is not remembered", "d", NewOSCTimetag(now), false},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      if got := guard.isReplay([]byte(test.sum), test.timetag); got != test.want {
+        t.Errorf("isReplay(%s) = %v, want %v", test.sum, got, test.want)
+      }
+    })
+  }
+}
+
+func TestOSCGuardFilterArmed(t *testing.T) {
+  requireArmed := true
+  motion := NewOSCMotionMethod("/bot/0/move", nil)
+  status := NewOSCMethod("/bot/0/move", nil)
+
+  tests := []struct {
+    name    string
+    isArmed bool
+    methods []*OSCMethod
+    want    int
+  }{
+    {"armed motion", true, []*OSCMethod{motion}, 1},
+    {"disarmed motion", false, []*OSCMethod{motion}, 0},
+    {"disarmed keeps other methods", false, []*OSCMethod{motion, status}, 1},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      guard := testOSCGuard(t, &OSCGuard{RequireArmed: &requireArmed})
+      if err := guard.SetArmed(test.isArmed, "test"); err != nil {
+        t.Fatalf("SetArmed() error: %v", err)
+      }
+
+      oscPacket := testOSCMessage(t, "/bot/0/move", int32(1))
+      oscPacket.Source = testOSCSource("192.168.1.10")
+      if got := guard.Filter(oscPacket, test.methods); len(got) != test.want {
+        t.Errorf("Filter() = %d methods, want %d", len(got), test.want)
+      }
+    })
+  }
+}
+
+func TestOSCServerAdmitScheduled(t *testing.T) {
+  requireArmed := true
+  tests := []struct {
+    name    string
+    isArmed bool
+    timetag OSCTimetag
+    want    bool
+  }{
+    {"armed future bundle", true, NewOSCTimetag(time.Now().Add(time.Hour)), true},
+    {"disarmed future bundle", false, NewOSCTimetag(time.Now().Add(time.Hour)), false},
+    // Immediate motion is rejected on delivery with the armed state at that time
+    {"disarmed immediate bundle", false, OSCTimetag_Immediate, true},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      guard := testOSCGuard(t, &OSCGuard{RequireArmed: &requireArmed})
+      if err := guard.SetArmed(test.isArmed, "test"); err != nil {
+        t.Fatalf("SetArmed() error: %v", err)
+      }
+      osc := NewOSCServer(net.UDPAddr{})
+      osc.Subscribe(&testOSCListener{[]*OSCMethod{NewOSCMotionMethod("/bot/0/move", nil)}})
+      osc.SetGuard(guard)
+
+      bundle := NewOSCBundle(test.timetag)
+      bundle.Append(testOSCMessage(t, "/bot/0/move", int32(1)))
+      oscSetSource(bundle, testOSCSource("192.168.1.10"))
+
+      if got := osc.admit(bundle, false) != nil; got != test.want {
+        t.Errorf("admit() kept = %v, want %v", got, test.want)
+      }
+    })
+  }
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+  "fmt"
+  "math"
+)
+
+type OSCMappingCurve string
+
+const (
+  OSCMappingCurve_Linear         OSCMappingCurve = "linear"
+  OSCMappingCurve_EaseIn         OSCMappingCurve = "easeIn"         // Quadratic
+  OSCMappingCurve_EaseOut        OSCMappingCurve = "easeOut"        // Quadratic
+  OSCMappingCurve_EaseInOut      OSCMappingCurve = "easeInOut"      // Quadratic
+  OSCMappingCurve_EaseInCubic    OSCMappingCurve = "easeInCubic"
+  OSCMappingCurve_EaseOutCubic   OSCMappingCurve = "easeOutCubic"
+  OSCMappingCurve_EaseInOutCubic OSCMappingCurve = "easeInOutCubic"
+  OSCMappingCurve_Smoothstep     OSCMappingCurve = "smoothstep"
+)
+
+// OSCMapping_Targets are position components with their value indexes
+var OSCMapping_Targets = map[string]struct{
+  positionType PositionType
+  index        int
+}{
+  "A1": {PositionType_E6AXIS, 0}, "A2": {PositionType_E6AXIS, 1}, "A3": {PositionType_E6AXIS, 2},
+  "A4": {PositionType_E6AXIS, 3}, "A5": {PositionType_E6AXIS, 4}, "A6": {PositionType_E6AXIS, 5},
+  "E1": {PositionType_E6AXIS, 8}, "E2": {PositionType_E6AXIS, 9}, "E3": {PositionType_E6AXIS, 10},
+  "E4": {PositionType_E6AXIS, 11}, "E5": {PositionType_E6AXIS, 12}, "E6": {PositionType_E6AXIS, 13},
+  "X": {PositionType_E6POS, 0}, "Y": {PositionType_E6POS, 1}, "Z": {PositionType_E6POS, 2},
+  "A": {PositionType_E6POS, 3}, "B": {PositionType_E6POS, 4}, "C": {PositionType_E6POS, 5},
+}
+
+// OSCMapping binds one OSC argument to one axis or pose component:
+// value = curve(invert(input)) * scale + offset, clamped to min..max.
+// Invert and curves expect a normalised 0..1 input, curves clamp the input to 0..1.
+type OSCMapping struct {
+  Path     string          `json:"path"`
+  Argument int             `json:"argument"`
+  Target   string          `json:"target"`
+  Scale    *float32        `json:"scale"`
+  Offset   float32         `json:"offset"`
+  Min      *float32        `json:"min"`
+  Max      *float32        `json:"max"`
+  Invert   bool            `json:"invert"`
+  Curve    OSCMappingCurve `json:"curve"`
+}
+
+func (m *OSCMapping) Check() error {
+  if m.Path == "" {
+    return fmt.Errorf("OSCMapping path is empty")
+  }
+
+  if m.Argument < 0 {
+    return fmt.Errorf("OSCMapping %s incorrect argument %d", m.Path, m.Argument)
+  }
+
+  if _, ok := OSCMapping_Targets[m.Target]; ok == false {
+    return fmt.Errorf("OSCMapping %s incorrect target %q", m.Path, m.Target)
+  }
+
+  switch m.Curve {
+    case "", OSCMappingCurve_Linear, OSCMappingCurve_EaseIn, OSCMappingCurve_EaseOut, OSCMappingCurve_EaseInOut,
+      OSCMappingCurve_EaseInCubic, OSCMappingCurve_EaseOutCubic, OSCMappingCurve_EaseInOutCubic, OSCMappingCurve_Smoothstep:
+    default:
+      return fmt.Errorf("OSCMapping %s incorrect curve %q", m.Path, m.Curve)
+  }
+
+  if m.Min != nil && m.Max != nil && *m.Min > *m.Max {
+    return fmt.Errorf("OSCMapping %s min %f is greater than max %f", m.Path, *m.Min, *m.Max)
+  }
+  return nil
+}
+
+func (m *OSCMapping) PositionType() PositionType {
+  return OSCMapping_Targets[m.Target].positionType
+}
+
+func (m *OSCMapping) Index() int {
+  return OSCMapping_Targets[m.Target].index
+}
+
+func (m *OSCMapping) Value(input float32) float32 {
+  v := float64(input)
+
+  if m.Curve != "" && m.Curve != OSCMappingCurve_Linear {
+    v = math.Max(0, math.Min(1, v))
+  }
+
+  if m.Invert == true {
+    v = 1 - v
+  }
+
+  switch m.Curve {
+    case OSCMappingCurve_EaseIn:
+      v = v * v
+    case OSCMappingCurve_EaseOut:
+      v = 1 - (1 - v) * (1 - v)
+    case OSCMappingCurve_EaseInOut:
+      if v < 0.5 {
+        v = 2 * v * v
+      } else {
+        v = 1 - 2 * (1 - v) * (1 - v)
+      }
+    case OSCMappingCurve_EaseInCubic:
+      v = v * v * v
+    case OSCMappingCurve_EaseOutCubic:
+      v = 1 - math.Pow(1 - v, 3)
+    case OSCMappingCurve_EaseInOutCubic:
+      if v < 0.5 {
+        v = 4 * v * v * v
+      } else {
+        v = 1 - 4 * math.Pow(1 - v, 3)
+      }
+    case OSCMappingCurve_Smoothstep:
+      v = v * v * (3 - 2 * v)
+  }
+
+  var scale float64 = 1
+  if m.Scale != nil {
+    scale = float64(*m.Scale)
+  }
+  v = v * scale + float64(m.Offset)
+
+  if m.Min != nil {
+    v = math.Max(v, float64(*m.Min))
+  }
+  if m.Max != nil {
+    v = math.Min(v, float64(*m.Max))
+  }
+  return float32(v)
+}
+
+// oscMappingPaths groups mappings by OSC path in configuration order,
+// every mapping of a path must target the same position type
+func oscMappingPaths(mappings []*OSCMapping) ([]string, map[string][]*OSCMapping, error) {
+  var paths []string
+  groups := make(map[string][]*OSCMapping)
+
+  for _, mapping := range mappings {
+    if err := mapping.Check(); err != nil {
+      return nil, nil, err
+    }
+
+    group, ok := groups[mapping.Path]
+    if ok == false {
+      paths = append(paths, mapping.Path)
+    } else if group[0].PositionType() != mapping.PositionType() {
+      return nil, nil, fmt.Errorf("OSCMapping %s mixes axis and pose targets", mapping.Path)
+    }
+    groups[mapping.Path] = append(group, mapping)
+  }
+  return paths, groups, nil
+}
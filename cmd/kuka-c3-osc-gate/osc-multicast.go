@@ -0,0 +1,55 @@
+package main
+
+import (
+  "fmt"
+  "log"
+  "net"
+)
+
+// JoinMulticast makes the UDP listener receive OSC commands sent to a multicast group,
+// ifaceName selects the interface, empty for the system default
+func (osc *OSCServer) JoinMulticast(group string, ifaceName string) error {
+  ip := net.ParseIP(group)
+  if ip == nil || ip.To4() == nil || ip.IsMulticast() == false {
+    return fmt.Errorf("OSCServer %s is not an IPv4 multicast group", group)
+  }
+
+  var ifaceIP net.IP = net.IPv4zero
+  if ifaceName != "" {
+    iface, err := net.InterfaceByName(ifaceName)
+    if err != nil {
+      return fmt.Errorf("OSCServer multicast interface error: %w", err)
+    }
+    addrs, err := iface.Addrs()
+    if err != nil {
+      return fmt.Errorf("OSCServer multicast interface %s error: %w", ifaceName, err)
+    }
+    for _, addr := range addrs {
+      if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+        ifaceIP = ipNet.IP.To4()
+        break
+      }
+    }
+    if ifaceIP.Equal(net.IPv4zero) {
+      return fmt.Errorf("OSCServer multicast interface %s has no IPv4 address", ifaceName)
+    }
+  }
+
+  rawConn, err := osc.conn.SyscallConn()
+  if err != nil {
+    return fmt.Errorf("OSCServer multicast error: %w", err)
+  }
+
+  var joinErr error
+  if err := rawConn.Control(func(fd uintptr) {
+    joinErr = oscJoinMulticast(fd, ip.To4(), ifaceIP.To4())
+  }); err != nil {
+    return fmt.Errorf("OSCServer multicast error: %w", err)
+  }
+  if joinErr != nil {
+    return fmt.Errorf("OSCServer multicast join %s error: %w", group, joinErr)
+  }
+
+  log.Printf("[OSCServer INFO] Joined multicast group %s on %s\n", group, ifaceIP.String())
+  return nil
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import (
+  "net"
+  "syscall"
+)
+
+func oscJoinMulticast(fd uintptr, group net.IP, iface net.IP) error {
+  mreq := &syscall.IPMreq{}
+  copy(mreq.Multiaddr[:], group)
+  copy(mreq.Interface[:], iface)
+  return syscall.SetsockoptIPMreq(int(fd), syscall.IPPROTO_IP, syscall.IP_ADD_MEMBERSHIP, mreq)
+}
@@ -5,6 +5,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"strings"
 )
 
@@ -16,9 +19,26 @@ const (
 	OSCOutputStatus_Error OSCOutputStatus = 3
 )
 
+// OSC 1.0/1.1 argument types without a native Go counterpart:
+// i int32, h int64, f float32, d float64, s string, b []byte, T/F bool, N nil, [ ] []any
+type OSCSymbol string  // S
+type OSCChar int32     // c
+type OSCImpulse struct{} // I
+
+type OSCColor struct { // r
+	R, G, B, A uint8
+}
+
+type OSCMIDI struct { // m
+	Port, Status, Data1, Data2 uint8
+}
+
 type OSCPacket struct {
 	Path   string
 	values []any
+
+	// Source is the sender address of a received packet, nil for local packets
+	Source net.Addr
 }
 
 func NewOSCPacket() *OSCPacket {
@@ -29,69 +49,166 @@ func NewOSCPacket() *OSCPacket {
 }
 
 func (p *OSCPacket) Parse(data []byte) error {
-	var start int = 0
+	// Whole datagram is buffered, so reader.Buffered() is the unread length
 	byteReader := bytes.NewReader(data)
-	reader := bufio.NewReader(byteReader)
+	reader := bufio.NewReaderSize(byteReader, len(data))
 
 	// Read path string
-	path, n, err := oscReadPaddedString(reader)
+	path, _, err := oscReadPaddedString(reader)
 	if err != nil {
 		return err
 	}
-	start += n
 
-	// Read tags string
-	typeTags, n, err := oscReadPaddedString(reader)
+	// Read tags string, messages without tags are treated as having no arguments
+	var typeTags string = ","
+	if reader.Buffered() > 0 {
+		if typeTags, _, err = oscReadPaddedString(reader); err != nil {
+			return err
+		}
+	}
+
+	if len(typeTags) == 0 || typeTags[0] != ',' {
+		return fmt.Errorf("Type tags %q do not start with ','", typeTags)
+	}
+
+	// Remove ',' from tags
+	tags := []rune(typeTags[1:])
+	var n int = 0
+	values, err := oscReadValues(reader, tags, &n, false)
 	if err != nil {
 		return err
 	}
 
-	// Remove ',' from tags
-	typeTags = typeTags[1:]
-
-	var values []any
-  for _, char := range typeTags {
-  	switch char {
-  		case 'i': // int32
-  			var i int32
-  			err = binary.Read(reader, binary.BigEndian, &i)
-				if err != nil {
-					return err
+	p.Path = path
+	p.values = values
+	return nil
+}
+
+func oscReadValues(reader *bufio.Reader, tags []rune, n *int, isArray bool) ([]any, error) {
+	values := make([]any, 0)
+	for *n < len(tags) {
+		char := tags[*n]
+		*n++
+
+		switch char {
+			case 'i': // int32
+				var i int32
+				if err := binary.Read(reader, binary.BigEndian, &i); err != nil {
+					return nil, err
 				}
 				values = append(values, i)
 
 			case 'h': // int64
 				var i int64
-				err = binary.Read(reader, binary.BigEndian, &i)
-				if err != nil {
-					return err
+				if err := binary.Read(reader, binary.BigEndian, &i); err != nil {
+					return nil, err
 				}
 				values = append(values, i)
 
 			case 'f': // float32
 				var f float32
-				err = binary.Read(reader, binary.BigEndian, &f)
-				if err != nil {
-					return err
+				if err := binary.Read(reader, binary.BigEndian, &f); err != nil {
+					return nil, err
 				}
 				values = append(values, f)
 
 			case 'd': // float64/double
 				var d float64
-				err = binary.Read(reader, binary.BigEndian, &d)
-				if err != nil {
-					return err
+				if err := binary.Read(reader, binary.BigEndian, &d); err != nil {
+					return nil, err
 				}
 				values = append(values, d)
 
+			case 's': // string
+				str, _, err := oscReadPaddedString(reader)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, str)
+
+			case 'S': // symbol
+				str, _, err := oscReadPaddedString(reader)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, OSCSymbol(str))
+
+			case 'b': // blob
+				var size int32
+				if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+					return nil, err
+				}
+				if size < 0 || int(size) > reader.Buffered() {
+					return nil, fmt.Errorf("Incorrect blob size %d", size)
+				}
+				blob := make([]byte, int(size) + oscPadBytesNeeded(int(size)))
+				if _, err := io.ReadFull(reader, blob); err != nil {
+					return nil, err
+				}
+				values = append(values, blob[:size])
+
+			case 't': // timetag
+				var t uint64
+				if err := binary.Read(reader, binary.BigEndian, &t); err != nil {
+					return nil, err
+				}
+				values = append(values, OSCTimetag(t))
+
+			case 'c': // ASCII char as 32 bits
+				var c int32
+				if err := binary.Read(reader, binary.BigEndian, &c); err != nil {
+					return nil, err
+				}
+				values = append(values, OSCChar(c))
+
+			case 'r': // RGBA color
+				var color OSCColor
+				if err := binary.Read(reader, binary.BigEndian, &color); err != nil {
+					return nil, err
+				}
+				values = append(values, color)
+
+			case 'm': // MIDI message: port id, status byte, data1, data2
+				var midi OSCMIDI
+				if err := binary.Read(reader, binary.BigEndian, &midi); err != nil {
+					return nil, err
+				}
+				values = append(values, midi)
+
+			case 'T': // true
+				values = append(values, true)
+
+			case 'F': // false
+				values = append(values, false)
+
+			case 'N': // nil
+				values = append(values, nil)
+
+			case 'I': // impulse/infinitum
+				values = append(values, OSCImpulse{})
+
+			case '[': // array start
+				array, err := oscReadValues(reader, tags, n, true)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, array)
+
+			case ']': // array end
+				if isArray == false {
+					return nil, fmt.Errorf("Unexpected array end type tag")
+				}
+				return values, nil
+
 			default:
-				return fmt.Errorf("Unsupported type tag: %c", char)
-  	}	
-  }
+				return nil, fmt.Errorf("Unsupported type tag: %c", char)
+		}
+	}
 
-  p.Path = path
-  p.values = values
-  return nil
+	if isArray == true {
+		return nil, fmt.Errorf("Array type tag is not closed")
+	}
+	return values, nil
 }
 
 func (p *OSCPacket) Bytes() ([]byte, error) {
@@ -103,70 +220,133 @@ func (p *OSCPacket) Bytes() ([]byte, error) {
 	}
 
 	// Type tag string starts with ","
-	lenArgs := len(p.values)
-	typetags := make([]byte, lenArgs+1)
-	typetags[0] = ','
+	typetags := []byte{','}
 
 	// Process the type tags and collect all arguments
 	payload := new(bytes.Buffer)
+	if typetags, err = oscWriteValues(p.values, typetags, payload); err != nil {
+		return nil, err
+	}
 
-	for i, value := range p.values {
+	// Write the type tag string to the data buffer
+	if _, err := oscWritePaddedString(string(typetags), data); err != nil {
+		return nil, err
+	}
+
+	// Write the payload (OSC arguments) to the data buffer
+	if _, err := data.Write(payload.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+func oscWriteValues(values []any, typetags []byte, payload *bytes.Buffer) ([]byte, error) {
+	for _, value := range values {
+		var err error
 		switch t := value.(type) {
 			case int32:
-				typetags[i + 1] = 'i'
+				typetags = append(typetags, 'i')
 				err = binary.Write(payload, binary.BigEndian, t)
-				if err != nil {
-					return nil, err
-				}
 
 			case int64:
-				typetags[i + 1] = 'h'
+				typetags = append(typetags, 'h')
 				err = binary.Write(payload, binary.BigEndian, t)
-				if err != nil {
-					return nil, err
-				}
 
 			case float32:
-				typetags[i+1] = 'f'
-				err := binary.Write(payload, binary.BigEndian, t)
-				if err != nil {
-					return nil, err
-				}
+				typetags = append(typetags, 'f')
+				err = binary.Write(payload, binary.BigEndian, t)
 
 			case float64:
-				typetags[i+1] = 'd'
+				typetags = append(typetags, 'd')
 				err = binary.Write(payload, binary.BigEndian, t)
-				if err != nil {
-					return nil, err
+
+			case string:
+				typetags = append(typetags, 's')
+				_, err = oscWritePaddedString(t, payload)
+
+			case OSCSymbol:
+				typetags = append(typetags, 'S')
+				_, err = oscWritePaddedString(string(t), payload)
+
+			case []byte:
+				typetags = append(typetags, 'b')
+				if err = binary.Write(payload, binary.BigEndian, int32(len(t))); err == nil {
+					payload.Write(t)
+					payload.Write(make([]byte, oscPadBytesNeeded(len(t))))
+				}
+
+			case OSCTimetag:
+				typetags = append(typetags, 't')
+				err = binary.Write(payload, binary.BigEndian, uint64(t))
+
+			case OSCChar:
+				typetags = append(typetags, 'c')
+				err = binary.Write(payload, binary.BigEndian, int32(t))
+
+			case OSCColor:
+				typetags = append(typetags, 'r')
+				err = binary.Write(payload, binary.BigEndian, t)
+
+			case OSCMIDI:
+				typetags = append(typetags, 'm')
+				err = binary.Write(payload, binary.BigEndian, t)
+
+			case bool:
+				if t == true {
+					typetags = append(typetags, 'T')
+				} else {
+					typetags = append(typetags, 'F')
+				}
+
+			case nil:
+				typetags = append(typetags, 'N')
+
+			case OSCImpulse:
+				typetags = append(typetags, 'I')
+
+			case []any:
+				typetags = append(typetags, '[')
+				if typetags, err = oscWriteValues(t, typetags, payload); err == nil {
+					typetags = append(typetags, ']')
 				}
+
 			default:
 				return nil, fmt.Errorf("Unsupported type: %T", t)
 		}
-	}
 
-	// Write the type tag string to the data buffer
-	if _, err := oscWritePaddedString(string(typetags), data); err != nil {
-		return nil, err
-	}
-
-	// Write the payload (OSC arguments) to the data buffer
-	if _, err := data.Write(payload.Bytes()); err != nil {
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return data.Bytes(), nil
+	return typetags, nil
 }
 
 func (p *OSCPacket) Append(arg any) error {
+	if err := oscCheckValue(arg); err != nil {
+		return err
+	}
+	p.values = append(p.values, arg)
+	return nil
+}
+
+func oscCheckValue(arg any) error {
 	switch t := arg.(type) {
 		// OSC types check
-		case int32, int64, float32, float64:
-			p.values = append(p.values, arg)
+		case int32, int64, float32, float64, string, OSCSymbol, []byte, OSCTimetag,
+		     OSCChar, OSCColor, OSCMIDI, bool, nil, OSCImpulse:
+			return nil
+		case []any:
+			for _, value := range t {
+				if err := oscCheckValue(value); err != nil {
+					return err
+				}
+			}
+			return nil
 		default:
 			return fmt.Errorf("Unsupported type: %T", t)
 	}
-
-	return nil
 }
 
 func (p *OSCPacket) Values() []any {
@@ -175,6 +355,62 @@ func (p *OSCPacket) Values() []any {
 	return values
 }
 
+// OSCInt32 converts a numeric OSC argument to int32, floats must be integral.
+// T and F are not numbers, so a boolean is never taken as an id or an index, switches like arm accept it themselves.
+func OSCInt32(value any) (int32, error) {
+	switch t := value.(type) {
+		case int32:
+			return t, nil
+		case int64:
+			if t < math.MinInt32 || t > math.MaxInt32 {
+				return 0, fmt.Errorf("int64 %d is out of int32 range", t)
+			}
+			return int32(t), nil
+		case float32:
+			return OSCInt32(float64(t))
+		case float64:
+			if t != math.Trunc(t) || t < math.MinInt32 || t > math.MaxInt32 {
+				return 0, fmt.Errorf("float %v is not an int32 value", t)
+			}
+			return int32(t), nil
+		case OSCChar:
+			return int32(t), nil
+		default:
+			return 0, fmt.Errorf("%T is not a numeric value", value)
+	}
+}
+
+// oscPositionArgs converts MoveGroup id, speed and index arguments of a position request
+func oscPositionArgs(values []any) ([3]int32, error) {
+	var args [3]int32
+	for i, value := range values {
+		if i >= len(args) {
+			break
+		}
+		v, err := OSCInt32(value)
+		if err != nil {
+			return args, fmt.Errorf("values[%d] error: %w", i, err)
+		}
+		args[i] = v
+	}
+	return args, nil
+}
+
+// OSCFloat32 converts a numeric OSC argument to float32
+func OSCFloat32(value any) (float32, error) {
+	switch t := value.(type) {
+		case float32:
+			return t, nil
+		case float64:
+			return float32(t), nil
+		case int32:
+			return float32(t), nil
+		case int64:
+			return float32(t), nil
+		default:
+			return 0, fmt.Errorf("%T is not a numeric value", value)
+	}
+}
 
 func oscReadPaddedString(reader *bufio.Reader) (string, int, error) {
 	// Read the string from the reader
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOSCInt32(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    int32
+		wantErr bool
+	}{
+		{"int32", int32(7), 7, false},
+		{"int64", int64(-7), -7, false},
+		{"int64 over range", int64(math.MaxInt32) + 1, 0, true},
+		{"integral float32", float32(3), 3, false},
+		{"integral float64", float64(-3), -3, false},
+		{"fractional float", float64(1.5), 0, true},
+		{"char", OSCChar('A'), 65, false},
+		{"true", true, 0, true},
+		{"false", false, 0, true},
+		{"string", "1", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := OSCInt32(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("OSCInt32(%v) error = %v, want error %v", test.value, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("OSCInt32(%v) = %d, want %d", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestOSCPositionArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []any
+		want    [3]int32
+		wantErr bool
+	}{
+		{"numbers", []any{int32(1), float32(50), int64(2)}, [3]int32{1, 50, 2}, false},
+		{"boolean id", []any{true, int32(50), int32(0)}, [3]int32{}, true},
+		{"boolean index", []any{int32(1), int32(50), false}, [3]int32{1, 50, 0}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := oscPositionArgs(test.values)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("oscPositionArgs(%v) error = %v, want error %v", test.values, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("oscPositionArgs(%v) = %v, want %v", test.values, got, test.want)
+			}
+		})
+	}
+}
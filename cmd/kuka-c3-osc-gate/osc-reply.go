@@ -0,0 +1,114 @@
+package main
+
+import (
+  "fmt"
+  "log"
+  "net"
+  "time"
+)
+
+const (
+  OSCReply_IdleTimeout   = 5 * time.Minute
+  OSCReply_CheckInterval = 10 * time.Second
+)
+
+type oscReplyClient struct {
+  client   *OSCClient
+  lastUsed time.Time
+}
+
+// Reply sends an element back to the sender of a received packet:
+// UDP senders get it from a per-sender client expired after OSCReply_IdleTimeout,
+// TCP senders get it on their own connection.
+func (osc *OSCServer) Reply(source net.Addr, element OSCElement) error {
+  switch addr := source.(type) {
+    case *net.UDPAddr:
+      client, err := osc.replyClient(addr)
+      if err != nil {
+        return err
+      }
+      return client.Send(element)
+
+    case *net.TCPAddr:
+      data, err := element.Bytes()
+      if err != nil {
+        return fmt.Errorf("OSCServer reply bytes error: %w", err)
+      }
+
+      osc.tcpConnsMux.Lock()
+      tcpConn, ok := osc.tcpConns[addr.String()]
+      osc.tcpConnsMux.Unlock()
+      if ok == false {
+        return fmt.Errorf("OSCServer reply TCP client %s is disconnected", addr.String())
+      }
+      return tcpConn.Write(data)
+  }
+
+  return fmt.Errorf("OSCServer reply source %v is not supported", source)
+}
+
+func (osc *OSCServer) replyClient(addr *net.UDPAddr) (*OSCClient, error) {
+  osc.replyClientsMux.Lock()
+  defer osc.replyClientsMux.Unlock()
+
+  if osc.isShutdown.Load() == true {
+    return nil, fmt.Errorf("OSCServer is shutdown")
+  }
+
+  address := addr.String()
+  if replyClient, ok := osc.replyClients[address]; ok {
+    replyClient.lastUsed = time.Now()
+    return replyClient.client, nil
+  }
+
+  client, err := NewOSCClient(address)
+  if err != nil {
+    return nil, err
+  }
+  osc.replyClients[address] = &oscReplyClient{client: client, lastUsed: time.Now()}
+
+  if osc.debugFlag == true {
+    log.Printf("[OSCServer DEBUG] Reply client %s is created\n", address)
+  }
+  return client, nil
+}
+
+func (osc *OSCServer) processReplyClients() {
+  defer osc.wg.Done()
+
+  ticker := time.NewTicker(OSCReply_CheckInterval)
+  defer ticker.Stop()
+
+  for {
+    select {
+      case <-osc.doneChan:
+        return
+
+      case <-ticker.C:
+        var expired []*OSCClient
+        osc.replyClientsMux.Lock()
+        for address, replyClient := range osc.replyClients {
+          if time.Since(replyClient.lastUsed) > OSCReply_IdleTimeout {
+            expired = append(expired, replyClient.client)
+            delete(osc.replyClients, address)
+          }
+        }
+        osc.replyClientsMux.Unlock()
+
+        for _, client := range expired {
+          client.Shutdown()
+        }
+    }
+  }
+}
+
+func (osc *OSCServer) shutdownReplyClients() {
+  osc.replyClientsMux.Lock()
+  replyClients := osc.replyClients
+  osc.replyClients = make(map[string]*oscReplyClient)
+  osc.replyClientsMux.Unlock()
+
+  for _, replyClient := range replyClients {
+    replyClient.client.Shutdown()
+  }
+}
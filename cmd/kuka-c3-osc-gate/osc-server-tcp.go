@@ -0,0 +1,104 @@
+package main
+
+import (
+  "errors"
+  "log"
+  "net"
+  "sync"
+  "time"
+)
+
+type oscTCPConn struct {
+  conn     net.Conn
+  framing  OSCFraming
+  writeMux sync.Mutex
+}
+
+func (c *oscTCPConn) Write(data []byte) error {
+  c.writeMux.Lock()
+  defer c.writeMux.Unlock()
+
+  c.conn.SetWriteDeadline(time.Now().Add(OSCClient_RetryTimeout))
+  _, err := c.conn.Write(oscEncodeFrame(c.framing, data))
+  return err
+}
+
+// ListenTCP accepts OSC stream clients in addition to UDP, every client connection is served concurrently
+func (osc *OSCServer) ListenTCP(addr net.TCPAddr, framing OSCFraming) error {
+  listener, err := net.ListenTCP("tcp", &addr)
+  if err != nil {
+    return err
+  }
+  osc.tcpListener = listener
+  osc.tcpFraming = framing
+
+  osc.readersWg.Add(1)
+  go osc.serveTCP()
+
+  log.Printf("[OSCServer INFO] TCP server start successfully at %s with %s framing\n", addr.String(), framing)
+  return nil
+}
+
+func (osc *OSCServer) serveTCP() {
+  defer osc.readersWg.Done()
+
+  for {
+    conn, err := osc.tcpListener.Accept()
+    if err != nil {
+      if errors.Is(err, net.ErrClosed) {
+        return
+      }
+      log.Printf("[OSCServer ERROR] Error accepting TCP client: %v\n", err)
+      continue
+    }
+
+    osc.tcpConnsMux.Lock()
+    if osc.isShutdown.Load() == true {
+      osc.tcpConnsMux.Unlock()
+      conn.Close()
+      return
+    }
+    osc.tcpConns[conn.RemoteAddr().String()] = &oscTCPConn{conn: conn, framing: osc.tcpFraming}
+    osc.tcpConnsMux.Unlock()
+
+    osc.readersWg.Add(1)
+    go osc.serveTCPConn(conn)
+  }
+}
+
+func (osc *OSCServer) serveTCPConn(conn net.Conn) {
+  defer osc.readersWg.Done()
+  defer func() {
+    osc.tcpConnsMux.Lock()
+    delete(osc.tcpConns, conn.RemoteAddr().String())
+    osc.tcpConnsMux.Unlock()
+    conn.Close()
+  }()
+
+  log.Printf("[OSCServer INFO] TCP client %s connected\n", conn.RemoteAddr().String())
+
+  reader := newOSCFrameReader(conn, osc.tcpFraming)
+  for {
+    packet, err := reader.ReadFrame()
+    if err != nil {
+      if osc.isShutdown.Load() == false {
+        log.Printf("[OSCServer INFO] TCP client %s disconnected: %v\n", conn.RemoteAddr().String(), err)
+      }
+      return
+    }
+    osc.push(packet, conn.RemoteAddr())
+  }
+}
+
+func (osc *OSCServer) shutdownTCP() {
+  if osc.tcpListener == nil {
+    return
+  }
+  osc.tcpListener.Close()
+
+  osc.tcpConnsMux.Lock()
+  for _, tcpConn := range osc.tcpConns {
+    tcpConn.conn.Close()
+  }
+  osc.tcpConnsMux.Unlock()
+}
@@ -4,30 +4,59 @@ import (
   "log"
   "net"
   "sync"
+  "sync/atomic"
+  "time"
 )
 
 const (
   OSCServer_PacketsBuffer = 512
-  OSCServer_UDPBuffer = 1024
+  OSCServer_UDPBuffer = 65535
+  OSCServer_BundleMaxDelay = 60 * time.Second
+  OSCServer_MaxScheduled = 1024
 )
 
-type OSCListener interface {
-   OSCPacket(oscPacket *OSCPacket)
+type oscDatagram struct {
+  data   []byte
+  source net.Addr
 }
 
 type OSCServer struct {
   addr net.UDPAddr
   conn *net.UDPConn
   
-  packets   chan []byte
+  tcpListener net.Listener
+  tcpFraming  OSCFraming
+  tcpConns    map[string]*oscTCPConn
+  tcpConnsMux sync.Mutex
+
+  replyClients    map[string]*oscReplyClient
+  replyClientsMux sync.Mutex
+  doneChan        chan struct{}
+
+  packets   chan *oscDatagram
   closeOnce sync.Once
 
-  subscribers    []OSCListener
+  // Readers are UDP and TCP goroutines sending to packets, they are done before packets is closed
+  readersWg sync.WaitGroup
+
+  dispatcher     *OSCDispatcher
+  guard          *OSCGuard
   subscribersMux sync.RWMutex
 
+  // Bundle elements are delivered under dispatchMux, so no other packet is interleaved
+  dispatchMux sync.Mutex
+
+  // Bundles are scheduled up to maxDelay ahead, at most OSCServer_MaxScheduled at a time
+  timers    map[*time.Timer]struct{}
+  maxDelay  time.Duration
+  timersMux sync.Mutex
+
   wg sync.WaitGroup
 
-  isShutdown bool
+  dropped atomic.Uint64
+
+  // isShutdown is read by UDP, TCP and reply goroutines under different mutexes
+  isShutdown atomic.Bool
   debugFlag  bool
 }
 
@@ -36,8 +65,13 @@ func NewOSCServer(addr net.UDPAddr) *OSCServer {
     addr: addr,
     conn: nil,
     
-    packets:     make(chan []byte, OSCServer_PacketsBuffer),
-    subscribers: make([]OSCListener, 0),
+    tcpConns:     make(map[string]*oscTCPConn),
+    replyClients: make(map[string]*oscReplyClient),
+    doneChan:     make(chan struct{}),
+    packets:      make(chan *oscDatagram, OSCServer_PacketsBuffer),
+    dispatcher:  NewOSCDispatcher(),
+    timers:      make(map[*time.Timer]struct{}),
+    maxDelay:    OSCServer_BundleMaxDelay,
   }
 }
 
@@ -48,46 +82,200 @@ func (osc *OSCServer) closePackets() {
 }
 
 func (osc *OSCServer) serve() {
-  defer osc.wg.Done()
+  defer osc.readersWg.Done()
 
   buffer := make([]byte, OSCServer_UDPBuffer)
   for {
-    n, _, err := osc.conn.ReadFromUDP(buffer)
+    n, source, err := osc.conn.ReadFromUDP(buffer)
     if err != nil {
       if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
         continue
       }
-      if osc.isShutdown == false {
+      if osc.isShutdown.Load() == false {
         log.Printf("[OSCServer ERROR] Error reading from UDP: %v\n", err)
       }
-      osc.closePackets()
       return
     }
 
     packet := make([]byte, n)
     copy(packet, buffer[:n])
-    select {
-      case osc.packets <- packet:
-      default:
-        log.Printf("[OSCServer WARNING] Packets channel is full, discarding packet\n")
-    }
+    osc.push(packet, source)
+  }
+}
+
+func (osc *OSCServer) push(packet []byte, source net.Addr) {
+  select {
+    case osc.packets <- &oscDatagram{data: packet, source: source}:
+    default:
+      osc.dropped.Add(1)
+      log.Printf("[OSCServer WARNING] Packets channel is full, discarding packet\n")
   }
 }
 
 func (osc *OSCServer) distributePackets() {
   defer osc.wg.Done()
   for packet := range osc.packets {
-    oscPacket := NewOSCPacket()
-    if err := oscPacket.Parse(packet); err != nil {
+    element, err := ParseOSCElement(packet.data)
+    if err != nil {
       log.Printf("[OSCServer ERROR] Packets parse error %v\n", err)
       continue
     }
-    osc.subscribersMux.RLock()
-    for _, listener := range osc.subscribers {
-      listener.OSCPacket(oscPacket)
+    oscSetSource(element, packet.source)
+
+    switch t := osc.admit(element, false).(type) {
+      case *OSCPacket:
+        osc.deliver([]*OSCPacket{t})
+      case *OSCBundle:
+        osc.dispatchBundle(t)
+    }
+  }
+}
+
+// admit runs guard checks when a packet is received and prunes rejected messages, so a scheduled bundle only
+// holds admitted messages. Messages of a future bundle are checked against the armed state now and again on delivery.
+func (osc *OSCServer) admit(element OSCElement, isScheduled bool) OSCElement {
+  switch t := element.(type) {
+    case *OSCPacket:
+      osc.subscribersMux.RLock()
+      methods := osc.dispatcher.Match(t.Path)
+      guard := osc.guard
+      osc.subscribersMux.RUnlock()
+
+      if len(methods) == 0 {
+        if osc.debugFlag == true {
+          log.Printf("[OSCServer DEBUG] No method matches %s\n", t.Path)
+        }
+        return nil
+      }
+      if guard == nil {
+        return t
+      }
+      if methods = guard.Admit(t, methods); len(methods) == 0 {
+        return nil
+      }
+      if isScheduled == true && len(guard.Armed(t, methods)) < len(methods) {
+        return nil
+      }
+      return t
+
+    case *OSCBundle:
+      isScheduled = isScheduled || t.Timetag.Delay() > 0
+      elements := t.Elements[:0]
+      for _, element := range t.Elements {
+        if element = osc.admit(element, isScheduled); element != nil {
+          elements = append(elements, element)
+        }
+      }
+      if len(elements) == 0 {
+        return nil
+      }
+      t.Elements = elements
+      return t
+  }
+  return nil
+}
+
+// dispatchBundle delivers bundle messages at once, a future timetag schedules the delivery
+func (osc *OSCServer) dispatchBundle(bundle *OSCBundle) {
+  if delay := bundle.Timetag.Delay(); delay > 0 {
+    if osc.debugFlag == true {
+      log.Printf("[OSCServer DEBUG] Bundle is scheduled in %s\n", delay)
+    }
+    osc.schedule(delay, func() {
+      osc.deliver(osc.bundleMessages(bundle))
+    })
+    return
+  }
+  osc.deliver(osc.bundleMessages(bundle))
+}
+
+// bundleMessages flattens due messages of the bundle, nested bundles with later timetags are dispatched separately
+func (osc *OSCServer) bundleMessages(bundle *OSCBundle) []*OSCPacket {
+  var messages []*OSCPacket
+  for _, element := range bundle.Elements {
+    switch t := element.(type) {
+      case *OSCPacket:
+        messages = append(messages, t)
+      case *OSCBundle:
+        if t.Timetag.Delay() > 0 {
+          osc.dispatchBundle(t)
+          continue
+        }
+        messages = append(messages, osc.bundleMessages(t)...)
     }
-    osc.subscribersMux.RUnlock()
   }
+  return messages
+}
+
+func (osc *OSCServer) deliver(messages []*OSCPacket) {
+  if len(messages) == 0 {
+    return
+  }
+
+  osc.dispatchMux.Lock()
+  defer osc.dispatchMux.Unlock()
+
+  osc.subscribersMux.RLock()
+  defer osc.subscribersMux.RUnlock()
+
+  for _, oscPacket := range messages {
+    methods := osc.dispatcher.Match(oscPacket.Path)
+    if len(methods) == 0 {
+      if osc.debugFlag == true {
+        log.Printf("[OSCServer DEBUG] No method matches %s\n", oscPacket.Path)
+      }
+      continue
+    }
+    if osc.guard != nil {
+      methods = osc.guard.Filter(oscPacket, methods)
+    }
+    for _, method := range methods {
+      method.Handler(oscPacket)
+    }
+  }
+}
+
+// schedule runs fn after delay, a delay over maxDelay or a full timers map drops it
+func (osc *OSCServer) schedule(delay time.Duration, fn func()) {
+  osc.timersMux.Lock()
+  defer osc.timersMux.Unlock()
+
+  if osc.isShutdown.Load() == true {
+    return
+  }
+  if delay > osc.maxDelay {
+    osc.dropped.Add(1)
+    log.Printf("[OSCServer WARNING] Bundle in %s is over the %s maximum delay, discarding bundle\n", delay, osc.maxDelay)
+    return
+  }
+  if len(osc.timers) >= OSCServer_MaxScheduled {
+    osc.dropped.Add(1)
+    log.Printf("[OSCServer WARNING] %d bundles are already scheduled, discarding bundle\n", len(osc.timers))
+    return
+  }
+
+  var timer *time.Timer
+  timer = time.AfterFunc(delay, func() {
+    osc.timersMux.Lock()
+    _, ok := osc.timers[timer]
+    delete(osc.timers, timer)
+    osc.timersMux.Unlock()
+
+    if ok == true {
+      fn()
+    }
+  })
+  osc.timers[timer] = struct{}{}
+}
+
+func (osc *OSCServer) cancelScheduled() {
+  osc.timersMux.Lock()
+  defer osc.timersMux.Unlock()
+
+  for timer := range osc.timers {
+    timer.Stop()
+  }
+  osc.timers = make(map[*time.Timer]struct{})
 }
 
 func (osc *OSCServer) ListenAndServe() error {
@@ -97,44 +285,65 @@ func (osc *OSCServer) ListenAndServe() error {
   }
   osc.conn = conn
 
-  osc.wg.Add(1)
+  osc.readersWg.Add(1)
   go osc.serve()
 
   osc.wg.Add(1)
   go osc.distributePackets()
 
+  osc.wg.Add(1)
+  go osc.processReplyClients()
+
   log.Printf("[OSCServer INFO] Server start successfully at %s\n", osc.addr.String())
   return nil
 }
 
 func (osc *OSCServer) Shutdown() {
-  osc.isShutdown = true
+  osc.timersMux.Lock()
+  osc.isShutdown.Store(true)
+  osc.timersMux.Unlock()
+
   osc.conn.Close()
+  osc.shutdownTCP()
+  osc.readersWg.Wait()
+
   osc.closePackets()
+  close(osc.doneChan)
   osc.wg.Wait()
+  osc.cancelScheduled()
+  osc.shutdownReplyClients()
   log.Printf("[OSCServer INFO] Server shutdown successfully\n")
 }
 
 func (osc *OSCServer) UnSubscribeAll() {
   osc.subscribersMux.Lock()
-  osc.subscribers = make([]OSCListener, 0)
+  osc.dispatcher = NewOSCDispatcher()
   osc.subscribersMux.Unlock()
 }
 
 func (osc *OSCServer) UnSubscribe(listener OSCListener) {
-  for i, other := range osc.subscribers {
-    if other == listener {
-      osc.subscribersMux.Lock()
-      osc.subscribers = append(osc.subscribers[:i], osc.subscribers[i+1:]...)
-      osc.subscribersMux.Unlock()
-      return
-    }
-  }
+  osc.subscribersMux.Lock()
+  osc.dispatcher.Remove(listener)
+  osc.subscribersMux.Unlock()
+}
+
+// SetGuard checks received packets with guard and filters them again on delivery, nil accepts all packets
+func (osc *OSCServer) SetGuard(guard *OSCGuard) {
+  osc.subscribersMux.Lock()
+  osc.guard = guard
+  osc.subscribersMux.Unlock()
+}
+
+// SetBundleMaxDelay limits how far ahead a bundle timetag may be scheduled
+func (osc *OSCServer) SetBundleMaxDelay(delay time.Duration) {
+  osc.timersMux.Lock()
+  osc.maxDelay = delay
+  osc.timersMux.Unlock()
 }
 
+// Subscribe registers listener methods, a repeated call refreshes them
 func (osc *OSCServer) Subscribe(listener OSCListener) {
-  osc.UnSubscribe(listener)
   osc.subscribersMux.Lock()
-  osc.subscribers = append(osc.subscribers, listener)
+  osc.dispatcher.Add(listener)
   osc.subscribersMux.Unlock()
 }
@@ -0,0 +1,167 @@
+package main
+
+import (
+  "bufio"
+  "bytes"
+  "encoding/binary"
+  "fmt"
+  "io"
+  "strings"
+)
+
+const (
+  OSCStream_MaxFrame = 1 << 20
+
+  // SLIP special bytes, RFC 1055
+  slipEND    = 0xC0
+  slipESC    = 0xDB
+  slipESCEND = 0xDC
+  slipESCESC = 0xDD
+)
+
+type OSCFraming string
+
+const (
+  OSCFraming_SLIP   OSCFraming = "slip"   // OSC 1.1 stream framing
+  OSCFraming_Length OSCFraming = "length" // OSC 1.0 stream framing, int32 size prefix
+)
+
+func (f *OSCFraming) String() string {
+  return string(*f)
+}
+
+func (f *OSCFraming) Set(s string) error {
+  switch OSCFraming(s) {
+    case OSCFraming_SLIP, OSCFraming_Length:
+      *f = OSCFraming(s)
+      return nil
+  }
+  return fmt.Errorf("incorrect OSC framing %q, must be %s or %s", s, OSCFraming_SLIP, OSCFraming_Length)
+}
+
+// OSCAddress is a parsed OSC destination: "host:port" and "udp://host:port" are UDP,
+// "tcp://host:port" is TCP with SLIP framing, "tcp+length://host:port" is TCP with size prefix framing
+type OSCAddress struct {
+  Network string
+  Host    string
+  Framing OSCFraming
+}
+
+func ParseOSCAddress(address string) (*OSCAddress, error) {
+  scheme, host, ok := strings.Cut(address, "://")
+  if ok == false {
+    return &OSCAddress{Network: "udp", Host: address}, nil
+  }
+
+  switch scheme {
+    case "udp":
+      return &OSCAddress{Network: "udp", Host: host}, nil
+    case "tcp", "tcp+slip":
+      return &OSCAddress{Network: "tcp", Host: host, Framing: OSCFraming_SLIP}, nil
+    case "tcp+length":
+      return &OSCAddress{Network: "tcp", Host: host, Framing: OSCFraming_Length}, nil
+  }
+  return nil, fmt.Errorf("Unsupported OSC address scheme %q", scheme)
+}
+
+func (a *OSCAddress) String() string {
+  switch {
+    case a.Network == "udp":
+      return "udp://" + a.Host
+    case a.Framing == OSCFraming_Length:
+      return "tcp+length://" + a.Host
+  }
+  return "tcp://" + a.Host
+}
+
+func oscEncodeFrame(framing OSCFraming, data []byte) []byte {
+  frame := new(bytes.Buffer)
+
+  if framing == OSCFraming_Length {
+    binary.Write(frame, binary.BigEndian, int32(len(data)))
+    frame.Write(data)
+    return frame.Bytes()
+  }
+
+  // Leading END flushes line noise accumulated on the receiver
+  frame.WriteByte(slipEND)
+  for _, b := range data {
+    switch b {
+      case slipEND:
+        frame.Write([]byte{slipESC, slipESCEND})
+      case slipESC:
+        frame.Write([]byte{slipESC, slipESCESC})
+      default:
+        frame.WriteByte(b)
+    }
+  }
+  frame.WriteByte(slipEND)
+  return frame.Bytes()
+}
+
+type oscFrameReader struct {
+  reader  *bufio.Reader
+  framing OSCFraming
+}
+
+func newOSCFrameReader(reader io.Reader, framing OSCFraming) *oscFrameReader {
+  return &oscFrameReader{
+    reader:  bufio.NewReader(reader),
+    framing: framing,
+  }
+}
+
+func (r *oscFrameReader) ReadFrame() ([]byte, error) {
+  if r.framing == OSCFraming_Length {
+    var size int32
+    if err := binary.Read(r.reader, binary.BigEndian, &size); err != nil {
+      return nil, err
+    }
+    if size <= 0 || size > OSCStream_MaxFrame {
+      return nil, fmt.Errorf("Incorrect OSC frame size %d", size)
+    }
+    frame := make([]byte, size)
+    if _, err := io.ReadFull(r.reader, frame); err != nil {
+      return nil, err
+    }
+    return frame, nil
+  }
+
+  frame := make([]byte, 0, 256)
+  var isEscape bool = false
+  for {
+    b, err := r.reader.ReadByte()
+    if err != nil {
+      return nil, err
+    }
+
+    switch {
+      case isEscape == true:
+        isEscape = false
+        switch b {
+          case slipESCEND:
+            b = slipEND
+          case slipESCESC:
+            b = slipESC
+          default:
+            return nil, fmt.Errorf("Incorrect SLIP escape 0x%02X", b)
+        }
+
+      case b == slipESC:
+        isEscape = true
+        continue
+
+      case b == slipEND:
+        // Empty frames between END bytes are skipped
+        if len(frame) > 0 {
+          return frame, nil
+        }
+        continue
+    }
+
+    if len(frame) >= OSCStream_MaxFrame {
+      return nil, fmt.Errorf("OSC frame exceeds %d bytes", OSCStream_MaxFrame)
+    }
+    frame = append(frame, b)
+  }
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+  "bytes"
+  "errors"
+  "io"
+  "strings"
+  "testing"
+)
+
+func TestOSCEncodeFrame(t *testing.T) {
+  tests := []struct {
+    name    string
+    framing OSCFraming
+    data    []byte
+    want    []byte
+  }{
+    {"slip plain", OSCFraming_SLIP, []byte{0x01, 0x02}, []byte{slipEND, 0x01, 0x02, slipEND}},
+    {"slip end", OSCFraming_SLIP, []byte{slipEND}, []byte{slipEND, slipESC, slipESCEND, slipEND}},
+    {"slip esc", OSCFraming_SLIP, []byte{slipESC}, []byte{slipEND, slipESC, slipESCESC, slipEND}},
+    {"slip escaped bytes", OSCFraming_SLIP, []byte{slipESCEND, slipESCESC}, []byte{slipEND, slipESCEND, slipESCESC, slipEND}},
+    {"length", OSCFraming_Length, []byte{0x01, 0x02, 0x03}, []byte{0x00, 0x00, 0x00, 0x03, 0x01, 0x02, 0x03}},
+    {"length empty", OSCFraming_Length, []byte{}, []byte{0x00, 0x00, 0x00, 0x00}},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      if got := oscEncodeFrame(test.framing, test.data); bytes.Equal(got, test.want) == false {
+        t.Errorf("oscEncodeFrame() = % X, want % X", got, test.want)
+      }
+    })
+  }
+}
+
+func TestOSCFrameReaderRoundTrip(t *testing.T) {
+  frames := [][]byte{
+    []byte("/bot/0\x00\x00,i\x00\x00\x00\x00\x00\x01"),
+    {slipEND, slipESC, slipESCEND, slipESCESC},
+    {0x00},
+  }
+
+  for _, framing := range []OSCFraming{OSCFraming_SLIP, OSCFraming_Length} {
+    t.Run(string(framing), func(t *testing.T) {
+      stream := new(bytes.Buffer)
+      for _, frame := range frames {
+        stream.Write(oscEncodeFrame(framing, frame))
+      }
+
+      reader := newOSCFrameReader(stream, framing)
+      for i, want := range frames {
+        got, err := reader.ReadFrame()
+        if err != nil {
+          t.Fatalf("ReadFrame() %d error: %v", i, err)
+        }
+        if bytes.Equal(got, want) == false {
+          t.Errorf("ReadFrame() %d = % X, want % X", i, got, want)
+        }
+      }
+      if _, err := reader.ReadFrame(); errors.Is(err, io.EOF) == false {
+        t.Errorf("ReadFrame() after last frame error = %v, want EOF", err)
+      }
+    })
+  }
+}
+
+func TestOSCFrameReaderSLIP(t *testing.T) {
+  tests := []struct {
+    name    string
+    stream  []byte
+    want    [][]byte
+    wantErr string
+  }{
+    {"empty frames are skipped", []byte{slipEND, slipEND, 0x01, slipEND, slipEND}, [][]byte{{0x01}}, ""},
+    {"frame without leading end", []byte{0x01, 0x02, slipEND}, [][]byte{{0x01, 0x02}}, ""},
+    {"two frames", []byte{0x01, slipEND, 0x02, slipEND}, [][]byte{{0x01}, {0x02}}, ""},
+    {"incorrect escape", []byte{slipEND, slipESC, 0x01, slipEND}, nil, "Incorrect SLIP escape 0x01"},
+    {"unterminated frame", []byte{slipEND, 0x01, 0x02}, nil, "EOF"},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      reader := newOSCFrameReader(bytes.NewReader(test.stream), OSCFraming_SLIP)
+      for i, want := range test.want {
+        got, err := reader.ReadFrame()
+        if err != nil {
+          t.Fatalf("ReadFrame() %d error: %v", i, err)
+        }
+        if bytes.Equal(got, want) == false {
+          t.Errorf("ReadFrame() %d = % X, want % X", i, got, want)
+        }
+      }
+      if test.wantErr == "" {
+        return
+      }
+      if _, err := reader.ReadFrame(); err == nil || strings.Contains(err.Error(), test.wantErr) == false {
+        t.Errorf("ReadFrame() error = %v, want %q", err, test.wantErr)
+      }
+    })
+  }
+}
+
+func TestOSCFrameReaderSLIPMaxFrame(t *testing.T) {
+  stream := append(bytes.Repeat([]byte{0x01}, OSCStream_MaxFrame + 1), slipEND)
+  reader := newOSCFrameReader(bytes.NewReader(stream), OSCFraming_SLIP)
+  if _, err := reader.ReadFrame(); err == nil || strings.Contains(err.Error(), "exceeds") == false {
+    t.Errorf("ReadFrame() error = %v, want frame size error", err)
+  }
+}
+
+func TestOSCFrameReaderLength(t *testing.T) {
+  tests := []struct {
+    name    string
+    stream  []byte
+    want    []byte
+    wantErr string
+  }{
+    {"frame", []byte{0x00, 0x00, 0x00, 0x02, 0x01, 0x02}, []byte{0x01, 0x02}, ""},
+    {"zero size", []byte{0x00, 0x00, 0x00, 0x00}, nil, "Incorrect OSC frame size 0"},
+    {"negative size", []byte{0xFF, 0xFF, 0xFF, 0xFF}, nil, "Incorrect OSC frame size -1"},
+    {"size over max", []byte{0x00, 0x10, 0x00, 0x01}, nil, "Incorrect OSC frame size 1048577"},
+    {"truncated size", []byte{0x00, 0x00}, nil, "EOF"},
+    {"truncated frame", []byte{0x00, 0x00, 0x00, 0x04, 0x01}, nil, "EOF"},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      got, err := newOSCFrameReader(bytes.NewReader(test.stream), OSCFraming_Length).ReadFrame()
+      if test.wantErr != "" {
+        if err == nil || strings.Contains(err.Error(), test.wantErr) == false {
+          t.Errorf("ReadFrame() error = %v, want %q", err, test.wantErr)
+        }
+        return
+      }
+      if err != nil {
+        t.Fatalf("ReadFrame() error: %v", err)
+      }
+      if bytes.Equal(got, test.want) == false {
+        t.Errorf("ReadFrame() = % X, want % X", got, test.want)
+      }
+    })
+  }
+}
+
+func TestParseOSCAddress(t *testing.T) {
+  tests := []struct {
+    address string
+    want    OSCAddress
+    wantErr bool
+  }{
+    {"127.0.0.1:9000", OSCAddress{Network: "udp", Host: "127.0.0.1:9000"}, false},
+    {"udp://127.0.0.1:9000", OSCAddress{Network: "udp", Host: "127.0.0.1:9000"}, false},
+    {"tcp://127.0.0.1:9000", OSCAddress{Network: "tcp", Host: "127.0.0.1:9000", Framing: OSCFraming_SLIP}, false},
+    {"tcp+slip://127.0.0.1:9000", OSCAddress{Network: "tcp", Host: "127.0.0.1:9000", Framing: OSCFraming_SLIP}, false},
+    {"tcp+length://127.0.0.1:9000", OSCAddress{Network: "tcp", Host: "127.0.0.1:9000", Framing: OSCFraming_Length}, false},
+    {"ws://127.0.0.1:9000", OSCAddress{}, true},
+  }
+
+  for _, test := range tests {
+    t.Run(test.address, func(t *testing.T) {
+      got, err := ParseOSCAddress(test.address)
+      if test.wantErr == true {
+        if err == nil {
+          t.Errorf("ParseOSCAddress() = %+v, want error", got)
+        }
+        return
+      }
+      if err != nil {
+        t.Fatalf("ParseOSCAddress() error: %v", err)
+      }
+      if *got != test.want {
+        t.Errorf("ParseOSCAddress() = %+v, want %+v", *got, test.want)
+      }
+    })
+  }
+}
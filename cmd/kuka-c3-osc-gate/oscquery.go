@@ -0,0 +1,538 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "fmt"
+  "log"
+  "net/http"
+  "sort"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  OSCQuery_Name           = execName
+  OSCQuery_ListenerBuffer = 64
+
+  OSCQueryAccess_None      = 0
+  OSCQueryAccess_Read      = 1
+  OSCQueryAccess_Write     = 2
+  OSCQueryAccess_ReadWrite = 3
+)
+
+type OSCQueryRange struct {
+  Min  *float64 `json:"MIN,omitempty"`
+  Max  *float64 `json:"MAX,omitempty"`
+  Vals []any    `json:"VALS,omitempty"`
+}
+
+type OSCQueryNode struct {
+  FullPath    string                   `json:"FULL_PATH"`
+  Contents    map[string]*OSCQueryNode `json:"CONTENTS,omitempty"`
+  Type        string                   `json:"TYPE,omitempty"`
+  Access      *int                     `json:"ACCESS,omitempty"`
+  Value       []any                    `json:"VALUE,omitempty"`
+  Range       []*OSCQueryRange         `json:"RANGE,omitempty"`
+  Description string                   `json:"DESCRIPTION,omitempty"`
+}
+
+func NewOSCQueryMethod(path string, typeTags string, access int, description string) *OSCQueryNode {
+  return &OSCQueryNode{
+    FullPath:    path,
+    Type:        typeTags,
+    Access:      &access,
+    Description: description,
+  }
+}
+
+// OSCQueryServer serves the OSCQuery namespace of the team and streams sent values to LISTEN clients.
+// There is no mDNS, controllers are pointed to http://<host>:<port>/ by hand.
+type OSCQueryServer struct {
+  botsTeam *Team
+  oscPort  PortValue
+  server   *http.Server
+
+  values    map[string][]any
+  valuesMux sync.RWMutex
+
+  listeners    map[*oscQueryListener]struct{}
+  listenersMux sync.RWMutex
+}
+
+type oscQueryListener struct {
+  ws      *WebSocket
+  paths   map[string]struct{}
+  pathMux sync.RWMutex
+  packets chan []byte
+}
+
+type oscQueryCommand struct {
+  Command string `json:"COMMAND"`
+  Data    any    `json:"DATA"`
+}
+
+func NewOSCQueryServer(port PortValue, oscPort PortValue, botsTeam *Team) *OSCQueryServer {
+  oscQuery := &OSCQueryServer{
+    botsTeam:  botsTeam,
+    oscPort:   oscPort,
+    values:    make(map[string][]any),
+    listeners: make(map[*oscQueryListener]struct{}),
+  }
+
+  oscQuery.server = &http.Server{
+    Addr:    fmt.Sprintf(":%s", port.String()),
+    Handler: http.HandlerFunc(oscQuery.Handler),
+  }
+
+  return oscQuery
+}
+
+func (oscQuery *OSCQueryServer) ListenAndServe() error {
+  errChan := make(chan error, 1)
+
+  go func() {
+    log.Printf("[OSCQuery INFO] Listening on http://0.0.0.0%s\n", oscQuery.server.Addr)
+    if err := oscQuery.server.ListenAndServe(); err != http.ErrServerClosed {
+      errChan <- err
+    }
+    close(errChan)
+  }()
+
+  select {
+    case err := <-errChan:
+      if err != nil {
+        return err
+      }
+    case <-time.After(Service_StartEndTimeout):
+  }
+
+  oscQuery.botsTeam.AddOSCMonitor(oscQuery)
+  return nil
+}
+
+func (oscQuery *OSCQueryServer) Shutdown() error {
+  oscQuery.botsTeam.RemoveOSCMonitor(oscQuery)
+
+  ctx, cancel := context.WithTimeout(context.Background(), Service_StartEndTimeout)
+  defer cancel()
+  err := oscQuery.server.Shutdown(ctx)
+
+  // Hijacked WebSocket connections are not closed by http.Server
+  oscQuery.listenersMux.Lock()
+  for listener := range oscQuery.listeners {
+    listener.ws.Close()
+  }
+  oscQuery.listenersMux.Unlock()
+
+  if err != nil {
+    return err
+  }
+  log.Printf("[OSCQuery INFO] Server stopped\n")
+  return nil
+}
+
+// OSCOutput caches the last value of every sent path and forwards it to listeners
+func (oscQuery *OSCQueryServer) OSCOutput(oscPacket *OSCPacket) {
+  oscQuery.valuesMux.Lock()
+  oscQuery.values[oscPacket.Path] = oscPacket.values
+  oscQuery.valuesMux.Unlock()
+
+  oscQuery.listenersMux.RLock()
+  defer oscQuery.listenersMux.RUnlock()
+  if len(oscQuery.listeners) == 0 {
+    return
+  }
+
+  var data []byte
+  for listener := range oscQuery.listeners {
+    if listener.isListening(oscPacket.Path) == false {
+      continue
+    }
+    if data == nil {
+      var err error
+      if data, err = oscPacket.Bytes(); err != nil {
+        log.Printf("[OSCQuery ERROR] Packet %s bytes error: %v\n", oscPacket.Path, err)
+        return
+      }
+    }
+    select {
+      case listener.packets <- data:
+      default: // Slow listener skips values instead of blocking bots
+    }
+  }
+}
+
+// Namespace builds the OSCQuery tree from the current team configuration
+func (oscQuery *OSCQueryServer) Namespace() *OSCQueryNode {
+  root := &OSCQueryNode{FullPath: "/", Contents: make(map[string]*OSCQueryNode)}
+
+  oscQuery.valuesMux.RLock()
+  defer oscQuery.valuesMux.RUnlock()
+
+  for _, method := range oscQuery.botsTeam.OSCQueryMethods() {
+    if value, ok := oscQuery.values[method.FullPath]; ok && *method.Access & OSCQueryAccess_Read != 0 {
+      method.Value = value
+    }
+
+    node := root
+    parts := strings.Split(strings.Trim(method.FullPath, "/"), "/")
+    for i, part := range parts {
+      if i == len(parts) - 1 {
+        if existing, ok := node.Contents[part]; ok && existing.Contents != nil {
+          method.Contents = existing.Contents
+        }
+        node.Contents[part] = method
+        break
+      }
+
+      child, ok := node.Contents[part]
+      if ok == false {
+        child = &OSCQueryNode{FullPath: "/" + strings.Join(parts[:i + 1], "/")}
+        node.Contents[part] = child
+      }
+      if child.Contents == nil {
+        child.Contents = make(map[string]*OSCQueryNode)
+      }
+      node = child
+    }
+  }
+
+  return root
+}
+
+func (oscQuery *OSCQueryServer) find(path string) *OSCQueryNode {
+  node := oscQuery.Namespace()
+  path = strings.Trim(path, "/")
+  if path == "" {
+    return node
+  }
+
+  for _, part := range strings.Split(path, "/") {
+    child, ok := node.Contents[part]
+    if ok == false {
+      return nil
+    }
+    node = child
+  }
+  return node
+}
+
+func (oscQuery *OSCQueryServer) hostInfo() map[string]any {
+  return map[string]any{
+    "NAME":          OSCQuery_Name,
+    "OSC_PORT":      int(oscQuery.oscPort),
+    "OSC_TRANSPORT": "UDP",
+    "EXTENSIONS": map[string]bool{
+      "ACCESS":       true,
+      "VALUE":        true,
+      "RANGE":        true,
+      "DESCRIPTION":  true,
+      "TYPE":         true,
+      "LISTEN":       true,
+      "PATH_CHANGED": false,
+    },
+  }
+}
+
+func (oscQuery *OSCQueryServer) Handler(w http.ResponseWriter, r *http.Request) {
+  if IsWebSocketRequest(r) {
+    oscQuery.serveWebSocket(w, r)
+    return
+  }
+
+  if r.Method != "GET" {
+    http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+    return
+  }
+
+  var response any
+  attribute := r.URL.RawQuery
+
+  if attribute == "HOST_INFO" {
+    response = oscQuery.hostInfo()
+  } else {
+    node := oscQuery.find(r.URL.Path)
+    if node == nil {
+      http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+      return
+    }
+
+    response = node
+    if attribute != "" {
+      value, ok := node.attribute(attribute)
+      if ok == false {
+        w.WriteHeader(http.StatusNoContent)
+        return
+      }
+      response = map[string]any{attribute: value}
+    }
+  }
+
+  w.Header().Set("Content-Type", "application/json; charset=utf-8")
+  w.WriteHeader(http.StatusOK)
+  if err := json.NewEncoder(w).Encode(response); err != nil {
+    log.Printf("[OSCQuery ERROR] Response json error: %v\n", err)
+  }
+}
+
+func (node *OSCQueryNode) attribute(name string) (any, bool) {
+  switch name {
+    case "FULL_PATH":
+      return node.FullPath, true
+    case "CONTENTS":
+      return node.Contents, node.Contents != nil
+    case "TYPE":
+      return node.Type, node.Type != ""
+    case "ACCESS":
+      return node.Access, node.Access != nil
+    case "VALUE":
+      return node.Value, node.Value != nil
+    case "RANGE":
+      return node.Range, node.Range != nil
+    case "DESCRIPTION":
+      return node.Description, node.Description != ""
+  }
+  return nil, false
+}
+
+func (oscQuery *OSCQueryServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+  ws, err := UpgradeWebSocket(w, r)
+  if err != nil {
+    log.Printf("[OSCQuery ERROR] WebSocket upgrade error: %v\n", err)
+    return
+  }
+
+  listener := &oscQueryListener{
+    ws:      ws,
+    paths:   make(map[string]struct{}),
+    packets: make(chan []byte, OSCQuery_ListenerBuffer),
+  }
+
+  oscQuery.listenersMux.Lock()
+  oscQuery.listeners[listener] = struct{}{}
+  oscQuery.listenersMux.Unlock()
+
+  done := make(chan struct{})
+  go func() {
+    for {
+      select {
+        case <-done:
+          return
+        case data := <-listener.packets:
+          if err := ws.WriteMessage(WebSocket_Binary, data); err != nil {
+            ws.Close()
+            return
+          }
+      }
+    }
+  }()
+
+  defer func() {
+    oscQuery.listenersMux.Lock()
+    delete(oscQuery.listeners, listener)
+    oscQuery.listenersMux.Unlock()
+    close(done)
+    ws.Close()
+  }()
+
+  for {
+    opcode, message, err := ws.ReadMessage()
+    if err != nil {
+      return
+    }
+    if opcode != WebSocket_Text {
+      continue
+    }
+
+    var command oscQueryCommand
+    if err := json.Unmarshal(message, &command); err != nil {
+      log.Printf("[OSCQuery ERROR] WebSocket command error: %v\n", err)
+      continue
+    }
+
+    path, _ := command.Data.(string)
+    switch command.Command {
+      case "LISTEN":
+        listener.listen(path, true)
+      case "IGNORE":
+        listener.listen(path, false)
+      default:
+        log.Printf("[OSCQuery WARNING] WebSocket unsupported command %q\n", command.Command)
+    }
+  }
+}
+
+func (listener *oscQueryListener) listen(path string, isListening bool) {
+  listener.pathMux.Lock()
+  defer listener.pathMux.Unlock()
+  if isListening {
+    listener.paths[path] = struct{}{}
+  } else {
+    delete(listener.paths, path)
+  }
+}
+
+func (listener *oscQueryListener) isListening(path string) bool {
+  listener.pathMux.RLock()
+  defer listener.pathMux.RUnlock()
+  _, ok := listener.paths[path]
+  return ok
+}
+
+// OSCQueryMethods describes every OSC path the team and its bots receive or send
+func (team *Team) OSCQueryMethods() []*OSCQueryNode {
+  var methods []*OSCQueryNode
+
+  moveGroupIds := make(map[uint16]struct{})
+  for _, bot := range team.GetBots() {
+    bot.moveGroupsMux.RLock()
+    for _, moveGroup := range bot.MoveGroups {
+      moveGroupIds[moveGroup.Id] = struct{}{}
+    }
+    bot.moveGroupsMux.RUnlock()
+  }
+  ids := make([]any, 0, len(moveGroupIds))
+  for id := range moveGroupIds {
+    ids = append(ids, id)
+  }
+  sort.Slice(ids, func(i, j int) bool { return ids[i].(uint16) < ids[j].(uint16) })
+
+  minSpeed, maxSpeed := float64(0), float64(100)
+  positionRange := []*OSCQueryRange{{Vals: ids}, {Min: &minSpeed, Max: &maxSpeed}, {}}
+  statusRange := []*OSCQueryRange{{Vals: []any{OSCOutputStatus_OK, OSCOutputStatus_Break, OSCOutputStatus_Error}}, {}, {Vals: ids}}
+
+  team.settingsMux.RLock()
+  if team.OSCRequestPosition != nil {
+    method := NewOSCQueryMethod(*team.OSCRequestPosition, "iii", OSCQueryAccess_Write, "Run MoveGroup on all bots: id, speed, index")
+    method.Range = positionRange
+    methods = append(methods, method)
+  }
+
+  if team.OSCResponsePosition != nil {
+    method := NewOSCQueryMethod(*team.OSCResponsePosition, "iii", OSCQueryAccess_Read, "Team MoveGroup result: status, index, id")
+    method.Range = statusRange
+    methods = append(methods, method)
+  }
+
+  if team.OSCRequestTimeline != nil {
+    for _, command := range []TimelineCommand{
+      TimelineCommand_Go, TimelineCommand_Back, TimelineCommand_Jump, TimelineCommand_Play, TimelineCommand_Stop,
+    } {
+      names := make([]any, 0, len(team.Timelines))
+      for _, timeline := range team.Timelines {
+        names = append(names, timeline.Name)
+      }
+      method := NewOSCQueryMethod(*team.OSCRequestTimeline + "/" + string(command), "s", OSCQueryAccess_Write, fmt.Sprintf("Timeline %s: timeline [, cue]", command))
+      method.Range = []*OSCQueryRange{{Vals: names}}
+      methods = append(methods, method)
+    }
+  }
+
+  if team.OSCRequestTeach != nil {
+    methods = append(methods, NewOSCQueryMethod(*team.OSCRequestTeach, "si", OSCQueryAccess_Write, "Teach live pose: bot, MoveGroup id [, source axis|pos|position] [, index]"))
+    methods = append(methods, NewOSCQueryMethod(*team.OSCRequestTeach + "/undo", "s", OSCQueryAccess_Write, "Undo last teach: bot"))
+  }
+  team.settingsMux.RUnlock()
+
+  if team.Timecode != nil && team.Timecode.OSCPath != nil {
+    methods = append(methods, NewOSCQueryMethod(*team.Timecode.OSCPath, "s", OSCQueryAccess_Write, "Timecode hh:mm:ss:ff"))
+  }
+
+  if team.Events != nil {
+    if path := team.Events.errorPath(); path != "" {
+      method := NewOSCQueryMethod(path, "iss", OSCQueryAccess_Read, "Gate error: code, bot, message")
+      codes := make([]any, 0, len(GateEvents_Codes))
+      for kind := range GateEvents_Codes {
+        codes = append(codes, team.Events.Code(kind))
+      }
+      sort.Slice(codes, func(i, j int) bool { return codes[i].(int32) < codes[j].(int32) })
+      method.Range = []*OSCQueryRange{{Vals: codes}, {}, {}}
+      methods = append(methods, method)
+    }
+    if path := team.Events.eventPath(); path != "" {
+      method := NewOSCQueryMethod(path, "ss", OSCQueryAccess_Read, "Gate event: type, bot [, MoveGroup id]")
+      types := make([]any, len(GateEvents_Types))
+      for i, eventType := range GateEvents_Types {
+        types[i] = string(eventType)
+      }
+      method.Range = []*OSCQueryRange{{Vals: types}, {}}
+      methods = append(methods, method)
+    }
+  }
+
+  for _, bot := range team.GetBots() {
+    bot.mappingMux.Lock()
+    bot.settingsMux.RLock()
+    if bot.OSCRequestAxis != nil {
+      method := NewOSCQueryMethod(*bot.OSCRequestAxis, "ffffff", OSCQueryAccess_Write, fmt.Sprintf("Bot %s move to A1..A6", bot.Name))
+      method.Range = oscQueryAxisRange(bot.Limits)
+      methods = append(methods, method)
+    }
+    if bot.OSCRequestCoords != nil {
+      method := NewOSCQueryMethod(*bot.OSCRequestCoords, "ffffff", OSCQueryAccess_Write, fmt.Sprintf("Bot %s move to X, Y, Z, A, B, C", bot.Name))
+      method.Range = oscQueryCoordsRange(bot.Limits)
+      methods = append(methods, method)
+    }
+    if bot.OSCRequestPosition != nil {
+      method := NewOSCQueryMethod(*bot.OSCRequestPosition, "iii", OSCQueryAccess_Write, fmt.Sprintf("Bot %s run MoveGroup: id, speed, index", bot.Name))
+      method.Range = positionRange
+      methods = append(methods, method)
+    }
+    for _, path := range bot.mappingPaths {
+      mappings := bot.mappingGroups[path]
+      var count int = 0
+      targets := make([]string, 0, len(mappings))
+      for _, mapping := range mappings {
+        count = max(count, mapping.Argument + 1)
+        targets = append(targets, mapping.Target)
+      }
+      methods = append(methods, NewOSCQueryMethod(path, strings.Repeat("f", count), OSCQueryAccess_Write, fmt.Sprintf("Bot %s mapped to %s", bot.Name, strings.Join(targets, ", "))))
+    }
+    if bot.OSCResponseAxes != nil {
+      methods = append(methods, NewOSCQueryMethod(*bot.OSCResponseAxes, "ffffff", OSCQueryAccess_Read, fmt.Sprintf("Bot %s current A1..A6", bot.Name)))
+    }
+    if bot.OSCResponseCoords != nil {
+      methods = append(methods, NewOSCQueryMethod(*bot.OSCResponseCoords, "ffffff", OSCQueryAccess_Read, fmt.Sprintf("Bot %s current X, Y, Z, A, B, C", bot.Name)))
+    }
+    if bot.OSCResponsePosition != nil {
+      method := NewOSCQueryMethod(*bot.OSCResponsePosition, "iii", OSCQueryAccess_Read, fmt.Sprintf("Bot %s MoveGroup result: status, index, id", bot.Name))
+      method.Range = statusRange
+      methods = append(methods, method)
+    }
+    bot.settingsMux.RUnlock()
+    bot.mappingMux.Unlock()
+  }
+
+  return methods
+}
+
+// oscQueryAxisRange reports A1..A6 limits, an axis without limit has an empty range
+func oscQueryAxisRange(limits *BotLimits) []*OSCQueryRange {
+  ranges := make([]*OSCQueryRange, 6)
+  for i := range ranges {
+    ranges[i] = &OSCQueryRange{}
+    if limits == nil {
+      continue
+    }
+    if limit, ok := limits.Axes[fmt.Sprintf("A%d", i + 1)]; ok == true {
+      min, max := float64(limit[0]), float64(limit[1])
+      ranges[i].Min, ranges[i].Max = &min, &max
+    }
+  }
+  return ranges
+}
+
+// oscQueryCoordsRange reports X, Y and Z within the reach, A, B and C have no limits
+func oscQueryCoordsRange(limits *BotLimits) []*OSCQueryRange {
+  ranges := make([]*OSCQueryRange, 6)
+  for i := range ranges {
+    ranges[i] = &OSCQueryRange{}
+    if i < 3 && limits != nil && limits.Reach != nil {
+      min, max := -float64(*limits.Reach), float64(*limits.Reach)
+      ranges[i].Min, ranges[i].Max = &min, &max
+    }
+  }
+  return ranges
+}
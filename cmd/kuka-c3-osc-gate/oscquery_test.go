@@ -0,0 +1,41 @@
+package main
+
+import (
+  "testing"
+)
+
+func TestOSCQueryMoveRange(t *testing.T) {
+  reach := float32(1500)
+  limits := &BotLimits{Axes: map[string][2]float32{"A1": {-170, 170}, "A5": {-120, 120}, "E1": {0, 3000}}, Reach: &reach}
+
+  tests := []struct {
+    name   string
+    ranges []*OSCQueryRange
+    want   [6]*[2]float64
+  }{
+    {"axis limits", oscQueryAxisRange(limits), [6]*[2]float64{{-170, 170}, nil, nil, nil, {-120, 120}, nil}},
+    {"axis without limits", oscQueryAxisRange(nil), [6]*[2]float64{}},
+    {"coords reach", oscQueryCoordsRange(limits), [6]*[2]float64{{-1500, 1500}, {-1500, 1500}, {-1500, 1500}, nil, nil, nil}},
+    {"coords without reach", oscQueryCoordsRange(&BotLimits{}), [6]*[2]float64{}},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      if len(test.ranges) != 6 {
+        t.Fatalf("ranges length = %d, want 6", len(test.ranges))
+      }
+      for i, want := range test.want {
+        got := test.ranges[i]
+        if want == nil {
+          if got.Min != nil || got.Max != nil {
+            t.Errorf("ranges[%d] = %v..%v, want no range", i, got.Min, got.Max)
+          }
+          continue
+        }
+        if got.Min == nil || got.Max == nil || *got.Min != want[0] || *got.Max != want[1] {
+          t.Errorf("ranges[%d] = %v..%v, want %v", i, got.Min, got.Max, *want)
+        }
+      }
+    })
+  }
+}
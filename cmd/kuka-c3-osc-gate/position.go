@@ -64,15 +64,18 @@ func (p Position) MarshalJSON() ([]byte, error) {
 }
 
 func (p *Position) UnmarshalJSON(input []byte) error {
-  var data [15]interface{}
+  var data []float64
   if err := json.Unmarshal(input, &data); err != nil {
-    return err
+    return fmt.Errorf("Position must be an array of numbers: %w", err)
+  }
+  if len(data) != 15 {
+    return fmt.Errorf("Position must have type and 14 values, got %d numbers", len(data))
   }
 
-  p.valueType = PositionType(data[0].(float64))
+  p.valueType = PositionType(data[0])
 
   for i := 0; i < 14; i++ {
-    p.values[i] = float32(data[i + 1].(float64))
+    p.values[i] = float32(data[i + 1])
   }
 
   return nil
@@ -392,3 +395,33 @@ func (p *Position) Clone() *Position {
   }
   return position
 }
+
+// RotationMatrix returns row-major R = Rz(A) * Ry(B) * Rx(C) of KUKA E6POS angles in degrees
+func (p *Position) RotationMatrix() [9]float64 {
+  a := float64(p.values[3]) * math.Pi / 180
+  b := float64(p.values[4]) * math.Pi / 180
+  c := float64(p.values[5]) * math.Pi / 180
+  sa, ca := math.Sincos(a)
+  sb, cb := math.Sincos(b)
+  sc, cc := math.Sincos(c)
+
+  return [9]float64{
+    ca * cb, ca * sb * sc - sa * cc, ca * sb * cc + sa * sc,
+    sa * cb, sa * sb * sc + ca * cc, sa * sb * cc - ca * sc,
+    -sb,     cb * sc,                cb * cc,
+  }
+}
+
+// Quaternion returns W, X, Y, Z of the same rotation as RotationMatrix
+func (p *Position) Quaternion() [4]float64 {
+  sa, ca := math.Sincos(float64(p.values[3]) * math.Pi / 360)
+  sb, cb := math.Sincos(float64(p.values[4]) * math.Pi / 360)
+  sc, cc := math.Sincos(float64(p.values[5]) * math.Pi / 360)
+
+  return [4]float64{
+    ca * cb * cc + sa * sb * sc,
+    ca * cb * sc - sa * sb * cc,
+    ca * sb * cc + sa * cb * sc,
+    sa * cb * cc - ca * sb * sc,
+  }
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+  "fmt"
+  "log"
+  "net"
+  "os"
+  "strconv"
+  "time"
+)
+
+// SDNotify sends a state to systemd when the gate runs as a Type=notify service, it does nothing otherwise
+func SDNotify(state string) error {
+  socket := os.Getenv("NOTIFY_SOCKET")
+  if socket == "" {
+    return nil
+  }
+  if socket[0] == '@' {
+    socket = "\x00" + socket[1:] // Abstract namespace socket
+  }
+
+  conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+  if err != nil {
+    return fmt.Errorf("sd_notify dial error: %w", err)
+  }
+  defer conn.Close()
+
+  if _, err := conn.Write([]byte(state)); err != nil {
+    return fmt.Errorf("sd_notify write error: %w", err)
+  }
+  return nil
+}
+
+// SDWatchdogInterval is half of the systemd WatchdogSec, zero when the watchdog is not enabled for this process
+func SDWatchdogInterval() time.Duration {
+  usec, err := strconv.ParseUint(os.Getenv("WATCHDOG_USEC"), 10, 64)
+  if err != nil || usec == 0 {
+    return 0
+  }
+  if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+    return 0
+  }
+  return time.Duration(usec) * time.Microsecond / 2
+}
+
+// SDWatchdog pings the systemd watchdog while alive reports true, so a hung gate is restarted
+func SDWatchdog(alive func() bool, doneChan chan struct{}) {
+  interval := SDWatchdogInterval()
+  if interval == 0 {
+    return
+  }
+  log.Printf("[SDNotify INFO] Watchdog is enabled with %s interval\n", interval)
+
+  ticker := time.NewTicker(interval)
+  defer ticker.Stop()
+  for {
+    select {
+      case <-doneChan:
+        return
+      case <-ticker.C:
+        if alive() == false {
+          log.Printf("[SDNotify WARNING] Gate is not alive, watchdog is not notified\n")
+          continue
+        }
+        if err := SDNotify("WATCHDOG=1"); err != nil {
+          log.Printf("[SDNotify ERROR] %v\n", err)
+        }
+    }
+  }
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+  "context"
+  "crypto/sha256"
+  "crypto/subtle"
+  "encoding/hex"
+  "encoding/json"
+  "fmt"
+  "log"
+  "net/http"
+  "os"
+  "strings"
+)
+
+type ServiceRole string
+
+const (
+  ServiceRole_Viewer   ServiceRole = "viewer"   // Read state, stream and metrics
+  ServiceRole_Operator ServiceRole = "operator" // Run MoveGroups, HOME and timelines, arm OSC motion commands
+  ServiceRole_Engineer ServiceRole = "engineer" // Edit MoveGroups, teach, direct moves and config reload
+)
+
+const (
+  ServiceAuth_Realm     = "KUKA-C3-OSC-Gate"
+  ServiceAuth_Anonymous = "anonymous"
+  ServiceAuth_SHA256    = "sha256:"
+)
+
+var serviceRoleLevels = map[ServiceRole]int{
+  ServiceRole_Viewer:   1,
+  ServiceRole_Operator: 2,
+  ServiceRole_Engineer: 3,
+}
+
+// ServiceOptions are HTTP API listening and access settings
+type ServiceOptions struct {
+  IsLocal  bool         // Listen on localhost only
+  Auth     *ServiceAuth // nil allows everyone as engineer
+  CertFile string       // TLS is enabled when both files are set
+  KeyFile  string
+}
+
+// ServiceUser authenticates with a bearer token or with basic auth name and password.
+// Password is plain text or "sha256:" and a hex digest.
+type ServiceUser struct {
+  Name     string      `json:"name"`
+  Role     ServiceRole `json:"role"`
+  Token    string      `json:"token"`
+  Password string      `json:"password"`
+}
+
+type ServiceAuth struct {
+  Users []*ServiceUser `json:"users"`
+}
+
+type serviceUserKey struct{}
+
+func LoadServiceAuth(filePath string) (*ServiceAuth, error) {
+  data, err := os.ReadFile(filePath)
+  if err != nil {
+    return nil, fmt.Errorf("Auth file error: %w", err)
+  }
+
+  auth := &ServiceAuth{}
+  if err := json.Unmarshal(data, auth); err != nil {
+    return nil, fmt.Errorf("Auth file %s decode JSON error: %w", filePath, err)
+  }
+  if err := auth.Check(); err != nil {
+    return nil, fmt.Errorf("Auth file %s error: %w", filePath, err)
+  }
+  return auth, nil
+}
+
+func (auth *ServiceAuth) Check() error {
+  if len(auth.Users) == 0 {
+    return fmt.Errorf("No users are configured")
+  }
+
+  names := make(map[string]bool, len(auth.Users))
+  tokens := make(map[string]bool, len(auth.Users))
+  for i, user := range auth.Users {
+    if user.Name == "" || user.Name == ServiceAuth_Anonymous {
+      return fmt.Errorf("User %d incorrect name %q", i, user.Name)
+    }
+    if names[user.Name] == true {
+      return fmt.Errorf("User %s is duplicated", user.Name)
+    }
+    names[user.Name] = true
+
+    if _, ok := serviceRoleLevels[user.Role]; ok == false {
+      return fmt.Errorf("User %s incorrect role %q, must be %s, %s or %s", user.Name, user.Role,
+        ServiceRole_Viewer, ServiceRole_Operator, ServiceRole_Engineer)
+    }
+    if user.Token == "" && user.Password == "" {
+      return fmt.Errorf("User %s has no token or password", user.Name)
+    }
+    if user.Token != "" {
+      if tokens[user.Token] == true {
+        return fmt.Errorf("User %s token is used by another user", user.Name)
+      }
+      tokens[user.Token] = true
+    }
+    if digest, ok := strings.CutPrefix(user.Password, ServiceAuth_SHA256); ok == true {
+      if _, err := hex.DecodeString(digest); err != nil || len(digest) != sha256.Size * 2 {
+        return fmt.Errorf("User %s incorrect password digest", user.Name)
+      }
+    }
+  }
+  return nil
+}
+
+// Authenticate finds the user of a request: bearer token, token query parameter (for stream
+// clients which can not set headers) or basic auth
+func (auth *ServiceAuth) Authenticate(r *http.Request) *ServiceUser {
+  token := r.URL.Query().Get("token")
+  if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok == true {
+    token = strings.TrimSpace(bearer)
+  }
+  if token != "" {
+    for _, user := range auth.Users {
+      if user.Token != "" && subtle.ConstantTimeCompare([]byte(user.Token), []byte(token)) == 1 {
+        return user
+      }
+    }
+    return nil
+  }
+
+  name, password, ok := r.BasicAuth()
+  if ok == false {
+    return nil
+  }
+  for _, user := range auth.Users {
+    if user.Name == name && user.checkPassword(password) == true {
+      return user
+    }
+  }
+  return nil
+}
+
+func (user *ServiceUser) checkPassword(password string) bool {
+  if user.Password == "" {
+    return false
+  }
+  if digest, ok := strings.CutPrefix(user.Password, ServiceAuth_SHA256); ok == true {
+    sum := sha256.Sum256([]byte(password))
+    return subtle.ConstantTimeCompare([]byte(strings.ToLower(digest)), []byte(hex.EncodeToString(sum[:]))) == 1
+  }
+  return subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) == 1
+}
+
+func (user *ServiceUser) HasRole(role ServiceRole) bool {
+  return serviceRoleLevels[user.Role] >= serviceRoleLevels[role]
+}
+
+// serviceRequestRole is the role required by a request, probes are public
+func serviceRequestRole(r *http.Request) (ServiceRole, bool) {
+  switch r.URL.Path {
+    case Service_Healthz_API, Service_Readyz_API:
+      return "", true
+  }
+
+  switch r.Method {
+    case "GET", "HEAD", "OPTIONS":
+      return ServiceRole_Viewer, false
+  }
+
+  switch r.URL.Path {
+    case Service_Bots_API, Service_Home_API, Service_Timelines_API, Service_OSCGuardArm_API, Service_OSCGuardDisarm_API:
+      return ServiceRole_Operator, false
+  }
+  return ServiceRole_Engineer, false
+}
+
+// ServiceRequestUser returns the authenticated user of a request
+func ServiceRequestUser(r *http.Request) *ServiceUser {
+  if user, ok := r.Context().Value(serviceUserKey{}).(*ServiceUser); ok == true {
+    return user
+  }
+  return &ServiceUser{Name: ServiceAuth_Anonymous, Role: ServiceRole_Engineer}
+}
+
+// authHandler checks access of every request and audits every request which changes state
+func (service *Service) authHandler(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    role, isPublic := serviceRequestRole(r)
+
+    user := &ServiceUser{Name: ServiceAuth_Anonymous, Role: ServiceRole_Engineer}
+    if service.options.Auth != nil && isPublic == false {
+      if user = service.options.Auth.Authenticate(r); user == nil {
+        log.Printf("[Audit WARNING] Unauthenticated %s %s from %s\n", r.Method, r.URL.Path, r.RemoteAddr)
+        w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", ServiceAuth_Realm))
+        responseError(w, NewServiceError(http.StatusUnauthorized, ServiceError_Auth, "Authentication required"))
+        return
+      }
+      if user.HasRole(role) == false {
+        log.Printf("[Audit WARNING] User %s (%s) is denied %s %s from %s, %s role required\n",
+          user.Name, user.Role, r.Method, r.URL.Path, r.RemoteAddr, role)
+        responseError(w, NewServiceError(http.StatusForbidden, ServiceError_Forbidden, "Role %s is required", role))
+        return
+      }
+    }
+
+    r = r.WithContext(context.WithValue(r.Context(), serviceUserKey{}, user))
+    if role == ServiceRole_Viewer || isPublic == true {
+      // Reads are not audited, stream handlers need the original writer to hijack or flush it
+      next.ServeHTTP(w, r)
+      return
+    }
+
+    recorder := &serviceStatusRecorder{ResponseWriter: w, status: http.StatusOK}
+    next.ServeHTTP(recorder, r)
+    log.Printf("[Audit INFO] User %s (%s) from %s %s %s%s: %d\n",
+      user.Name, user.Role, r.RemoteAddr, r.Method, r.URL.Path, serviceAuditForm(r), recorder.status)
+  })
+}
+
+// serviceAuditForm lists parsed form values, secrets are never sent in forms
+func serviceAuditForm(r *http.Request) string {
+  if len(r.Form) == 0 {
+    return ""
+  }
+  values := make([]string, 0, len(r.Form))
+  for _, key := range sortedKeys(r.Form) {
+    if key == "token" {
+      continue
+    }
+    values = append(values, key + "=" + strings.Join(r.Form[key], ","))
+  }
+  return " [" + strings.Join(values, " ") + "]"
+}
+
+type serviceStatusRecorder struct {
+  http.ResponseWriter
+  status int
+}
+
+func (recorder *serviceStatusRecorder) WriteHeader(status int) {
+  recorder.status = status
+  recorder.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+  "fmt"
+  "log"
+  "net/http"
+)
+
+// ServiceErrorKind lets clients tell failures apart without parsing messages
+type ServiceErrorKind string
+
+const (
+  ServiceError_Validation ServiceErrorKind = "validation" // 400 malformed request, 422 rejected value
+  ServiceError_NotFound   ServiceErrorKind = "notFound"   // 404 bot, MoveGroup, move or position is not found
+  ServiceError_Conflict   ServiceErrorKind = "conflict"   // 409 request conflicts with the stored state
+  ServiceError_Busy       ServiceErrorKind = "busy"       // 409 bot is already moving
+  ServiceError_Robot      ServiceErrorKind = "robot"      // 502 robot rejected or timed out, 503 bot is unavailable
+  ServiceError_Auth       ServiceErrorKind = "auth"       // 401 credentials are missing or wrong
+  ServiceError_Forbidden  ServiceErrorKind = "forbidden"  // 403 user role does not allow the request
+  ServiceError_Method     ServiceErrorKind = "method"     // 405
+  ServiceError_Internal   ServiceErrorKind = "internal"   // 500
+)
+
+// ServiceError is the JSON body of every failed bot API request
+type ServiceError struct {
+  Status  int              `json:"status"`
+  Kind    ServiceErrorKind `json:"error"`
+  Message string           `json:"message"`
+}
+
+func NewServiceError(status int, kind ServiceErrorKind, format string, args ...any) *ServiceError {
+  return &ServiceError{Status: status, Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *ServiceError) Error() string {
+  return e.Message
+}
+
+// responseError writes a ServiceError, any other error is an internal error
+func responseError(w http.ResponseWriter, err error) {
+  serviceErr, ok := err.(*ServiceError)
+  if ok == false {
+    log.Printf("[Service ERROR] Internal error: %v\n", err)
+    serviceErr = NewServiceError(http.StatusInternalServerError, ServiceError_Internal, "%v", err)
+  }
+  responseJSON(w, serviceErr.Status, serviceErr)
+}
+
+func responseMethodNotAllowed(w http.ResponseWriter) {
+  responseError(w, NewServiceError(http.StatusMethodNotAllowed, ServiceError_Method, "Method not allowed"))
+}
+
+func errValidation(format string, args ...any) *ServiceError {
+  return NewServiceError(http.StatusBadRequest, ServiceError_Validation, format, args...)
+}
+
+func errNotFound(format string, args ...any) *ServiceError {
+  return NewServiceError(http.StatusNotFound, ServiceError_NotFound, format, args...)
+}
+
+// errBotAvailable reports a bot which can not be commanded or sampled
+func errBotAvailable(bot *Bot) *ServiceError {
+  switch {
+    case bot.IsDegraded() == true:
+      return NewServiceError(http.StatusServiceUnavailable, ServiceError_Robot, "Bot %s is degraded", bot.Name)
+    case bot.IsConnected() == false:
+      return NewServiceError(http.StatusServiceUnavailable, ServiceError_Robot, "Bot %s is not connected", bot.Name)
+  }
+  return nil
+}
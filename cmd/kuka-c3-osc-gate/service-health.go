@@ -0,0 +1,52 @@
+package main
+
+import (
+  "net/http"
+  "time"
+)
+
+const (
+  Service_Healthz_API = "/healthz"
+  Service_Readyz_API  = "/readyz"
+
+  Service_Health_Timeout = 1 * time.Second
+)
+
+type HealthApp struct {
+  Status string `json:"status"`
+}
+
+// ReadyApp lists the state of every bot, the gate is ready when all bots can be commanded
+type ReadyApp struct {
+  Ready bool           `json:"ready"`
+  Bots  []*BotStateApp `json:"bots"`
+}
+
+// HealthzHandler is the liveness probe: the team loop handles OSC commands
+func (service *Service) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+  if r.Method != "GET" && r.Method != "HEAD" {
+    responseMethodNotAllowed(w)
+    return
+  }
+
+  if service.botsTeam.Alive(Service_Health_Timeout) == false {
+    responseError(w, NewServiceError(http.StatusServiceUnavailable, ServiceError_Internal, "Team loop does not respond"))
+    return
+  }
+  responseJSON(w, http.StatusOK, &HealthApp{Status: "ok"})
+}
+
+// ReadyzHandler is the readiness probe: every bot is connected and polled
+func (service *Service) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+  if r.Method != "GET" && r.Method != "HEAD" {
+    responseMethodNotAllowed(w)
+    return
+  }
+
+  ready := service.botsTeam.GetReadyApp()
+  status := http.StatusOK
+  if ready.Ready == false {
+    status = http.StatusServiceUnavailable
+  }
+  responseJSON(w, status, ready)
+}
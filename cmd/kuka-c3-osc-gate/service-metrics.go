@@ -0,0 +1,152 @@
+package main
+
+import (
+  "bytes"
+  "net/http"
+  "runtime"
+  "strings"
+  "time"
+)
+
+const (
+  Service_Metrics_API = "/metrics"
+
+  Service_Metrics_ContentType     = "text/plain; version=0.0.4; charset=utf-8"
+  Service_OpenMetrics_ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+var metricsStartTime = time.Now()
+
+// MetricsHandler exposes gate and robot health in the Prometheus text format,
+// OpenMetrics is returned when the scraper accepts it
+func (service *Service) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+  if r.Method != "GET" {
+    responseMethodNotAllowed(w)
+    return
+  }
+
+  buffer := new(bytes.Buffer)
+  mw := &metricsWriter{w: buffer, isOpenMetrics: strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")}
+  service.botsTeam.WriteMetrics(mw)
+  mw.end()
+
+  if mw.isOpenMetrics == true {
+    w.Header().Set("Content-Type", Service_OpenMetrics_ContentType)
+  } else {
+    w.Header().Set("Content-Type", Service_Metrics_ContentType)
+  }
+  w.WriteHeader(http.StatusOK)
+  w.Write(buffer.Bytes())
+}
+
+func (team *Team) WriteMetrics(mw *metricsWriter) {
+  bots := team.GetBots()
+
+  mw.family("build_info", "gauge", "Gate version")
+  mw.sample("build_info", []string{"version", version, "goversion", runtime.Version()}, 1)
+  mw.family("start_time_seconds", "gauge", "Gate start time in unix seconds")
+  mw.sample("start_time_seconds", nil, float64(metricsStartTime.UnixNano()) / 1e9)
+
+  mw.family("osc_dropped_packets_total", "counter", "OSC packets discarded because an input queue is full")
+  if team.oscServer != nil {
+    mw.sample("osc_dropped_packets_total", []string{"queue", "server"}, float64(team.oscServer.dropped.Load()))
+  }
+  mw.sample("osc_dropped_packets_total", []string{"queue", "team"}, float64(team.oscDropped.Load()))
+  for _, bot := range bots {
+    mw.sample("osc_dropped_packets_total", []string{"queue", "bot", "bot", bot.Name}, float64(bot.metrics.oscDropped.Load()))
+  }
+
+  if team.OSCGuard != nil {
+    mw.family("osc_rejected_packets_total", "counter", "OSC packets rejected by the guard by reason: source, secret, hmac or disarmed")
+    rejected := team.OSCGuard.Rejected()
+    for _, reason := range OSCGuard_Reasons {
+      mw.sample("osc_rejected_packets_total", []string{"reason", string(reason)}, float64(rejected[reason]))
+    }
+    mw.family("osc_armed", "gauge", "OSC motion commands are accepted")
+    var armed float64
+    if team.OSCGuard.IsArmed() == true {
+      armed = 1
+    }
+    mw.sample("osc_armed", nil, armed)
+  }
+
+  botGauge := func(name string, help string, value func(bot *Bot) bool) {
+    mw.family(name, "gauge", help)
+    for _, bot := range bots {
+      var v float64
+      if value(bot) == true {
+        v = 1
+      }
+      mw.sample(name, []string{"bot", bot.Name}, v)
+    }
+  }
+  botGauge("bot_connected", "C3 connection is established", (*Bot).IsConnected)
+  botGauge("bot_degraded", "Bot failed to start", (*Bot).IsDegraded)
+  botGauge("bot_moving", "Bot is moving", (*Bot).IsMovement)
+
+  // C3 statistics live in the C3Client, a degraded bot may have none
+  clients := make(map[*Bot]*C3Client, len(bots))
+  for _, bot := range bots {
+    if bot.c3Client != nil {
+      clients[bot] = bot.c3Client
+    }
+  }
+  c3Counter := func(name string, help string, value func(c3 *C3Client) uint64) {
+    mw.family(name, "counter", help)
+    for _, bot := range bots {
+      if c3 := clients[bot]; c3 != nil {
+        mw.sample(name, []string{"bot", bot.Name}, float64(value(c3)))
+      }
+    }
+  }
+  c3Counter("c3_requests_total", "C3 variable requests sent", func(c3 *C3Client) uint64 { return c3.requests.Load() })
+  c3Counter("c3_request_timeouts_total", "C3 requests without response in time", func(c3 *C3Client) uint64 { return c3.timeouts.Load() })
+  c3Counter("c3_request_collisions_total", "C3 pending requests replaced by a request with the same tagID", func(c3 *C3Client) uint64 { return c3.collisions.Load() })
+  c3Counter("c3_reconnects_total", "C3 connections restored after a loss", func(c3 *C3Client) uint64 { return c3.reconnects.Load() })
+  c3Counter("c3_dropped_packets_total", "C3 responses discarded because the queue is full", func(c3 *C3Client) uint64 { return c3.dropped.Load() })
+
+  mw.family("c3_request_duration_seconds", "histogram", "C3 request to response latency")
+  for _, bot := range bots {
+    if c3 := clients[bot]; c3 != nil {
+      mw.histogram("c3_request_duration_seconds", []string{"bot", bot.Name}, c3.latency)
+    }
+  }
+
+  mw.family("position_updates_total", "counter", "Robot position polls")
+  for _, bot := range bots {
+    mw.sample("position_updates_total", []string{"bot", bot.Name}, float64(bot.metrics.positionUpdates.Load()))
+  }
+  mw.family("position_update_errors_total", "counter", "Failed robot position polls")
+  for _, bot := range bots {
+    mw.sample("position_update_errors_total", []string{"bot", bot.Name}, float64(bot.metrics.positionUpdateErrors.Load()))
+  }
+  mw.family("position_update_rate_hertz", "gauge", "Robot position polls per second over the last second")
+  for _, bot := range bots {
+    mw.sample("position_update_rate_hertz", []string{"bot", bot.Name}, bot.metrics.positionRate.Value())
+  }
+
+  mw.family("moves_total", "counter", "Moves to a position by result: success, break or error")
+  for _, bot := range bots {
+    mw.sample("moves_total", []string{"bot", bot.Name, "result", "success"}, float64(bot.metrics.moveSuccess.Load()))
+    mw.sample("moves_total", []string{"bot", bot.Name, "result", "break"}, float64(bot.metrics.moveBreak.Load()))
+    mw.sample("moves_total", []string{"bot", bot.Name, "result", "error"}, float64(bot.metrics.moveError.Load()))
+  }
+  mw.family("move_duration_seconds", "histogram", "Duration of moves to a position")
+  for _, bot := range bots {
+    mw.histogram("move_duration_seconds", []string{"bot", bot.Name}, bot.metrics.moveDuration)
+  }
+
+  axes := metricsAxes()
+  mw.family("bot_axis_position", "gauge", "Current joint values A1..A6 in degrees, E1..E6 in degrees or mm")
+  for _, bot := range bots {
+    bot.positionMux.RLock()
+    position := bot.c3AXIS_ACT.Clone()
+    bot.positionMux.RUnlock()
+    if position.Type() != PositionType_E6AXIS {
+      continue
+    }
+    for _, axis := range axes {
+      mw.sample("bot_axis_position", []string{"bot", bot.Name, "axis", axis}, float64(position.Get(OSCMapping_Targets[axis].index)))
+    }
+  }
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+  "encoding/json"
+  "fmt"
+  "log"
+  "net/http"
+  "strconv"
+)
+
+const (
+  Service_MoveGroups_API      = "/bots/{bot}/moveGroups"
+  Service_MoveGroup_API       = "/bots/{bot}/moveGroups/{id}"
+  Service_MoveGroupsOrder_API = "/bots/{bot}/moveGroups/order"
+  Service_Steps_API           = "/bots/{bot}/moveGroups/{id}/positions"
+  Service_Step_API            = "/bots/{bot}/moveGroups/{id}/positions/{index}"
+  Service_StepsOrder_API      = "/bots/{bot}/moveGroups/{id}/positions/order"
+
+  Service_MaxBody = 1 << 20
+)
+
+func (service *Service) handleMoveGroups() {
+  service.mux.HandleFunc("GET " + Service_MoveGroups_API, service.MoveGroupsListHandler)
+  service.mux.HandleFunc("POST " + Service_MoveGroups_API, service.MoveGroupCreateHandler)
+  service.mux.HandleFunc("PUT " + Service_MoveGroupsOrder_API, service.MoveGroupsOrderHandler)
+  service.mux.HandleFunc("GET " + Service_MoveGroup_API, service.MoveGroupGetHandler)
+  service.mux.HandleFunc("PUT " + Service_MoveGroup_API, service.MoveGroupUpdateHandler)
+  service.mux.HandleFunc("DELETE " + Service_MoveGroup_API, service.MoveGroupDeleteHandler)
+  service.mux.HandleFunc("POST " + Service_Steps_API, service.StepCreateHandler)
+  service.mux.HandleFunc("PUT " + Service_StepsOrder_API, service.StepsOrderHandler)
+  service.mux.HandleFunc("PUT " + Service_Step_API, service.StepUpdateHandler)
+  service.mux.HandleFunc("DELETE " + Service_Step_API, service.StepDeleteHandler)
+}
+
+// requestBot finds a bot by index or by name
+func (service *Service) requestBot(w http.ResponseWriter, r *http.Request) *Bot {
+  value := r.PathValue("bot")
+  var bot *Bot
+  if id, err := strconv.Atoi(value); err == nil {
+    bot = service.botsTeam.GetBot(id)
+  } else {
+    bot = service.botsTeam.GetBotByName(value)
+  }
+
+  if bot == nil {
+    responseError(w, errNotFound("Bot %s is not found", value))
+  }
+  return bot
+}
+
+func requestMoveGroupId(w http.ResponseWriter, r *http.Request) (uint16, bool) {
+  id, err := strconv.ParseUint(r.PathValue("id"), 10, 16)
+  if err != nil {
+    responseError(w, errValidation("Incorrect MoveGroup id %q", r.PathValue("id")))
+    return 0, false
+  }
+  return uint16(id), true
+}
+
+func requestJSON(w http.ResponseWriter, r *http.Request, value any) bool {
+  decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, Service_MaxBody))
+  decoder.DisallowUnknownFields()
+  if err := decoder.Decode(value); err != nil {
+    responseError(w, errValidation("Incorrect JSON body: %v", err))
+    return false
+  }
+  return true
+}
+
+func responseJSON(w http.ResponseWriter, status int, value any) {
+  w.Header().Set("Content-Type", "application/json; charset=utf-8")
+  w.WriteHeader(status)
+  if err := json.NewEncoder(w).Encode(value); err != nil {
+    log.Printf("[Service ERROR] Response json error: %v\n", err)
+  }
+}
+
+func moveGroupNotFound(id uint16) error {
+  return errNotFound("MoveGroup %d is not found", id)
+}
+
+func moveGroupExists(id uint16) error {
+  return NewServiceError(http.StatusConflict, ServiceError_Conflict, "MoveGroup %d already exists", id)
+}
+
+func moveGroupIndex(moveGroups []*MoveGroup, id uint16) int {
+  for i, moveGroup := range moveGroups {
+    if moveGroup.Id == id {
+      return i
+    }
+  }
+  return -1
+}
+
+// editMoveGroups applies an edit and writes the error status or responds with result()
+func (service *Service) editMoveGroups(w http.ResponseWriter, bot *Bot, status int, edit func([]*MoveGroup) ([]*MoveGroup, error), result func() any) {
+  if err := service.botsTeam.EditMoveGroups(bot, edit); err != nil {
+    log.Printf("[Service ERROR] Bot %s MoveGroups edit error: %v\n", bot.Name, err)
+    if _, ok := err.(*ServiceError); ok == false {
+      err = NewServiceError(http.StatusUnprocessableEntity, ServiceError_Validation, "%v", err)
+    }
+    responseError(w, err)
+    return
+  }
+  responseJSON(w, status, result())
+}
+
+// editMoveGroup replaces MoveGroup id with an edited clone
+func (service *Service) editMoveGroup(w http.ResponseWriter, bot *Bot, id uint16, status int, edit func(*MoveGroup) error) {
+  var edited *MoveGroup
+  service.editMoveGroups(w, bot, status, func(moveGroups []*MoveGroup) ([]*MoveGroup, error) {
+    i := moveGroupIndex(moveGroups, id)
+    if i < 0 {
+      return nil, moveGroupNotFound(id)
+    }
+    edited = moveGroups[i].Clone()
+    if err := edit(edited); err != nil {
+      return nil, err
+    }
+    moveGroups[i] = edited
+    return moveGroups, nil
+  }, func() any { return edited })
+}
+
+func (service *Service) MoveGroupsListHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  responseJSON(w, http.StatusOK, bot.GetMoveGroups())
+}
+
+func (service *Service) MoveGroupGetHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, ok := requestMoveGroupId(w, r)
+  if ok == false {
+    return
+  }
+
+  moveGroup := bot.GetMoveGroup(id)
+  if moveGroup == nil {
+    responseError(w, moveGroupNotFound(id))
+    return
+  }
+  responseJSON(w, http.StatusOK, moveGroup)
+}
+
+// MoveGroupCreateHandler appends a MoveGroup, ?index=n inserts it at position n
+func (service *Service) MoveGroupCreateHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+
+  moveGroup := NewMoveGroup(0)
+  if requestJSON(w, r, moveGroup) == false {
+    return
+  }
+  if moveGroup.Positions == nil {
+    moveGroup.Positions = make([]*MoveStep, 0)
+  }
+
+  service.editMoveGroups(w, bot, http.StatusCreated, func(moveGroups []*MoveGroup) ([]*MoveGroup, error) {
+    if moveGroupIndex(moveGroups, moveGroup.Id) >= 0 {
+      return nil, moveGroupExists(moveGroup.Id)
+    }
+    index, err := requestIndex(r, len(moveGroups))
+    if err != nil {
+      return nil, err
+    }
+    return insertAt(moveGroups, index, moveGroup), nil
+  }, func() any { return moveGroup })
+}
+
+// MoveGroupUpdateHandler replaces positions of MoveGroup id, a different body id renames the group
+func (service *Service) MoveGroupUpdateHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, ok := requestMoveGroupId(w, r)
+  if ok == false {
+    return
+  }
+
+  moveGroup := NewMoveGroup(id)
+  if requestJSON(w, r, moveGroup) == false {
+    return
+  }
+  if moveGroup.Positions == nil {
+    moveGroup.Positions = make([]*MoveStep, 0)
+  }
+
+  service.editMoveGroups(w, bot, http.StatusOK, func(moveGroups []*MoveGroup) ([]*MoveGroup, error) {
+    i := moveGroupIndex(moveGroups, id)
+    if i < 0 {
+      return nil, moveGroupNotFound(id)
+    }
+    if moveGroup.Id != id && moveGroupIndex(moveGroups, moveGroup.Id) >= 0 {
+      return nil, moveGroupExists(moveGroup.Id)
+    }
+    moveGroups[i] = moveGroup
+    return moveGroups, nil
+  }, func() any { return moveGroup })
+}
+
+func (service *Service) MoveGroupDeleteHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, ok := requestMoveGroupId(w, r)
+  if ok == false {
+    return
+  }
+
+  service.editMoveGroups(w, bot, http.StatusOK, func(moveGroups []*MoveGroup) ([]*MoveGroup, error) {
+    i := moveGroupIndex(moveGroups, id)
+    if i < 0 {
+      return nil, moveGroupNotFound(id)
+    }
+    return append(moveGroups[:i], moveGroups[i+1:]...), nil
+  }, func() any { return true })
+}
+
+// MoveGroupsOrderHandler reorders MoveGroups by a JSON array of every MoveGroup id
+func (service *Service) MoveGroupsOrderHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+
+  var ids []uint16
+  if requestJSON(w, r, &ids) == false {
+    return
+  }
+
+  var ordered []*MoveGroup
+  service.editMoveGroups(w, bot, http.StatusOK, func(moveGroups []*MoveGroup) ([]*MoveGroup, error) {
+    if len(ids) != len(moveGroups) {
+      return nil, fmt.Errorf("Order must list all %d MoveGroup ids, got %d", len(moveGroups), len(ids))
+    }
+    ordered = make([]*MoveGroup, 0, len(ids))
+    for _, id := range ids {
+      i := moveGroupIndex(moveGroups, id)
+      if i < 0 {
+        return nil, moveGroupNotFound(id)
+      }
+      ordered = append(ordered, moveGroups[i])
+    }
+    return ordered, nil
+  }, func() any { return ordered })
+}
+
+// StepCreateHandler appends a position or an action name, ?index=n inserts it at position n
+func (service *Service) StepCreateHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, ok := requestMoveGroupId(w, r)
+  if ok == false {
+    return
+  }
+
+  step := &MoveStep{}
+  if requestJSON(w, r, step) == false {
+    return
+  }
+
+  service.editMoveGroup(w, bot, id, http.StatusCreated, func(moveGroup *MoveGroup) error {
+    index, err := requestIndex(r, len(moveGroup.Positions))
+    if err != nil {
+      return err
+    }
+    moveGroup.Positions = insertAt(moveGroup.Positions, index, step)
+    return nil
+  })
+}
+
+func (service *Service) StepUpdateHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, ok := requestMoveGroupId(w, r)
+  if ok == false {
+    return
+  }
+
+  step := &MoveStep{}
+  if requestJSON(w, r, step) == false {
+    return
+  }
+
+  service.editMoveGroup(w, bot, id, http.StatusOK, func(moveGroup *MoveGroup) error {
+    index, err := requestStepIndex(r, len(moveGroup.Positions))
+    if err != nil {
+      return err
+    }
+    moveGroup.Positions[index] = step
+    return nil
+  })
+}
+
+func (service *Service) StepDeleteHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, ok := requestMoveGroupId(w, r)
+  if ok == false {
+    return
+  }
+
+  service.editMoveGroup(w, bot, id, http.StatusOK, func(moveGroup *MoveGroup) error {
+    index, err := requestStepIndex(r, len(moveGroup.Positions))
+    if err != nil {
+      return err
+    }
+    moveGroup.Positions = append(moveGroup.Positions[:index], moveGroup.Positions[index+1:]...)
+    return nil
+  })
+}
+
+// StepsOrderHandler reorders positions by a JSON array of every current position index
+func (service *Service) StepsOrderHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, ok := requestMoveGroupId(w, r)
+  if ok == false {
+    return
+  }
+
+  var order []int
+  if requestJSON(w, r, &order) == false {
+    return
+  }
+
+  service.editMoveGroup(w, bot, id, http.StatusOK, func(moveGroup *MoveGroup) error {
+    if len(order) != len(moveGroup.Positions) {
+      return fmt.Errorf("Order must list all %d position indexes, got %d", len(moveGroup.Positions), len(order))
+    }
+    used := make([]bool, len(order))
+    steps := make([]*MoveStep, 0, len(order))
+    for _, i := range order {
+      if i < 0 || i >= len(order) || used[i] == true {
+        return fmt.Errorf("Order position index %d is incorrect or duplicated", i)
+      }
+      used[i] = true
+      steps = append(steps, moveGroup.Positions[i])
+    }
+    moveGroup.Positions = steps
+    return nil
+  })
+}
+
+// requestIndex returns ?index=n for inserts, the list length when not set
+func requestIndex(r *http.Request, length int) (int, error) {
+  value := r.URL.Query().Get("index")
+  if value == "" {
+    return length, nil
+  }
+  index, err := strconv.Atoi(value)
+  if err != nil || index < 0 || index > length {
+    return 0, errValidation("Incorrect index %q of %d", value, length)
+  }
+  return index, nil
+}
+
+func requestStepIndex(r *http.Request, length int) (int, error) {
+  index, err := strconv.Atoi(r.PathValue("index"))
+  if err != nil || index < 0 || index >= length {
+    return 0, errNotFound("Position %q is not found of %d", r.PathValue("index"), length)
+  }
+  return index, nil
+}
+
+func insertAt[T any](list []T, index int, value T) []T {
+  list = append(list, value)
+  copy(list[index+1:], list[index:])
+  list[index] = value
+  return list
+}
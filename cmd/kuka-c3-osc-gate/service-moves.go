@@ -0,0 +1,217 @@
+package main
+
+import (
+  "log"
+  "net/http"
+  "strconv"
+  "sync"
+  "time"
+)
+
+const (
+  Service_Moves_API = "/bots/{bot}/moves"
+  Service_Move_API  = "/bots/{bot}/moves/{id}"
+
+  Service_MovesHistory = 256
+)
+
+type MoveState string
+
+const (
+  MoveState_Moving MoveState = "moving"
+  MoveState_Done   MoveState = "done"
+  MoveState_Failed MoveState = "failed" // Robot rejected the move or C3 request failed
+  MoveState_Break  MoveState = "break"  // Robot did not reach the position in time
+)
+
+// MoveRequest is a direct move to an E6AXIS or E6POS position array
+type MoveRequest struct {
+  Position *Position `json:"position"`
+}
+
+type MoveStatus struct {
+  Id         uint64        `json:"id"`
+  Bot        string        `json:"bot"`
+  Position   *Position     `json:"position"`
+  State      MoveState     `json:"state"`
+  User       string        `json:"user"` // Who requested the move
+  Error      *ServiceError `json:"error"`
+  StartedAt  time.Time     `json:"startedAt"`
+  FinishedAt *time.Time    `json:"finishedAt"`
+
+  bot *Bot
+}
+
+// MoveTracker runs direct moves, one per bot, and keeps the latest statuses for queries by id
+type MoveTracker struct {
+  nextId uint64
+  moves  map[uint64]*MoveStatus
+  order  []uint64
+  active map[*Bot]*MoveStatus
+  mux    sync.Mutex
+}
+
+func NewMoveTracker() *MoveTracker {
+  return &MoveTracker{
+    moves:  make(map[uint64]*MoveStatus),
+    active: make(map[*Bot]*MoveStatus),
+  }
+}
+
+// Start moves the bot in background, a bot moving by any source is busy
+func (mt *MoveTracker) Start(bot *Bot, position *Position, user string) (*MoveStatus, error) {
+  mt.mux.Lock()
+  defer mt.mux.Unlock()
+
+  if mt.active[bot] != nil || bot.IsMovement() == true {
+    return nil, NewServiceError(http.StatusConflict, ServiceError_Busy, "Bot %s is already moving", bot.Name)
+  }
+
+  mt.nextId++
+  status := &MoveStatus{
+    Id:        mt.nextId,
+    Bot:       bot.Name,
+    Position:  position,
+    State:     MoveState_Moving,
+    User:      user,
+    StartedAt: time.Now(),
+    bot:       bot,
+  }
+  mt.active[bot] = status
+  mt.moves[status.Id] = status
+  mt.order = append(mt.order, status.Id)
+  if len(mt.order) > Service_MovesHistory {
+    delete(mt.moves, mt.order[0])
+    mt.order = mt.order[1:]
+  }
+
+  go func() {
+    isBreak, err := bot.Move(position)
+    mt.finish(status, isBreak, err)
+  }()
+
+  return status.clone(), nil
+}
+
+func (mt *MoveTracker) finish(status *MoveStatus, isBreak bool, err error) {
+  mt.mux.Lock()
+  defer mt.mux.Unlock()
+
+  finishedAt := time.Now()
+  status.FinishedAt = &finishedAt
+  switch {
+    case err == nil:
+      status.State = MoveState_Done
+    case isBreak == true:
+      status.State = MoveState_Break
+      status.Error = NewServiceError(http.StatusGatewayTimeout, ServiceError_Robot, "%v", err)
+    default:
+      status.State = MoveState_Failed
+      status.Error = NewServiceError(http.StatusBadGateway, ServiceError_Robot, "%v", err)
+  }
+  delete(mt.active, status.bot)
+
+  if err != nil {
+    log.Printf("[Service ERROR] Bot %s move %d error: %v with break %v\n", status.Bot, status.Id, err, isBreak)
+    return
+  }
+  log.Printf("[Service INFO] Bot %s move %d is done\n", status.Bot, status.Id)
+}
+
+func (mt *MoveTracker) Get(bot *Bot, id uint64) *MoveStatus {
+  mt.mux.Lock()
+  defer mt.mux.Unlock()
+  status, ok := mt.moves[id]
+  if ok == false || status.bot != bot {
+    return nil
+  }
+  return status.clone()
+}
+
+// List returns kept statuses of the bot, oldest first
+func (mt *MoveTracker) List(bot *Bot) []*MoveStatus {
+  mt.mux.Lock()
+  defer mt.mux.Unlock()
+  list := make([]*MoveStatus, 0)
+  for _, id := range mt.order {
+    if status := mt.moves[id]; status.bot == bot {
+      list = append(list, status.clone())
+    }
+  }
+  return list
+}
+
+func (status *MoveStatus) clone() *MoveStatus {
+  result := *status
+  return &result
+}
+
+func (service *Service) handleMoves() {
+  service.mux.HandleFunc("GET " + Service_Moves_API, service.MovesListHandler)
+  service.mux.HandleFunc("POST " + Service_Moves_API, service.MoveHandler)
+  service.mux.HandleFunc("GET " + Service_Move_API, service.MoveStatusHandler)
+}
+
+// MoveHandler starts a move to {"position": [type, values...]} and responds with its status,
+// the move result is polled by id
+func (service *Service) MoveHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+
+  request := &MoveRequest{}
+  if requestJSON(w, r, request) == false {
+    return
+  }
+  if err := bot.CheckMoveStep(NewPositionStep(request.Position)); err != nil {
+    responseError(w, NewServiceError(http.StatusUnprocessableEntity, ServiceError_Validation, "%v", err))
+    return
+  }
+
+  if err := errBotAvailable(bot); err != nil {
+    responseError(w, err)
+    return
+  }
+  if bot.IsHomeRequired() == true {
+    responseError(w, NewServiceError(http.StatusServiceUnavailable, ServiceError_Robot, "Bot %s is waiting for HOME confirmation", bot.Name))
+    return
+  }
+
+  status, err := service.moves.Start(bot, request.Position, ServiceRequestUser(r).Name)
+  if err != nil {
+    responseError(w, err)
+    return
+  }
+
+  w.Header().Set("Location", "/bots/" + r.PathValue("bot") + "/moves/" + strconv.FormatUint(status.Id, 10))
+  responseJSON(w, http.StatusAccepted, status)
+}
+
+func (service *Service) MovesListHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  responseJSON(w, http.StatusOK, service.moves.List(bot))
+}
+
+func (service *Service) MoveStatusHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+
+  id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+  if err != nil {
+    responseError(w, errValidation("Incorrect move id %q", r.PathValue("id")))
+    return
+  }
+
+  status := service.moves.Get(bot, id)
+  if status == nil {
+    responseError(w, errNotFound("Move %d of bot %s is not found", id, bot.Name))
+    return
+  }
+  responseJSON(w, http.StatusOK, status)
+}
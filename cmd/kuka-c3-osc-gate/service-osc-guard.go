@@ -0,0 +1,37 @@
+package main
+
+import (
+  "log"
+  "net/http"
+)
+
+const (
+  Service_OSCGuard_API       = "/osc/guard"
+  Service_OSCGuardArm_API    = "/osc/guard/arm"
+  Service_OSCGuardDisarm_API = "/osc/guard/disarm"
+)
+
+func (service *Service) handleOSCGuard() {
+  service.mux.HandleFunc("GET " + Service_OSCGuard_API, service.OSCGuardHandler)
+  service.mux.HandleFunc("POST " + Service_OSCGuardArm_API, func(w http.ResponseWriter, r *http.Request) {
+    service.OSCGuardArmHandler(w, r, true)
+  })
+  service.mux.HandleFunc("POST " + Service_OSCGuardDisarm_API, func(w http.ResponseWriter, r *http.Request) {
+    service.OSCGuardArmHandler(w, r, false)
+  })
+}
+
+// OSCGuardHandler returns the armed state, allow rules and rejected OSC packet counters
+func (service *Service) OSCGuardHandler(w http.ResponseWriter, r *http.Request) {
+  responseJSON(w, http.StatusOK, service.botsTeam.OSCGuard.GetAppData())
+}
+
+func (service *Service) OSCGuardArmHandler(w http.ResponseWriter, r *http.Request, isArmed bool) {
+  user := ServiceRequestUser(r)
+  if err := service.botsTeam.OSCGuard.SetArmed(isArmed, "API user " + user.Name); err != nil {
+    log.Printf("[Service ERROR] POST OSC Guard %s error: %v\n", oscGuardArmedName(isArmed), err)
+    responseError(w, NewServiceError(http.StatusConflict, ServiceError_Conflict, "%v", err))
+    return
+  }
+  responseJSON(w, http.StatusOK, service.botsTeam.OSCGuard.GetAppData())
+}
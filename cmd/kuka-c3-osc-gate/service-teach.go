@@ -0,0 +1,114 @@
+package main
+
+import (
+  "log"
+  "net/http"
+  "strconv"
+)
+
+const (
+  Service_Teach_API     = "/bots/{bot}/teach"
+  Service_TeachUndo_API = "/bots/{bot}/teach/undo"
+)
+
+type TeachUndoApp struct {
+  MoveGroupId uint16     `json:"moveGroupId"`
+  MoveGroup   *MoveGroup `json:"moveGroup"`
+}
+
+func (service *Service) handleTeach() {
+  service.mux.HandleFunc("GET " + Service_Teach_API, service.TeachPreviewHandler)
+  service.mux.HandleFunc("POST " + Service_Teach_API, service.TeachHandler)
+  service.mux.HandleFunc("POST " + Service_TeachUndo_API, service.TeachUndoHandler)
+}
+
+// requestTeach reads ?moveGroup=id&source=axis|pos|position&index=n, a missing index appends
+func requestTeach(w http.ResponseWriter, r *http.Request) (uint16, TeachSource, int, bool) {
+  id, err := strconv.ParseUint(r.FormValue("moveGroup"), 10, 16)
+  if err != nil {
+    responseError(w, errValidation("Incorrect MoveGroup id %q", r.FormValue("moveGroup")))
+    return 0, "", 0, false
+  }
+
+  source, err := ParseTeachSource(r.FormValue("source"))
+  if err != nil {
+    responseError(w, errValidation("%v", err))
+    return 0, "", 0, false
+  }
+
+  index := -1
+  if value := r.FormValue("index"); value != "" {
+    if index, err = strconv.Atoi(value); err != nil || index < 0 {
+      responseError(w, errValidation("Incorrect index %q", value))
+      return 0, "", 0, false
+    }
+  }
+  return uint16(id), source, index, true
+}
+
+// TeachPreviewHandler returns the pose which would be captured without saving it
+func (service *Service) TeachPreviewHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, source, index, ok := requestTeach(w, r)
+  if ok == false {
+    return
+  }
+
+  if _, err := bot.TeachPosition(source); err != nil {
+    responseError(w, NewServiceError(http.StatusServiceUnavailable, ServiceError_Robot, "%v", err))
+    return
+  }
+
+  result, err := service.botsTeam.TeachPreview(bot, id, source, index)
+  if err != nil {
+    responseError(w, NewServiceError(http.StatusUnprocessableEntity, ServiceError_Validation, "%v", err))
+    return
+  }
+  responseJSON(w, http.StatusOK, result)
+}
+
+// TeachHandler captures the live pose into a MoveGroup, the group is created when it does not exist
+func (service *Service) TeachHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+  id, source, index, ok := requestTeach(w, r)
+  if ok == false {
+    return
+  }
+
+  if _, err := bot.TeachPosition(source); err != nil {
+    responseError(w, NewServiceError(http.StatusServiceUnavailable, ServiceError_Robot, "%v", err))
+    return
+  }
+
+  result, err := service.botsTeam.Teach(bot, id, source, index)
+  if err != nil {
+    log.Printf("[Service ERROR] Bot %s teach error: %v\n", bot.Name, err)
+    responseError(w, NewServiceError(http.StatusUnprocessableEntity, ServiceError_Validation, "%v", err))
+    return
+  }
+  responseJSON(w, http.StatusCreated, result)
+}
+
+// TeachUndoHandler removes the step of the last teach of the bot and returns its MoveGroup
+func (service *Service) TeachUndoHandler(w http.ResponseWriter, r *http.Request) {
+  bot := service.requestBot(w, r)
+  if bot == nil {
+    return
+  }
+
+  id, err := service.botsTeam.TeachUndo(bot)
+  if err != nil {
+    log.Printf("[Service ERROR] Bot %s teach undo error: %v\n", bot.Name, err)
+    responseError(w, NewServiceError(http.StatusConflict, ServiceError_Conflict, "%v", err))
+    return
+  }
+
+  // MoveGroup is null when the undone teach created the group and it is removed
+  responseJSON(w, http.StatusOK, &TeachUndoApp{MoveGroupId: id, MoveGroup: bot.GetMoveGroup(id)})
+}
@@ -8,6 +8,7 @@ import (
 	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Lit3D/lit3d-kuka-c3-gate/app"
@@ -16,10 +17,19 @@ import (
 const (
   Service_StartEndTimeout = 1 * time.Second
   Service_Bots_API = "/bots"
+  Service_Home_API = "/bots/home"
+  Service_Timelines_API = "/timelines"
+  Service_Timecode_API = "/bots/timecode"
+  Service_Events_API = "/bots/events"
+  Service_Stream_API = "/bots/stream"
+  Service_ConfigReload_API = "/config/reload"
 )
 
 type Service struct {
+  options  *ServiceOptions
   botsTeam *Team
+  stream   *BotStream
+  moves    *MoveTracker
   mux      *http.ServeMux
   server   *http.Server
 }
@@ -33,29 +43,69 @@ func init() {
   mime.AddExtensionType(".html", "text/html")
 }
 
-func NewService(port PortValue, botsTeam *Team) *Service {
+func NewService(port PortValue, botsTeam *Team, options *ServiceOptions) *Service {
+  if options == nil {
+    options = &ServiceOptions{}
+  }
+
   service := &Service{
+    options: options,
     botsTeam: botsTeam,
+    stream: NewBotStream(botsTeam),
+    moves: NewMoveTracker(),
     mux: http.NewServeMux(),
   }
 
   service.mux.Handle("/", http.FileServer(app.AppFS))
   service.mux.HandleFunc(Service_Bots_API, service.BotHandler)
+  service.mux.HandleFunc(Service_Home_API, service.HomeHandler)
+  service.mux.HandleFunc(Service_Timelines_API, service.TimelineHandler)
+  service.mux.HandleFunc(Service_Timecode_API, service.TimecodeHandler)
+  service.mux.HandleFunc(Service_Events_API, service.EventsHandler)
+  service.mux.HandleFunc(Service_Stream_API, service.stream.Handler)
+  service.mux.HandleFunc(Service_ConfigReload_API, service.ConfigReloadHandler)
+  service.mux.HandleFunc(Service_Metrics_API, service.MetricsHandler)
+  service.mux.HandleFunc(Service_Healthz_API, service.HealthzHandler)
+  service.mux.HandleFunc(Service_Readyz_API, service.ReadyzHandler)
+  service.handleMoveGroups()
+  service.handleTeach()
+  service.handleMoves()
+  service.handleOSCGuard()
+
+  host := ""
+  if options.IsLocal == true {
+    host = "127.0.0.1"
+  }
 
   service.server = &http.Server{
-    Addr:    fmt.Sprintf(":%s", port.String()),
-    Handler: service.mux,
+    Addr:    fmt.Sprintf("%s:%s", host, port.String()),
+    Handler: service.authHandler(service.mux),
   }
 
   return service
 }
 
 func (service *Service) ListenAndServe() error {
+  service.stream.Up()
   errChan := make(chan error, 1)
 
+  isTLS := service.options.CertFile != "" && service.options.KeyFile != ""
+  if service.options.Auth == nil && service.options.IsLocal == false {
+    log.Printf("[Service WARNING] Authentication is disabled, every client is an engineer\n")
+  } else if isTLS == false && service.options.IsLocal == false {
+    log.Printf("[Service WARNING] Authentication without TLS sends credentials in clear text\n")
+  }
+
   go func() {
-    log.Printf("[Service INFO] Listening on http://0.0.0.0%s\n", service.server.Addr)
-    if err := service.server.ListenAndServe(); err != http.ErrServerClosed {
+    var err error
+    if isTLS == true {
+      log.Printf("[Service INFO] Listening on https://%s\n", service.listenAddr())
+      err = service.server.ListenAndServeTLS(service.options.CertFile, service.options.KeyFile)
+    } else {
+      log.Printf("[Service INFO] Listening on http://%s\n", service.listenAddr())
+      err = service.server.ListenAndServe()
+    }
+    if err != http.ErrServerClosed {
       errChan <- err
     }
     close(errChan)
@@ -74,7 +124,17 @@ func (service *Service) ListenAndServe() error {
   return nil
 }
 
+func (service *Service) listenAddr() string {
+  if strings.HasPrefix(service.server.Addr, ":") == true {
+    return "0.0.0.0" + service.server.Addr
+  }
+  return service.server.Addr
+}
+
 func (service *Service) Shutdown() error {
+  // Stream handlers are long-lived requests, they end before the server waits for idle connections
+  service.stream.Shutdown()
+
   ctx, cancel := context.WithTimeout(context.Background(), Service_StartEndTimeout)
   defer cancel()
   
@@ -90,8 +150,8 @@ func (service *Service) BotHandler(w http.ResponseWriter, r *http.Request) {
   switch r.Method {
     case "GET":
       teamAppData := service.botsTeam.GetAppData()
-      w.WriteHeader(http.StatusOK)
       w.Header().Set("Content-Type", "application/json; charset=utf-8")
+      w.WriteHeader(http.StatusOK)
       if err := json.NewEncoder(w).Encode(teamAppData); err != nil {
         log.Printf("[Service ERROR] Get parse json error: %v\n", err)
       }
@@ -104,38 +164,185 @@ func (service *Service) BotHandler(w http.ResponseWriter, r *http.Request) {
       botId, err := strconv.ParseUint(botIdStr, 10, 16)
       if err != nil {
         log.Printf("[Service ERROR] POST parse Bot ID error: %v\n", err)
-        http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+        responseError(w, errValidation("Incorrect bot id %q", botIdStr))
         return
       }
 
       moveGroupId, err := strconv.ParseUint(moveGroupIdStr, 10, 16)
       if err != nil {
         log.Printf("[Service ERROR] POST parse MoveGroup ID error: %v\n", err)
-        http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+        responseError(w, errValidation("Incorrect MoveGroup id %q", moveGroupIdStr))
         return
       }
       
-      bot := service.botsTeam.Bots[int(botId)]
+      bot := service.botsTeam.GetBot(int(botId))
       if bot == nil {
         log.Printf("[Service ERROR] POST Bot is not found of id %d\n", botId)
-        http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+        responseError(w, errNotFound("Bot %d is not found", botId))
+        return
+      }
+
+      if bot.GetMoveGroup(uint16(moveGroupId)) == nil {
+        log.Printf("[Service ERROR] POST MoveGroup %d is not found\n", moveGroupId)
+        responseError(w, moveGroupNotFound(uint16(moveGroupId)))
+        return
+      }
+
+      if err := errBotAvailable(bot); err != nil {
+        log.Printf("[Service ERROR] POST Run MoveGroup error: %v\n", err)
+        responseError(w, err)
+        return
+      }
+
+      if bot.IsMovement() == true {
+        log.Printf("[Service ERROR] POST Run MoveGroup error: Bot %s is already moving\n", bot.Name)
+        responseError(w, NewServiceError(http.StatusConflict, ServiceError_Busy, "Bot %s is already moving", bot.Name))
         return
       }
 
       if err := bot.RunMoveGroup(uint16(moveGroupId)); err != nil {
         log.Printf("[Service ERROR] POST Run MoveGroup error: %v\n", err)
-        http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+        responseError(w, err)
         return
       }
 
-      w.WriteHeader(http.StatusOK)
       w.Header().Set("Content-Type", "application/json; charset=utf-8")
+      w.WriteHeader(http.StatusOK)
       var trueData bool = true
       json.NewEncoder(w).Encode(trueData)
       return
     
     default:
-      http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+      responseMethodNotAllowed(w)
       return
     }
 }
+
+func (service *Service) HomeHandler(w http.ResponseWriter, r *http.Request) {
+  if r.Method != "POST" {
+    responseMethodNotAllowed(w)
+    return
+  }
+
+  r.ParseMultipartForm(10 << 25)
+  botId, err := strconv.ParseUint(r.FormValue("botId"), 10, 16)
+  if err != nil {
+    log.Printf("[Service ERROR] POST HOME parse Bot ID error: %v\n", err)
+    responseError(w, errValidation("Incorrect bot id %q", r.FormValue("botId")))
+    return
+  }
+
+  bot := service.botsTeam.GetBot(int(botId))
+  if bot == nil {
+    log.Printf("[Service ERROR] POST HOME Bot is not found of id %d\n", botId)
+    responseError(w, errNotFound("Bot %d is not found", botId))
+    return
+  }
+
+  if bot.IsHomeRequired() != true {
+    log.Printf("[Service ERROR] POST HOME Bot %s is not waiting for HOME confirmation\n", bot.Name)
+    responseError(w, NewServiceError(http.StatusConflict, ServiceError_Conflict, "Bot %s is not waiting for HOME confirmation", bot.Name))
+    return
+  }
+
+  go func(bot *Bot) {
+    if err := bot.ConfirmHome(); err != nil {
+      log.Printf("[Service ERROR] POST HOME Bot %s error: %v\n", bot.Name, err)
+    }
+  }(bot)
+
+  w.Header().Set("Content-Type", "application/json; charset=utf-8")
+  w.WriteHeader(http.StatusAccepted)
+  var trueData bool = true
+  json.NewEncoder(w).Encode(trueData)
+}
+
+func (service *Service) TimelineHandler(w http.ResponseWriter, r *http.Request) {
+  switch r.Method {
+    case "GET":
+      timelinesAppData := service.botsTeam.GetTimelinesAppData()
+      w.Header().Set("Content-Type", "application/json; charset=utf-8")
+      w.WriteHeader(http.StatusOK)
+      if err := json.NewEncoder(w).Encode(timelinesAppData); err != nil {
+        log.Printf("[Service ERROR] Get timelines json error: %v\n", err)
+      }
+      return
+
+    case "POST":
+      r.ParseMultipartForm(10 << 25)
+      timeline := service.botsTeam.GetTimeline(r.FormValue("timeline"))
+      if timeline == nil {
+        log.Printf("[Service ERROR] POST Timeline %s is not found\n", r.FormValue("timeline"))
+        responseError(w, errNotFound("Timeline %s is not found", r.FormValue("timeline")))
+        return
+      }
+
+      if err := timeline.Command(TimelineCommand(r.FormValue("command")), r.FormValue("cue")); err != nil {
+        log.Printf("[Service ERROR] POST Timeline command error: %v\n", err)
+        responseError(w, errValidation("%v", err))
+        return
+      }
+
+      w.Header().Set("Content-Type", "application/json; charset=utf-8")
+      w.WriteHeader(http.StatusOK)
+      json.NewEncoder(w).Encode(timeline.GetAppData())
+      return
+
+    default:
+      responseMethodNotAllowed(w)
+      return
+  }
+}
+
+func (service *Service) TimecodeHandler(w http.ResponseWriter, r *http.Request) {
+  if r.Method != "GET" {
+    responseMethodNotAllowed(w)
+    return
+  }
+
+  if service.botsTeam.Timecode == nil {
+    responseError(w, errNotFound("Timecode is not configured"))
+    return
+  }
+
+  w.Header().Set("Content-Type", "application/json; charset=utf-8")
+  w.WriteHeader(http.StatusOK)
+  if err := json.NewEncoder(w).Encode(service.botsTeam.Timecode.GetAppData()); err != nil {
+    log.Printf("[Service ERROR] Get timecode json error: %v\n", err)
+  }
+}
+
+// EventsHandler documents OSC error and event paths with every error code
+func (service *Service) EventsHandler(w http.ResponseWriter, r *http.Request) {
+  if r.Method != "GET" {
+    responseMethodNotAllowed(w)
+    return
+  }
+
+  events := service.botsTeam.Events
+  if events == nil {
+    events = &GateEvents{}
+  }
+
+  w.Header().Set("Content-Type", "application/json; charset=utf-8")
+  w.WriteHeader(http.StatusOK)
+  if err := json.NewEncoder(w).Encode(events.GetAppData()); err != nil {
+    log.Printf("[Service ERROR] Get events json error: %v\n", err)
+  }
+}
+
+// ConfigReloadHandler re-reads the config file, a rejected config is reported and running bots are not changed
+func (service *Service) ConfigReloadHandler(w http.ResponseWriter, r *http.Request) {
+  if r.Method != "POST" {
+    responseMethodNotAllowed(w)
+    return
+  }
+
+  result, err := service.botsTeam.Reload()
+  if err != nil {
+    log.Printf("[Service ERROR] POST config reload error: %v\n", err)
+    responseError(w, NewServiceError(http.StatusUnprocessableEntity, ServiceError_Validation, "%v", err))
+    return
+  }
+  responseJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestServiceErrorJSON(t *testing.T) {
+  service := &Service{botsTeam: NewTeam("")}
+
+  tests := []struct {
+    name    string
+    handler http.HandlerFunc
+    method  string
+    body    string
+    status  int
+    kind    ServiceErrorKind
+  }{
+    {"timeline method", service.TimelineHandler, "PUT", "", http.StatusMethodNotAllowed, ServiceError_Method},
+    {"timeline not found", service.TimelineHandler, "POST", "timeline=show&command=go", http.StatusNotFound, ServiceError_NotFound},
+    {"timecode method", service.TimecodeHandler, "POST", "", http.StatusMethodNotAllowed, ServiceError_Method},
+    {"timecode not configured", service.TimecodeHandler, "GET", "", http.StatusNotFound, ServiceError_NotFound},
+    {"events method", service.EventsHandler, "DELETE", "", http.StatusMethodNotAllowed, ServiceError_Method},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      r := httptest.NewRequest(test.method, "/", strings.NewReader(test.body))
+      r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+      w := httptest.NewRecorder()
+      test.handler(w, r)
+
+      var serviceErr ServiceError
+      if err := json.Unmarshal(w.Body.Bytes(), &serviceErr); err != nil {
+        t.Fatalf("body %q is not a ServiceError: %v", w.Body.String(), err)
+      }
+      if w.Code != test.status || serviceErr.Status != test.status || serviceErr.Kind != test.kind {
+        t.Errorf("response = %d %+v, want %d %s", w.Code, serviceErr, test.status, test.kind)
+      }
+    })
+  }
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "log"
+  "strconv"
+)
+
+const (
+  Teach_UndoDepth = 64
+)
+
+// TeachSource is the live pose captured by a teach, a pose step is replayed in the frame it is captured in
+type TeachSource string
+
+const (
+  TeachSource_Axis     TeachSource = "axis"     // Raw AXIS_ACT as E6AXIS
+  TeachSource_Pos      TeachSource = "pos"      // Raw POS_ACT as a raw E6POS step
+  TeachSource_Position TeachSource = "position" // POS_ACT corrected by the startup offset as E6POS
+)
+
+// TeachResult describes a captured (or previewed) waypoint
+type TeachResult struct {
+  Bot         string      `json:"bot"`
+  MoveGroupId uint16      `json:"moveGroupId"`
+  Index       int         `json:"index"`
+  Source      TeachSource `json:"source"`
+  Position    *Position   `json:"position"`
+  IsNewGroup  bool        `json:"isNewGroup"`
+}
+
+// teachUndo removes the taught step, edits of other steps made after the teach are kept
+type teachUndo struct {
+  moveGroupId uint16
+  index       int
+  step        *MoveStep
+  isNewGroup  bool
+}
+
+var errTeachUndoStale = errors.New("taught step is already removed")
+
+// stepIndex finds the taught step, at its index or moved by later inserts and deletes
+func (undo *teachUndo) stepIndex(steps []*MoveStep) int {
+  isTaught := func(step *MoveStep) bool {
+    return step.IsAction() == false && step.Position != nil && step.IsRaw == undo.step.IsRaw &&
+      step.Position.Type() == undo.step.Position.Type() && step.Position.EqualFull(undo.step.Position, 0)
+  }
+  if undo.index < len(steps) && isTaught(steps[undo.index]) == true {
+    return undo.index
+  }
+  for i, step := range steps {
+    if isTaught(step) == true {
+      return i
+    }
+  }
+  return -1
+}
+
+// teachStep keeps a raw pose raw, so the step is completed against POS_ACT
+func teachStep(source TeachSource, position *Position) *MoveStep {
+  if source == TeachSource_Pos {
+    return NewRawPositionStep(position)
+  }
+  return NewPositionStep(position)
+}
+
+func ParseTeachSource(value string) (TeachSource, error) {
+  switch TeachSource(value) {
+    case "":
+      return TeachSource_Axis, nil
+    case TeachSource_Axis, TeachSource_Pos, TeachSource_Position:
+      return TeachSource(value), nil
+  }
+  return "", fmt.Errorf("Incorrect teach source %q, must be %s, %s or %s", value, TeachSource_Axis, TeachSource_Pos, TeachSource_Position)
+}
+
+// TeachPosition snapshots the live pose of the bot
+func (bot *Bot) TeachPosition(source TeachSource) (*Position, error) {
+  if bot.IsDegraded() == true {
+    return nil, fmt.Errorf("Bot %s is degraded", bot.Name)
+  }
+  if bot.IsConnected() == false {
+    return nil, fmt.Errorf("Bot %s is not connected", bot.Name)
+  }
+
+  bot.positionMux.RLock()
+  defer bot.positionMux.RUnlock()
+
+  var position *Position
+  switch source {
+    case TeachSource_Axis:
+      position = bot.c3AXIS_ACT.Clone()
+    case TeachSource_Pos:
+      position = bot.c3POS_ACT.Clone()
+    case TeachSource_Position:
+      position = bot.c3POSITION.Clone()
+    default:
+      return nil, fmt.Errorf("Incorrect teach source %q", source)
+  }
+
+  if position.Type() == PositionType_NIL {
+    return nil, fmt.Errorf("Bot %s position is not received yet", bot.Name)
+  }
+  return position, nil
+}
+
+// TeachPreview returns the waypoint Teach would capture without changing the MoveGroup.
+// Negative index appends.
+func (team *Team) TeachPreview(bot *Bot, id uint16, source TeachSource, index int) (*TeachResult, error) {
+  position, err := bot.TeachPosition(source)
+  if err != nil {
+    return nil, err
+  }
+
+  result := &TeachResult{Bot: bot.Name, MoveGroupId: id, Source: source, Position: position}
+  moveGroup := bot.GetMoveGroup(id)
+  length := 0
+  if moveGroup == nil {
+    result.IsNewGroup = true
+  } else {
+    length = len(moveGroup.Positions)
+  }
+
+  if result.Index, err = teachIndex(index, length); err != nil {
+    return nil, err
+  }
+  return result, nil
+}
+
+// Teach inserts the live pose into MoveGroup id, creating the group when it does not exist.
+// The edit is persisted and can be reverted with TeachUndo.
+func (team *Team) Teach(bot *Bot, id uint16, source TeachSource, index int) (*TeachResult, error) {
+  position, err := bot.TeachPosition(source)
+  if err != nil {
+    return nil, err
+  }
+
+  result := &TeachResult{Bot: bot.Name, MoveGroupId: id, Source: source, Position: position}
+  var undo *teachUndo
+
+  err = team.EditMoveGroups(bot, func(moveGroups []*MoveGroup) ([]*MoveGroup, error) {
+    i := moveGroupIndex(moveGroups, id)
+
+    var moveGroup *MoveGroup
+    if i < 0 {
+      result.IsNewGroup = true
+      moveGroup = NewMoveGroup(id)
+    } else {
+      moveGroup = moveGroups[i].Clone()
+    }
+
+    var err error
+    if result.Index, err = teachIndex(index, len(moveGroup.Positions)); err != nil {
+      return nil, err
+    }
+    step := teachStep(source, position)
+    undo = &teachUndo{moveGroupId: id, index: result.Index, step: step.Clone(), isNewGroup: result.IsNewGroup}
+    moveGroup.Positions = insertAt(moveGroup.Positions, result.Index, step)
+
+    if i < 0 {
+      return append(moveGroups, moveGroup), nil
+    }
+    moveGroups[i] = moveGroup
+    return moveGroups, nil
+  })
+  if err != nil {
+    return nil, err
+  }
+
+  team.teachMux.Lock()
+  if team.teachHistory == nil {
+    team.teachHistory = make(map[*Bot][]*teachUndo)
+  }
+  stack := append(team.teachHistory[bot], undo)
+  if len(stack) > Teach_UndoDepth {
+    stack = stack[len(stack) - Teach_UndoDepth:]
+  }
+  team.teachHistory[bot] = stack
+  team.teachMux.Unlock()
+
+  log.Printf("[BotTeam INFO] Bot %s teach %s %s into MoveGroup %d at %d\n", bot.Name, source, position.Value(), id, result.Index)
+  team.Events.Event(GateEvent_Taught, bot.Name, int32(id), int32(result.Index))
+  return result, nil
+}
+
+// TeachUndo removes the step inserted by the last teach of the bot and returns its MoveGroup id.
+// A group created by the teach is removed when the taught step was its only step.
+func (team *Team) TeachUndo(bot *Bot) (uint16, error) {
+  team.teachMux.Lock()
+  defer team.teachMux.Unlock()
+
+  stack := team.teachHistory[bot]
+  if len(stack) == 0 {
+    return 0, fmt.Errorf("Bot %s has nothing to undo", bot.Name)
+  }
+  undo := stack[len(stack) - 1]
+
+  err := team.EditMoveGroups(bot, func(moveGroups []*MoveGroup) ([]*MoveGroup, error) {
+    i := moveGroupIndex(moveGroups, undo.moveGroupId)
+    if i < 0 {
+      return nil, fmt.Errorf("MoveGroup %d %w", undo.moveGroupId, errTeachUndoStale)
+    }
+    moveGroup := moveGroups[i].Clone()
+    j := undo.stepIndex(moveGroup.Positions)
+    if j < 0 {
+      return nil, fmt.Errorf("MoveGroup %d %w", undo.moveGroupId, errTeachUndoStale)
+    }
+
+    moveGroup.Positions = append(moveGroup.Positions[:j], moveGroup.Positions[j+1:]...)
+    if len(moveGroup.Positions) == 0 && undo.isNewGroup == true {
+      return append(moveGroups[:i], moveGroups[i+1:]...), nil
+    }
+    moveGroups[i] = moveGroup
+    return moveGroups, nil
+  })
+  if err != nil {
+    // A stale entry would block every earlier undo, so it is dropped
+    if errors.Is(err, errTeachUndoStale) == true {
+      team.teachHistory[bot] = stack[:len(stack) - 1]
+    }
+    return 0, err
+  }
+
+  team.teachHistory[bot] = stack[:len(stack) - 1]
+  log.Printf("[BotTeam INFO] Bot %s teach into MoveGroup %d is undone\n", bot.Name, undo.moveGroupId)
+  team.Events.Event(GateEvent_TeachUndone, bot.Name, int32(undo.moveGroupId))
+  return undo.moveGroupId, nil
+}
+
+// teachIndex returns the insert index, negative index appends
+func teachIndex(index int, length int) (int, error) {
+  if index < 0 {
+    return length, nil
+  }
+  if index > length {
+    return 0, fmt.Errorf("Teach index %d is out of %d positions", index, length)
+  }
+  return index, nil
+}
+
+// processOSCTeach handles <teachPath> <bot> <moveGroupId> [source] [index]
+func (team *Team) processOSCTeach(oscPacket *OSCPacket) {
+  values := oscPacket.Values()
+  if len(values) < 2 || len(values) > 4 {
+    log.Printf("[BotTeam ERROR] Incorrect OSC Teach values length of %+v\n", values)
+    team.Events.Error(oscPacket.Source, GateError_BadArity, "", fmt.Sprintf("Teach expects 2 to 4 values, got %d", len(values)))
+    return
+  }
+
+  bot := team.oscBot(values[0])
+  if bot == nil {
+    log.Printf("[BotTeam ERROR] OSC Teach bot %v is not found\n", values[0])
+    team.Events.Error(oscPacket.Source, GateError_BadType, "", fmt.Sprintf("Teach bot %v is not found", values[0]))
+    return
+  }
+
+  id, err := OSCInt32(values[1])
+  if err != nil || id < 0 || id > 0xFFFF {
+    log.Printf("[BotTeam ERROR] OSC Teach MoveGroup id %v is incorrect\n", values[1])
+    team.Events.Error(oscPacket.Source, GateError_BadType, bot.Name, fmt.Sprintf("Teach MoveGroup id %v is incorrect", values[1]))
+    return
+  }
+
+  source := TeachSource_Axis
+  if len(values) > 2 {
+    var value string
+    switch v := values[2].(type) {
+      case string:
+        value = v
+      case OSCSymbol:
+        value = string(v)
+    }
+    if source, err = ParseTeachSource(value); err != nil {
+      log.Printf("[BotTeam ERROR] OSC Teach %v\n", err)
+      team.Events.Error(oscPacket.Source, GateError_BadType, bot.Name, err.Error())
+      return
+    }
+  }
+
+  var index int32 = -1
+  if len(values) > 3 {
+    if index, err = OSCInt32(values[3]); err != nil {
+      log.Printf("[BotTeam ERROR] OSC Teach index error: %v\n", err)
+      team.Events.Error(oscPacket.Source, GateError_BadType, bot.Name, fmt.Sprintf("Teach index error: %v", err))
+      return
+    }
+  }
+
+  if _, err := team.Teach(bot, uint16(id), source, int(index)); err != nil {
+    log.Printf("[BotTeam ERROR] OSC Teach error: %v\n", err)
+    team.Events.Error(oscPacket.Source, GateError_CommandFailed, bot.Name, err.Error())
+  }
+}
+
+// processOSCTeachUndo handles <teachPath>/undo <bot>
+func (team *Team) processOSCTeachUndo(oscPacket *OSCPacket) {
+  values := oscPacket.Values()
+  if len(values) != 1 {
+    log.Printf("[BotTeam ERROR] Incorrect OSC Teach undo values length of %+v\n", values)
+    team.Events.Error(oscPacket.Source, GateError_BadArity, "", fmt.Sprintf("Teach undo expects 1 value, got %d", len(values)))
+    return
+  }
+
+  bot := team.oscBot(values[0])
+  if bot == nil {
+    log.Printf("[BotTeam ERROR] OSC Teach undo bot %v is not found\n", values[0])
+    team.Events.Error(oscPacket.Source, GateError_BadType, "", fmt.Sprintf("Teach bot %v is not found", values[0]))
+    return
+  }
+
+  if _, err := team.TeachUndo(bot); err != nil {
+    log.Printf("[BotTeam ERROR] OSC Teach undo error: %v\n", err)
+    team.Events.Error(oscPacket.Source, GateError_CommandFailed, bot.Name, err.Error())
+  }
+}
+
+// oscBot finds a bot by name (string or symbol) or by index (number)
+func (team *Team) oscBot(value any) *Bot {
+  switch v := value.(type) {
+    case string:
+      if bot := team.GetBotByName(v); bot != nil {
+        return bot
+      }
+      if id, err := strconv.Atoi(v); err == nil {
+        return team.GetBot(id)
+      }
+      return nil
+    case OSCSymbol:
+      return team.oscBot(string(v))
+  }
+
+  id, err := OSCInt32(value)
+  if err != nil {
+    return nil
+  }
+  return team.GetBot(int(id))
+}
@@ -0,0 +1,487 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "log"
+  "os"
+  "time"
+)
+
+const (
+  Team_Reload_IdleTimeout = 10 * time.Second
+  Team_Reload_IdleStep    = 50 * time.Millisecond
+)
+
+// TeamReloadApp reports what a config reload changed, bots are listed by name
+type TeamReloadApp struct {
+  Added           []string `json:"added"`
+  Removed         []string `json:"removed"`
+  Restarted       []string `json:"restarted"`
+  Updated         []string `json:"updated"`
+  RestartRequired []string `json:"restartRequired"` // Changed settings which are applied at process start only
+}
+
+// botReload is the plan for one bot of the new config
+type botReload struct {
+  live *Bot // nil for an added bot
+  next *Bot
+
+  isRestart bool
+  isUpdate  bool
+
+  oscClient          *OSCClient
+  isOSCClientChanged bool
+  isDestinationsChanged bool
+}
+
+// teamReload keeps resources created before the new config is applied,
+// so a failure releases them without touching the running team
+type teamReload struct {
+  next *Team
+  bots []*botReload
+
+  oscClient             *OSCClient
+  isOSCClientChanged    bool
+  isDestinationsChanged bool
+}
+
+// Reload re-reads the config file and applies the difference live: team OSC paths and outputs,
+// bot OSC paths, outputs and MoveGroups are updated in place, bots are added and removed,
+// bots with a changed address are restarted. A rejected config leaves the running team untouched.
+func (team *Team) Reload() (*TeamReloadApp, error) {
+  team.editMux.Lock()
+  defer team.editMux.Unlock()
+
+  if _, err := os.Stat(team.filePath); err != nil {
+    return nil, fmt.Errorf("Config %s error: %w", team.filePath, err)
+  }
+
+  next := NewTeam(team.filePath)
+  if err := next.Read(); err != nil {
+    return nil, err
+  }
+
+  if err := next.Validate().Err(); err != nil {
+    return nil, fmt.Errorf("Config %s is rejected: %w", team.filePath, err)
+  }
+
+  plan, err := team.reloadPlan(next)
+  if err != nil {
+    return nil, fmt.Errorf("Config %s is rejected: %w", team.filePath, err)
+  }
+
+  if err := plan.up(); err != nil {
+    plan.release()
+    return nil, fmt.Errorf("Config %s is rejected: %w", team.filePath, err)
+  }
+
+  result := &TeamReloadApp{
+    Added:           make([]string, 0),
+    Removed:         make([]string, 0),
+    Restarted:       make([]string, 0),
+    Updated:         make([]string, 0),
+    RestartRequired: make([]string, 0),
+  }
+
+  if jsonEqual(team.Timelines, next.Timelines) == false {
+    result.RestartRequired = append(result.RestartRequired, "timelines")
+  }
+  if jsonEqual(team.Timecode, next.Timecode) == false {
+    result.RestartRequired = append(result.RestartRequired, "timecode")
+  }
+
+  team.reloadSettings(plan)
+
+  kept := make(map[*Bot]bool)
+  for _, reload := range plan.bots {
+    if reload.live != nil && reload.isRestart == false {
+      kept[reload.live] = true
+    }
+  }
+
+  emulators := make(map[*Bot]*C3Emelate)
+  for i, bot := range team.GetBots() {
+    if i < len(team.c3EmelateList) && team.c3EmelateList[i] != nil {
+      emulators[bot] = team.c3EmelateList[i]
+    }
+  }
+
+  // Removed and restarted bots leave team.Bots before they are shut down, so team commands never reach a closed bot
+  liveBots := team.GetBots()
+  var remaining []*Bot
+  var remainingEmulators []*C3Emelate
+  for _, bot := range liveBots {
+    if kept[bot] == true {
+      remaining = append(remaining, bot)
+      remainingEmulators = append(remainingEmulators, emulators[bot])
+    }
+  }
+  team.botsMux.Lock()
+  team.Bots = remaining
+  team.c3EmelateList = remainingEmulators
+  team.botsMux.Unlock()
+
+  for _, bot := range liveBots {
+    if kept[bot] == true || plan.hasRestart(bot) == true {
+      continue
+    }
+    team.stopBot(bot)
+    if c3Emelate := emulators[bot]; c3Emelate != nil {
+      if err := c3Emelate.Shutdown(); err != nil {
+        log.Printf("[BotTeam ERROR] Bot %s C3Emulate down error: %v\n", bot.Name, err)
+      }
+    }
+    result.Removed = append(result.Removed, bot.Name)
+  }
+
+  bots := make([]*Bot, len(plan.bots))
+  c3EmelateList := make([]*C3Emelate, len(plan.bots))
+  for i, reload := range plan.bots {
+    switch {
+      case reload.live == nil:
+        if team.isEmulated == true {
+          c3Emelate, err := team.nextEmulator()
+          if err != nil {
+            log.Printf("[BotTeam ERROR] Bot %s C3Emulate error: %v\n", reload.next.Name, err)
+          } else {
+            reload.next.Address = c3Emelate.Address()
+            c3EmelateList[i] = c3Emelate
+          }
+        }
+        team.startBot(reload.next)
+        bots[i] = reload.next
+        result.Added = append(result.Added, reload.next.Name)
+
+      case reload.isRestart == true:
+        team.stopBot(reload.live)
+        if c3Emelate := emulators[reload.live]; c3Emelate != nil {
+          reload.next.Address = c3Emelate.Address()
+          c3EmelateList[i] = c3Emelate
+        }
+        team.startBot(reload.next)
+        bots[i] = reload.next
+        result.Restarted = append(result.Restarted, reload.next.Name)
+
+      default:
+        team.reloadBot(reload)
+        bots[i] = reload.live
+        c3EmelateList[i] = emulators[reload.live]
+        if reload.isUpdate == true {
+          result.Updated = append(result.Updated, reload.live.Name)
+        }
+    }
+  }
+
+  team.botsMux.Lock()
+  team.Bots = bots
+  team.c3EmelateList = c3EmelateList
+  team.botsMux.Unlock()
+
+  // Team methods are refreshed after bots, so paths shared with a removed bot are not lost
+  team.oscServer.Subscribe(team)
+  team.oscServer.Subscribe(team.OSCGuard)
+
+  log.Printf("[BotTeam INFO] Config %s is reloaded: added %v, removed %v, restarted %v, updated %v\n",
+    team.filePath, result.Added, result.Removed, result.Restarted, result.Updated)
+  if len(result.RestartRequired) > 0 {
+    log.Printf("[BotTeam WARNING] Config %s changes of %v are applied at restart only\n", team.filePath, result.RestartRequired)
+  }
+  return result, nil
+}
+
+// reloadPlan validates the new config and matches its bots with running bots by name
+func (team *Team) reloadPlan(next *Team) (*teamReload, error) {
+  if next.Sync != nil {
+    if err := next.Sync.Check(); err != nil {
+      return nil, err
+    }
+  }
+
+  if next.Events != nil {
+    if err := next.Events.Check(); err != nil {
+      return nil, err
+    }
+  }
+
+  if next.OSCGuard != nil {
+    if err := next.OSCGuard.Check(); err != nil {
+      return nil, err
+    }
+  }
+
+  if err := telemetryCheck(next.Telemetry); err != nil {
+    return nil, err
+  }
+
+  plan := &teamReload{
+    next:                  next,
+    isOSCClientChanged:    equalString(team.OSCResponseAddress, next.OSCResponseAddress) == false,
+    isDestinationsChanged: jsonEqual(team.OSCDestinations, next.OSCDestinations) == false,
+  }
+
+  names := make(map[string]bool, len(next.Bots))
+  for _, bot := range next.Bots {
+    if names[bot.Name] == true {
+      return nil, fmt.Errorf("Bot name %q is duplicated", bot.Name)
+    }
+    names[bot.Name] = true
+
+    next.inherit(bot)
+    if err := bot.Check(); err != nil {
+      return nil, err
+    }
+
+    reload := &botReload{next: bot, live: team.GetBotByName(bot.Name)}
+    if reload.live != nil {
+      live := reload.live
+      if team.isEmulated == true {
+        // Emulated bots keep their emulator address
+        bot.Address = live.Address
+      }
+      reload.isUpdate = jsonEqual(live, bot) == false
+      reload.isRestart = live.Address != bot.Address ||
+        (live.IsDegraded() == true && reload.isUpdate == true)
+      reload.isOSCClientChanged = equalString(live.OSCResponseAddress, bot.OSCResponseAddress) == false
+      reload.isDestinationsChanged = jsonEqual(live.OSCDestinations, bot.OSCDestinations) == false
+    }
+    plan.bots = append(plan.bots, reload)
+  }
+  return plan, nil
+}
+
+// up creates OSC outputs of changed team and bot settings
+func (plan *teamReload) up() (err error) {
+  if plan.isOSCClientChanged == true && plan.next.OSCResponseAddress != nil {
+    if plan.oscClient, err = NewOSCClient(*plan.next.OSCResponseAddress); err != nil {
+      return fmt.Errorf("OSCClient creation error: %w", err)
+    }
+  }
+
+  if plan.isDestinationsChanged == true {
+    if err := oscDestinationsUp(plan.next.OSCDestinations); err != nil {
+      return err
+    }
+  }
+
+  for _, reload := range plan.bots {
+    if reload.live == nil || reload.isRestart == true || reload.isUpdate == false {
+      continue
+    }
+
+    if reload.isOSCClientChanged == true && reload.next.OSCResponseAddress != nil {
+      if reload.oscClient, err = NewOSCClient(*reload.next.OSCResponseAddress); err != nil {
+        return fmt.Errorf("Bot %s OSCClient creation error: %w", reload.next.Name, err)
+      }
+    }
+
+    if reload.isDestinationsChanged == true {
+      if err := oscDestinationsUp(reload.next.OSCDestinations); err != nil {
+        return fmt.Errorf("Bot %s %w", reload.next.Name, err)
+      }
+    }
+  }
+  return nil
+}
+
+// release shuts down outputs created by up when the config is rejected
+func (plan *teamReload) release() {
+  if plan.oscClient != nil {
+    plan.oscClient.Shutdown()
+  }
+  if plan.isDestinationsChanged == true {
+    oscDestinationsShutdown(plan.next.OSCDestinations)
+  }
+  for _, reload := range plan.bots {
+    if reload.oscClient != nil {
+      reload.oscClient.Shutdown()
+    }
+    if reload.live != nil && reload.isDestinationsChanged == true {
+      oscDestinationsShutdown(reload.next.OSCDestinations)
+    }
+  }
+}
+
+func (plan *teamReload) hasRestart(bot *Bot) bool {
+  for _, reload := range plan.bots {
+    if reload.live == bot && reload.isRestart == true {
+      return true
+    }
+  }
+  return false
+}
+
+// reloadSettings applies team settings, bots read them through inherit
+func (team *Team) reloadSettings(plan *teamReload) {
+  next := plan.next
+
+  team.settingsMux.Lock()
+  team.OSCRequestPosition = next.OSCRequestPosition
+  team.OSCResponsePosition = next.OSCResponsePosition
+  team.OSCReplyToSender = next.OSCReplyToSender
+  team.OSCRequestTeach = next.OSCRequestTeach
+  team.OSCRequestTimeline = next.OSCRequestTimeline
+
+  var oscClient *OSCClient
+  if plan.isOSCClientChanged == true {
+    oscClient = team.oscClient
+    team.oscClient = plan.oscClient
+    team.OSCResponseAddress = next.OSCResponseAddress
+  }
+
+  var destinations []*OSCDestination
+  if plan.isDestinationsChanged == true {
+    destinations = team.OSCDestinations
+    team.OSCDestinations = next.OSCDestinations
+  }
+
+  team.Telemetry = next.Telemetry
+  team.Home = next.Home
+  team.HomeTolerance = next.HomeTolerance
+  team.HomeSpeed = next.HomeSpeed
+  team.HomeRestoreSpeed = next.HomeRestoreSpeed
+  team.StartupPolicy = next.StartupPolicy
+  team.Limits = next.Limits
+  team.InternalActions = next.InternalActions
+  team.Sync = next.Sync
+  team.settingsMux.Unlock()
+
+  // Replaced outputs are shut down after the lock is released, a send still holding one returns an error
+  if oscClient != nil {
+    oscClient.Shutdown()
+  }
+  oscDestinationsShutdown(destinations)
+
+  // Bots keep the GateEvents pointer, so paths and codes are copied into it
+  events := next.Events
+  if events == nil {
+    events = &GateEvents{}
+  }
+  team.Events.Update(events)
+
+  // OSCServer keeps the OSCGuard pointer, so settings are copied into it and counters are kept
+  guard := next.OSCGuard
+  if guard == nil {
+    guard = &OSCGuard{}
+  }
+  team.OSCGuard.Update(guard)
+
+  team.OSCBundleMaxDelay = next.OSCBundleMaxDelay
+  team.oscServer.SetBundleMaxDelay(team.bundleMaxDelay())
+}
+
+// reloadBot applies new settings to a running bot
+func (team *Team) reloadBot(reload *botReload) {
+  live, next := reload.live, reload.next
+  if reload.isUpdate == false {
+    live.settingsMux.Lock()
+    live.teamOSCDestinations = team.OSCDestinations
+    live.teamInternalActions = team.InternalActions
+    live.settingsMux.Unlock()
+    return
+  }
+
+  live.settingsMux.Lock()
+  live.OSCRequestAxis = next.OSCRequestAxis
+  live.OSCRequestCoords = next.OSCRequestCoords
+  live.OSCRequestPosition = next.OSCRequestPosition
+  live.OSCResponseAxes = next.OSCResponseAxes
+  live.OSCResponseCoords = next.OSCResponseCoords
+  live.OSCResponsePosition = next.OSCResponsePosition
+  live.OSCReplyToSender = next.OSCReplyToSender
+
+  // Replaced outputs are shut down after the lock is released, a send still holding one returns an error
+  var oscClient *OSCClient
+  if reload.isOSCClientChanged == true {
+    oscClient = live.oscClient
+    live.oscClient = reload.oscClient
+    live.OSCResponseAddress = next.OSCResponseAddress
+  }
+
+  var destinations []*OSCDestination
+  if reload.isDestinationsChanged == true {
+    destinations = live.OSCDestinations
+    live.OSCDestinations = next.OSCDestinations
+  }
+  live.teamOSCDestinations = team.OSCDestinations
+
+  live.Telemetry = next.Telemetry
+  live.Home = next.Home
+  live.HomeTolerance = next.HomeTolerance
+  live.HomeSpeed = next.HomeSpeed
+  live.HomeRestoreSpeed = next.HomeRestoreSpeed
+  live.StartupPolicy = next.StartupPolicy
+  live.Limits = next.Limits
+  live.InternalActions = next.InternalActions
+  live.teamInternalActions = team.InternalActions
+  live.settingsMux.Unlock()
+
+  if oscClient != nil {
+    oscClient.Shutdown()
+  }
+  oscDestinationsShutdown(destinations)
+
+  live.mappingMux.Lock()
+  live.OSCMappings = next.OSCMappings
+  live.mappingPaths = next.mappingPaths
+  live.mappingGroups = next.mappingGroups
+  live.mappingMux.Unlock()
+
+  live.SetMoveGroups(next.MoveGroups)
+
+  if live.IsDegraded() == false {
+    team.oscServer.Subscribe(live)
+  }
+  log.Printf("[BotTeam INFO] Bot %s settings are reloaded\n", live.Name)
+}
+
+// startBot brings up a bot of the new config, a failed bot is degraded as at start
+func (team *Team) startBot(bot *Bot) {
+  team.attach(bot)
+  if err := bot.Up(); err != nil {
+    log.Printf("[BotTeam ERROR] Bot %s is degraded, Up failed with error: %v\n", bot.Name, err)
+    return
+  }
+  team.oscServer.Subscribe(bot)
+}
+
+// stopBot waits for the current move to finish before the bot is shut down
+func (team *Team) stopBot(bot *Bot) {
+  team.oscServer.UnSubscribe(bot)
+
+  deadline := time.Now().Add(Team_Reload_IdleTimeout)
+  for bot.IsMovement() == true && time.Now().Before(deadline) {
+    time.Sleep(Team_Reload_IdleStep)
+  }
+  if bot.IsMovement() == true {
+    log.Printf("[BotTeam WARNING] Bot %s is still moving, shutdown after %v\n", bot.Name, Team_Reload_IdleTimeout)
+  }
+
+  if err := bot.Shutdown(); err != nil {
+    log.Printf("[BotTeam ERROR] Bot %s shutdown error: %v\n", bot.Name, err)
+  }
+}
+
+// nextEmulator starts a C3 emulator on the port after the last started one
+func (team *Team) nextEmulator() (*C3Emelate, error) {
+  c3Emelate := NewC3Emelate(team.c3EmelatePort)
+  if err := c3Emelate.ListenAndServe(); err != nil {
+    return nil, err
+  }
+  team.c3EmelatePort++
+  return c3Emelate, nil
+}
+
+func equalString(a *string, b *string) bool {
+  if a == nil || b == nil {
+    return a == b
+  }
+  return *a == *b
+}
+
+func jsonEqual(a any, b any) bool {
+  dataA, errA := json.Marshal(a)
+  dataB, errB := json.Marshal(b)
+  return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+)
+
+const (
+  TeamSync_Speed = 100
+)
+
+type TeamSyncFailure string
+
+const (
+  TeamSyncFailure_Abort    TeamSyncFailure = "abort"    // No bot starts the next waypoint after a failure
+  TeamSyncFailure_Continue TeamSyncFailure = "continue" // Failed bots are dropped, healthy bots continue
+)
+
+type TeamSync struct {
+  Enabled   bool            `json:"enabled"`
+  TimeScale bool            `json:"timeScale"`
+  Speed     *uint8          `json:"speed"`
+  OnFailure TeamSyncFailure `json:"onFailure"`
+}
+
+type teamSyncBot struct {
+  bot       *Bot
+  moveGroup *MoveGroup
+  isBreak   bool
+  err       error
+
+  // Axis speed of the bot before the sync or the base speed when it is unknown, restored when timeScale is enabled
+  velocity     uint8
+  acceleration uint8
+}
+
+func (ts *TeamSync) Check() error {
+  switch ts.OnFailure {
+    case TeamSyncFailure_Abort, TeamSyncFailure_Continue:
+    default:
+      return fmt.Errorf("TeamSync incorrect onFailure %q", ts.OnFailure)
+  }
+
+  if ts.Speed != nil && (*ts.Speed == 0 || *ts.Speed > 100) {
+    return fmt.Errorf("TeamSync speed %d is out of 1..100 range", *ts.Speed)
+  }
+
+  return nil
+}
+
+func (ts *TeamSync) baseSpeed(speed uint16) uint8 {
+  if speed > 0 && speed <= 100 {
+    return uint8(speed)
+  }
+  if ts.Speed != nil {
+    return *ts.Speed
+  }
+  return TeamSync_Speed
+}
+
+// SyncMoveRound runs MoveGroup id on every bot waypoint by waypoint:
+// no bot starts waypoint N+1 before every active bot has finished waypoint N.
+// Motions already started are not interrupted, a failure takes effect at the next barrier.
+func (team *Team) SyncMoveRound(id uint16, speed uint16) (bool, error) {
+  ts := team.sync()
+  baseSpeed := ts.baseSpeed(speed)
+
+  bots := team.GetBots()
+  active := make([]*teamSyncBot, 0, len(bots))
+  for _, bot := range bots {
+    if bot.IsDegraded() == true {
+      log.Printf("[BotTeam WARNING] Bot %s is degraded, sync MoveGroup %d skipped\n", bot.Name, id)
+      continue
+    }
+
+    moveGroup := bot.GetMoveGroup(id)
+    if moveGroup == nil {
+      if ts.OnFailure == TeamSyncFailure_Abort {
+        return false, fmt.Errorf("Bot %s sync MoveGroup %d is not found", bot.Name, id)
+      }
+      log.Printf("[BotTeam WARNING] Bot %s sync MoveGroup %d is not found\n", bot.Name, id)
+      continue
+    }
+
+    if err := bot.checkMoveGroup(moveGroup); err != nil {
+      if ts.OnFailure == TeamSyncFailure_Abort {
+        return false, fmt.Errorf("Bot %s sync %w", bot.Name, err)
+      }
+      log.Printf("[BotTeam WARNING] Bot %s sync MoveGroup %d check error: %v\n", bot.Name, id, err)
+      continue
+    }
+
+    sb := &teamSyncBot{bot: bot, moveGroup: moveGroup}
+    if ts.TimeScale == true {
+      var err error
+      // A program which never set COM_VALUE2 and COM_VALUE4 has no speed to read, the base speed is restored then
+      if sb.velocity, sb.acceleration, err = bot.GetAXISSpeed(); err != nil {
+        sb.velocity, sb.acceleration = baseSpeed, baseSpeed
+        log.Printf("[BotTeam WARNING] Bot %s sync MoveGroup %d speed is unknown, %d%% is restored after sync: %v\n", bot.Name, id, baseSpeed, err)
+      }
+    }
+
+    active = append(active, sb)
+  }
+
+  if len(active) == 0 {
+    return false, fmt.Errorf("Sync MoveGroup %d has no bots to move", id)
+  }
+
+  var steps int = 0
+  for _, sb := range active {
+    if len(sb.moveGroup.Positions) > steps {
+      steps = len(sb.moveGroup.Positions)
+    }
+  }
+
+  if ts.TimeScale == true {
+    participants := append([]*teamSyncBot(nil), active...)
+    defer team.syncRestoreSpeed(participants)
+  }
+
+  for _, sb := range active {
+    team.Events.Event(GateEvent_MoveStarted, sb.bot.Name, int32(id))
+  }
+
+  var failed []*teamSyncBot
+  for n := 0; n < steps; n++ {
+    if ts.TimeScale == true {
+      team.syncScaleSpeed(active, n, baseSpeed)
+    }
+
+    var wg sync.WaitGroup
+    for _, sb := range active {
+      if n >= len(sb.moveGroup.Positions) {
+        continue
+      }
+
+      wg.Add(1)
+      go func(sb *teamSyncBot, step *MoveStep) {
+        defer wg.Done()
+        if isBreak, err := sb.bot.MoveStep(step); err != nil {
+          sb.isBreak = isBreak
+          sb.err = fmt.Errorf("Bot %s MoveGroup %d Position %s move error: %w", sb.bot.Name, id, step.Value(), err)
+        }
+      }(sb, sb.moveGroup.Positions[n])
+    }
+    wg.Wait()
+
+    healthy := active[:0]
+    for _, sb := range active {
+      if sb.err != nil {
+        log.Printf("[BotTeam ERROR] Sync waypoint %d error: %v\n", n, sb.err)
+        failed = append(failed, sb)
+        continue
+      }
+      healthy = append(healthy, sb)
+    }
+    active = healthy
+
+    if len(failed) > 0 && ts.OnFailure == TeamSyncFailure_Abort {
+      log.Printf("[BotTeam ERROR] Sync MoveGroup %d aborted at waypoint %d\n", id, n)
+      return syncFailure(failed)
+    }
+
+    if len(active) == 0 {
+      return syncFailure(failed)
+    }
+  }
+
+  for _, sb := range active {
+    sb.bot.setCurrentMoveGroup(sb.moveGroup)
+    team.Events.Event(GateEvent_MoveDone, sb.bot.Name, int32(id))
+  }
+
+  if len(failed) > 0 {
+    return syncFailure(failed)
+  }
+
+  return false, nil
+}
+
+func syncFailure(failed []*teamSyncBot) (bool, error) {
+  var isBreak bool = false
+  for _, sb := range failed {
+    isBreak = isBreak || sb.isBreak
+  }
+  return isBreak, failed[0].err
+}
+
+// syncScaleSpeed scales E6AXIS speed of every bot by its share of the longest joint travel
+// in waypoint n, so all bots arrive at the same time. E6POS waypoints are not scaled.
+func (team *Team) syncScaleSpeed(active []*teamSyncBot, n int, baseSpeed uint8) {
+  travels := make([]float64, len(active))
+  var maxTravel float64 = 0
+
+  for i, sb := range active {
+    travels[i] = -1
+    if n >= len(sb.moveGroup.Positions) {
+      continue
+    }
+
+    step := sb.moveGroup.Positions[n]
+    if step.IsAction() || step.Position.Type() != PositionType_E6AXIS {
+      continue
+    }
+
+    current := sb.bot.CurrentAxis()
+    var travel float64 = 0
+    for j := 0; j < 6; j++ {
+      travel = math.Max(travel, math.Abs(float64(step.Position.Get(j) - current.Get(j))))
+    }
+    travels[i] = travel
+    maxTravel = math.Max(maxTravel, travel)
+  }
+
+  if maxTravel == 0 {
+    return
+  }
+
+  for i, sb := range active {
+    if travels[i] < 0 {
+      continue
+    }
+
+    speed := uint8(math.Max(1, math.Round(float64(baseSpeed) * travels[i] / maxTravel)))
+    if err := sb.bot.SetAXISSpeed(speed, speed); err != nil {
+      log.Printf("[BotTeam ERROR] Bot %s sync waypoint %d speed error: %v\n", sb.bot.Name, n, err)
+    }
+  }
+}
+
+// syncRestoreSpeed sets every bot back to its own axis speed from before the sync
+func (team *Team) syncRestoreSpeed(active []*teamSyncBot) {
+  for _, sb := range active {
+    if err := sb.bot.SetAXISSpeed(sb.velocity, sb.acceleration); err != nil {
+      log.Printf("[BotTeam ERROR] Bot %s sync speed restore error: %v\n", sb.bot.Name, err)
+    }
+  }
+}
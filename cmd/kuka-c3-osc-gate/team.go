@@ -3,10 +3,14 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -23,16 +27,65 @@ type Team struct {
 
   OSCResponseAddress  *string `json:"oscResponseAddress"`
   OSCResponsePosition *string `json:"oscResponsePositionPath"`
+  OSCReplyToSender    *bool   `json:"oscReplyToSender"`
 
-  Bots []*Bot `json:"bots"`
+  OSCDestinations []*OSCDestination `json:"oscDestinations"`
+  Telemetry       []*Telemetry      `json:"telemetry"`
 
-  oscInput  chan *OSCPacket
+  Home             *Position         `json:"home"`
+  HomeTolerance    *float32          `json:"homeTolerance"`
+  HomeSpeed        *uint8            `json:"homeSpeed"`
+  HomeRestoreSpeed *uint8            `json:"homeRestoreSpeed"`
+  StartupPolicy    *BotStartupPolicy `json:"startupPolicy"`
+  Limits           *BotLimits        `json:"limits"`
+
+  InternalActions []*InternalAction `json:"internalActions"`
+
+  Sync *TeamSync `json:"sync"`
+
+  Events *GateEvents `json:"events"`
+
+  OSCGuard *OSCGuard `json:"oscGuard"`
+
+  // Seconds an OSC bundle timetag may be ahead of the gate clock, later bundles are discarded
+  OSCBundleMaxDelay *float64 `json:"oscBundleMaxDelay"`
+
+  OSCRequestTeach *string `json:"oscRequestTeachPath"`
+
+  OSCRequestTimeline *string     `json:"oscRequestTimelinePath"`
+  Timelines          []*Timeline `json:"timelines"`
+  Timecode           *Timecode   `json:"timecode"`
+
+  Bots    []*Bot `json:"bots"`
+  botsMux sync.RWMutex
+
+  // settingsMux guards OSC paths, outputs and Sync, a config reload replaces them while the team runs
+  settingsMux sync.RWMutex
+
+  oscInput  chan *oscCall
   oscClient *OSCClient
+  oscServer *OSCServer
+
+  oscMonitors    []OSCMonitor
+  oscMonitorsMux sync.RWMutex
+
+  cueClients    map[string]*OSCClient
+  cueClientsMux sync.Mutex
+
+  editMux sync.Mutex
+  isEmulated bool
+
+  teachHistory map[*Bot][]*teachUndo
+  teachMux     sync.Mutex
 
-  isShutdown bool
+  oscDropped atomic.Uint64
+
+  // doneChan is closed on shutdown, oscInput stays open so late senders never hit a closed channel
+  doneChan chan struct{}
   wg sync.WaitGroup
 
   c3EmelateList []*C3Emelate
+  c3EmelatePort uint16
 }
 
 func NewTeam(filePath string) *Team {
@@ -51,12 +104,20 @@ func (team *Team) EmulateC3Servers() error {
     }
     team.c3EmelateList[i] = c3Emelate
   }
+  team.isEmulated = true
+  team.c3EmelatePort = uint16(Team_C3Emelate_StartPort + len(team.c3EmelateList))
   return nil
 }
 
 func (team *Team) Up(oscServer *OSCServer) (err error) {
-  team.oscInput = make(chan *OSCPacket, Team_PacketsBuffer)
-  team.isShutdown = false
+  team.oscInput = make(chan *oscCall, Team_PacketsBuffer)
+  team.doneChan = make(chan struct{})
+
+  if team.Sync != nil {
+    if err := team.Sync.Check(); err != nil {
+      return err
+    }
+  }
 
   if team.OSCResponseAddress != nil {
     if team.oscClient, err = NewOSCClient(*team.OSCResponseAddress); err != nil {
@@ -66,10 +127,37 @@ func (team *Team) Up(oscServer *OSCServer) (err error) {
     team.oscClient = nil
   }
 
+  if err := oscDestinationsUp(team.OSCDestinations); err != nil {
+    return err
+  }
+
+  team.oscServer = oscServer
+
+  // Errors and events are sent with default paths and codes when not configured
+  if team.Events == nil {
+    team.Events = &GateEvents{}
+  }
+  if err := team.Events.Check(); err != nil {
+    return err
+  }
+  team.Events.send = team.oscReply
+
+  // OSC input is accepted from every source when the guard is not configured
+  if team.OSCGuard == nil {
+    team.OSCGuard = &OSCGuard{}
+  }
+  if err := team.OSCGuard.Up(team.Events); err != nil {
+    return err
+  }
+  oscServer.SetGuard(team.OSCGuard)
+
+  if team.OSCBundleMaxDelay != nil && *team.OSCBundleMaxDelay <= 0 {
+    return fmt.Errorf("OSC bundle max delay %v must be positive", *team.OSCBundleMaxDelay)
+  }
+  oscServer.SetBundleMaxDelay(team.bundleMaxDelay())
+
   for i, bot := range team.Bots {
-    if bot.OSCResponseAddress == nil && team.OSCResponseAddress != nil {
-      bot.OSCResponseAddress = team.OSCResponseAddress
-    }
+    team.inherit(bot)
 
     c3Emelate := team.c3EmelateList[i]
     if c3Emelate != nil {
@@ -77,33 +165,117 @@ func (team *Team) Up(oscServer *OSCServer) (err error) {
     }
     
     if err := bot.Up(); err != nil {
-      return fmt.Errorf("Bot %s Up failed with error %v\n", bot.Name, err)
+      log.Printf("[BotTeam ERROR] Bot %s is degraded, Up failed with error: %v\n", bot.Name, err)
+      continue
     }
     
     oscServer.Subscribe(bot)
   }
 
+  team.cueClients = make(map[string]*OSCClient)
+  for _, timeline := range team.Timelines {
+    if err := timeline.Up(team); err != nil {
+      return err
+    }
+  }
+
+  if team.Timecode != nil {
+    if err := team.Timecode.Up(team); err != nil {
+      return err
+    }
+  }
+
   team.wg.Add(1)
   go team.processOSCPackets()
 
   oscServer.Subscribe(team)
+  oscServer.Subscribe(team.OSCGuard)
 
   return nil
 }
 
+// inherit copies team settings into bot settings which are not set
+func (team *Team) inherit(bot *Bot) {
+  if bot.OSCResponseAddress == nil && team.OSCResponseAddress != nil {
+    bot.OSCResponseAddress = team.OSCResponseAddress
+  }
+
+  if bot.OSCReplyToSender == nil && team.OSCReplyToSender != nil {
+    bot.OSCReplyToSender = team.OSCReplyToSender
+  }
+  if bot.Telemetry == nil && team.Telemetry != nil {
+    bot.Telemetry = team.Telemetry
+  }
+
+  team.attach(bot)
+
+  if bot.Home == nil && team.Home != nil {
+    bot.Home = team.Home
+  }
+
+  if bot.HomeTolerance == nil && team.HomeTolerance != nil {
+    bot.HomeTolerance = team.HomeTolerance
+  }
+
+  if bot.HomeSpeed == nil && team.HomeSpeed != nil {
+    bot.HomeSpeed = team.HomeSpeed
+  }
+
+  if bot.HomeRestoreSpeed == nil && team.HomeRestoreSpeed != nil {
+    bot.HomeRestoreSpeed = team.HomeRestoreSpeed
+  }
+
+  if bot.StartupPolicy == nil && team.StartupPolicy != nil {
+    bot.StartupPolicy = team.StartupPolicy
+  }
+
+  if bot.Limits == nil && team.Limits != nil {
+    bot.Limits = team.Limits
+  }
+}
+
+// attach connects bot outputs to the running team
+func (team *Team) attach(bot *Bot) {
+  bot.oscServer = team.oscServer
+  bot.oscOutput = team.oscOutput
+  bot.teamOSCDestinations = team.OSCDestinations
+  bot.teamInternalActions = team.InternalActions
+  bot.events = team.Events
+}
+
 func (team *Team) Shutdown() error {
-  team.isShutdown = true
-  close(team.oscInput)
-  if team.oscClient != nil {
-    team.oscClient.Shutdown()
+  close(team.doneChan)
+  team.settingsMux.RLock()
+  oscClient, destinations := team.oscClient, team.OSCDestinations
+  team.settingsMux.RUnlock()
+  if oscClient != nil {
+    oscClient.Shutdown()
   }
 
-  for _, bot := range team.Bots {
+  if team.Timecode != nil {
+    team.Timecode.Shutdown()
+  }
+
+  for _, timeline := range team.Timelines {
+    timeline.Shutdown()
+  }
+
+  team.cueClientsMux.Lock()
+  for _, oscClient := range team.cueClients {
+    oscClient.Shutdown()
+  }
+  team.cueClients = make(map[string]*OSCClient)
+  team.cueClientsMux.Unlock()
+
+  for _, bot := range team.GetBots() {
     if err := bot.Shutdown(); err != nil {
       return fmt.Errorf("Bot %s Down failed with error %v\n", bot.Name, err)
     }
   }
 
+  // Bots without own destinations send to team destinations, so they are shut down after bots
+  oscDestinationsShutdown(destinations)
+
   for i, c3Emelate := range team.c3EmelateList {
     if c3Emelate != nil {
       if err := c3Emelate.Shutdown(); err != nil {
@@ -140,6 +312,7 @@ func (team *Team) Read() error {
   return nil
 }
 
+// Write replaces the config file atomically: a temporary file in the same directory is renamed over it
 func (team *Team) Write() error {
   team.fileMux.Lock()
   defer team.fileMux.Unlock()
@@ -149,111 +322,460 @@ func (team *Team) Write() error {
     return fmt.Errorf("JSON serialization error: %w", err) 
   }
 
-  if err := ioutil.WriteFile(team.filePath, jsonData, 0644); err != nil {
+  file, err := os.CreateTemp(filepath.Dir(team.filePath), filepath.Base(team.filePath) + ".*.tmp")
+  if err != nil {
+    return fmt.Errorf("Write file error: %w", err)
+  }
+  defer os.Remove(file.Name())
+
+  if _, err := file.Write(jsonData); err != nil {
+    file.Close()
     return fmt.Errorf("Write file error: %w", err) 
   }
+  if err := file.Sync(); err != nil {
+    file.Close()
+    return fmt.Errorf("Write file sync error: %w", err)
+  }
+  if err := file.Close(); err != nil {
+    return fmt.Errorf("Write file close error: %w", err)
+  }
+  if err := os.Chmod(file.Name(), 0644); err != nil {
+    return fmt.Errorf("Write file mode error: %w", err)
+  }
+
+  if err := os.Rename(file.Name(), team.filePath); err != nil {
+    return fmt.Errorf("Write file rename error: %w", err)
+  }
+
+  return nil
+}
+
+// EditMoveGroups applies edit to a copy of the bot MoveGroups, validates the result, applies it live
+// and persists it to the config file. The live list is restored when the config can not be written.
+func (team *Team) EditMoveGroups(bot *Bot, edit func(moveGroups []*MoveGroup) ([]*MoveGroup, error)) error {
+  team.editMux.Lock()
+  defer team.editMux.Unlock()
+
+  moveGroups, err := edit(bot.GetMoveGroups())
+  if err != nil {
+    return err
+  }
+
+  if err := bot.CheckMoveGroups(moveGroups); err != nil {
+    return err
+  }
 
+  previous := bot.SetMoveGroups(moveGroups)
+  if err := team.saveMoveGroups(); err != nil {
+    bot.SetMoveGroups(previous)
+    return err
+  }
+
+  log.Printf("[BotTeam INFO] Bot %s MoveGroups are saved to %s\n", bot.Name, team.filePath)
   return nil
 }
 
-func (team *Team) OSCPacket(oscPacket *OSCPacket) {
+// saveMoveGroups writes live MoveGroups into a fresh copy of the config file,
+// so values inherited from the team at Up are not written into bots
+func (team *Team) saveMoveGroups() error {
+  config := NewTeam(team.filePath)
+  if err := config.Read(); err != nil {
+    return err
+  }
+
+  for _, bot := range config.Bots {
+    if live := team.GetBotByName(bot.Name); live != nil {
+      bot.MoveGroups = live.GetMoveGroups()
+    }
+  }
+
+  return config.Write()
+}
+
+func (team *Team) OSCMethods() []*OSCMethod {
+  team.settingsMux.RLock()
+  defer team.settingsMux.RUnlock()
+
+  var methods []*OSCMethod
+  if team.OSCRequestPosition != nil {
+    methods = append(methods, NewOSCMotionMethod(*team.OSCRequestPosition, team.oscQueue(team.processOSCPosition)))
+  }
+
+  // Timecode chases timelines, so it moves robots as well
+  if team.Timecode != nil && team.Timecode.OSCPath != nil {
+    methods = append(methods, NewOSCMotionMethod(*team.Timecode.OSCPath, team.oscQueue(team.Timecode.OSCPacket)))
+  }
+
+  if team.OSCRequestTimeline != nil {
+    for _, command := range []TimelineCommand{
+      TimelineCommand_Go, TimelineCommand_Back, TimelineCommand_Jump, TimelineCommand_Play, TimelineCommand_Stop,
+    } {
+      method := NewOSCMethod(*team.OSCRequestTimeline + "/" + string(command), team.oscQueue(func(oscPacket *OSCPacket) {
+        team.processOSCTimeline(command, oscPacket)
+      }))
+      // Stop is accepted while disarmed
+      method.IsMotion = command != TimelineCommand_Stop
+      methods = append(methods, method)
+    }
+  }
+
+  if team.OSCRequestTeach != nil {
+    methods = append(methods, NewOSCMethod(*team.OSCRequestTeach, team.oscQueue(team.processOSCTeach)))
+    methods = append(methods, NewOSCMethod(*team.OSCRequestTeach + "/undo", team.oscQueue(team.processOSCTeachUndo)))
+  }
+
+  return methods
+}
+
+func (team *Team) oscQueue(handler OSCHandler) OSCHandler {
+  return func(oscPacket *OSCPacket) {
+    select {
+      case team.oscInput <- &oscCall{handler: handler, packet: oscPacket}:
+      default:
+        team.oscDropped.Add(1)
+        log.Printf("[BotTeam WARNING] OSC Input channel is full, discarding packet\n")
+    }
+  }
+}
+
+// Alive checks that the team loop handles a queued call within timeout, it is the gate liveness probe
+func (team *Team) Alive(timeout time.Duration) bool {
+  if team.doneChan == nil {
+    return false
+  }
+
+  handledChan := make(chan struct{})
+  timer := time.NewTimer(timeout)
+  defer timer.Stop()
+
+  select {
+    case team.oscInput <- &oscCall{handler: func(*OSCPacket) { close(handledChan) }}:
+    case <-team.doneChan:
+      return false
+    case <-timer.C:
+      return false
+  }
+
   select {
-    case team.oscInput <- oscPacket:
-    default:
-      log.Printf("[BotTeam WARNING] OSC Input channel is full, discarding packet\n")
+    case <-handledChan:
+      return true
+    case <-team.doneChan:
+      return false
+    case <-timer.C:
+      return false
   }
 }
 
+// GetReadyApp reports every bot state, the team is ready when all bots can be commanded
+func (team *Team) GetReadyApp() *ReadyApp {
+  bots := team.GetBots()
+  ready := &ReadyApp{Ready: true, Bots: make([]*BotStateApp, len(bots))}
+  for i, bot := range bots {
+    ready.Bots[i] = bot.GetStateApp()
+    if bot.IsReady() == false {
+      ready.Ready = false
+    }
+  }
+  return ready
+}
+
 func (team *Team) processOSCPackets() {
   defer team.wg.Done()
 
-  for packet := range team.oscInput {
-    if team.OSCRequestPosition != nil && packet.Path == *team.OSCRequestPosition {
-      team.processOSCPosition(packet)
-      continue
+  for {
+    select {
+      case call := <-team.oscInput:
+        call.handler(call.packet)
+      case <-team.doneChan:
+        return
     }
   }
 }
 
+func (team *Team) processOSCTimeline(command TimelineCommand, oscPacket *OSCPacket) {
+  values := oscPacket.Values()
+  if len(values) < 1 || len(values) > 2 {
+    log.Printf("[BotTeam ERROR] Incorrect OSC Timeline values length of %+v\n", values)
+    team.Events.Error(oscPacket.Source, GateError_BadArity, "", fmt.Sprintf("Timeline %s expects 1 or 2 values, got %d", command, len(values)))
+    return
+  }
+
+  args := make([]string, 2)
+  for i, value := range values {
+    switch v := value.(type) {
+      case string:
+        args[i] = v
+      case OSCSymbol:
+        args[i] = string(v)
+      default:
+        n, err := OSCInt32(value)
+        if err != nil {
+          log.Printf("[BotTeam ERROR] OSC Timeline values[%d] is not of int or string value: %v\n", i, err)
+          team.Events.Error(oscPacket.Source, GateError_BadType, "", fmt.Sprintf("Timeline values[%d] is not of int or string value", i))
+          return
+        }
+        args[i] = strconv.Itoa(int(n))
+    }
+  }
+
+  timeline := team.GetTimeline(args[0])
+  if timeline == nil {
+    log.Printf("[BotTeam ERROR] OSC Timeline %s is not found\n", args[0])
+    team.Events.Error(oscPacket.Source, GateError_UnknownTimeline, "", fmt.Sprintf("Timeline %s is not found", args[0]))
+    return
+  }
+
+  if err := timeline.Command(command, args[1]); err != nil {
+    log.Printf("[BotTeam ERROR] OSC Timeline command error: %v\n", err)
+    team.Events.Error(oscPacket.Source, GateError_CommandFailed, "", err.Error())
+  }
+}
+
 func (team *Team) processOSCPosition(oscPacket *OSCPacket) {
   values := oscPacket.Values()
   if len(values) != 3 {
     log.Printf("[Bot ERROR] Incorrect OSC Position values length of %+v\n", values)
+    team.Events.Error(oscPacket.Source, GateError_BadArity, "", fmt.Sprintf("Position expects 3 values, got %d", len(values)))
     return
   }
 
-  var id uint16 = uint16(values[0].(int32))
-  var _ uint16 = uint16(values[1].(int32)) // Speed
-  var index int32 = values[2].(int32)
+  args, err := oscPositionArgs(values)
+  if err != nil {
+    log.Printf("[BotTeam ERROR] OSC Position %v\n", err)
+    team.Events.Error(oscPacket.Source, GateError_BadType, "", err.Error())
+    if err := team.oscResponsePosition(oscPacket.Source, OSCOutputStatus_Error, 0, 0); err != nil {
+      log.Printf("[BotTeam ERROR] OSC error response error %v\n", err)
+    }
+    return
+  }
 
-  go func(index int32, id uint16) {
+  var id uint16 = uint16(args[0])
+  var speed uint16 = uint16(args[1])
+  var index int32 = args[2]
 
-    var wg sync.WaitGroup
-    errorChan := make(chan error, len(team.Bots))
-    breakChan := make(chan bool, len(team.Bots))
-    
-    for _, bot := range team.Bots {
-      wg.Add(1)
-      go func(bot *Bot, id uint16) {
-        defer wg.Done()
-
-        moveGroup := bot.GetMoveGroup(id)
-        if moveGroup == nil {
-          log.Printf("[BotTeam WARNING] Bot %s OSC MoveGroup %d is not found\n", bot.Name, id)
-          return
-        }
+  if team.hasMoveGroup(id) == false {
+    log.Printf("[BotTeam ERROR] OSC Position MoveGroup %d is not found\n", id)
+    team.Events.Error(oscPacket.Source, GateError_UnknownMoveGroup, "", fmt.Sprintf("MoveGroup %d is not found", id))
+    if err := team.oscResponsePosition(oscPacket.Source, OSCOutputStatus_Error, index, id); err != nil {
+      log.Printf("[BotTeam ERROR] OSC error response error %v\n", err)
+    }
+    return
+  }
 
-        if isBreak, err := bot.MoveRound(moveGroup); err != nil {
-          log.Printf("[BotTeam ERROR] Bot %s OSC Position error: %v\n", bot.Name, err)
-          errorChan <- err
-          breakChan <- isBreak
-        }
-      }(bot, id)
+  go func(index int32, id uint16, speed uint16) {
+    var isBreak bool
+    var err error
+    if ts := team.sync(); ts != nil && ts.Enabled == true {
+      isBreak, err = team.SyncMoveRound(id, speed)
+    } else {
+      isBreak, err = team.MoveRound(id)
     }
 
-    wg.Wait()
-    close(errorChan)
-    close(breakChan)
-
-    for err := range errorChan {
-      if err != nil {
-        status := OSCOutputStatus_Error
-        loop: for isBreak := range breakChan {
-          if isBreak == true {
-             status = OSCOutputStatus_Break
-             break loop
-          }
-        }
-        if err := team.oscResponsePosition(status, index, id); err != nil {
-          log.Printf("[BotTeam ERROR] OSC error move response error %v\n", err)
-        }
-        return
+    if err != nil {
+      log.Printf("[BotTeam ERROR] OSC Position MoveGroup %d error: %v\n", id, err)
+      status := OSCOutputStatus_Error
+      kind := GateError_MoveFailed
+      if isBreak == true {
+        status = OSCOutputStatus_Break
+        kind = GateError_MoveBreak
       }
+      team.Events.Error(oscPacket.Source, kind, "", err.Error())
+      if err := team.oscResponsePosition(oscPacket.Source, status, index, id); err != nil {
+        log.Printf("[BotTeam ERROR] OSC error move response error %v\n", err)
+      }
+      return
     }
 
+    if err := team.oscResponsePosition(oscPacket.Source, OSCOutputStatus_OK, index, id); err != nil {
+      log.Printf("[Bot ERROR] OSC sucess move response error %v\n", err)
+    }
+  }(index, id, speed)
+}
 
+func (team *Team) hasMoveGroup(id uint16) bool {
+  for _, bot := range team.GetBots() {
+    if bot.GetMoveGroup(id) != nil {
+      return true
+    }
+  }
+  return false
+}
 
-    if err := team.oscResponsePosition(OSCOutputStatus_OK, index, id); err != nil {
-      log.Printf("[Bot ERROR] OSC sucess move response error %v\n", err)
+func (team *Team) bundleMaxDelay() time.Duration {
+  if team.OSCBundleMaxDelay != nil {
+    return time.Duration(*team.OSCBundleMaxDelay * float64(time.Second))
+  }
+  return OSCServer_BundleMaxDelay
+}
+
+func (team *Team) sync() *TeamSync {
+  team.settingsMux.RLock()
+  defer team.settingsMux.RUnlock()
+  return team.Sync
+}
+
+func (team *Team) RunMoveGroup(id uint16) (bool, error) {
+  if ts := team.sync(); ts != nil && ts.Enabled == true {
+    return team.SyncMoveRound(id, 0)
+  }
+  return team.MoveRound(id)
+}
+
+func (team *Team) MoveRound(id uint16) (bool, error) {
+  var wg sync.WaitGroup
+  bots := team.GetBots()
+  errorChan := make(chan error, len(bots))
+  breakChan := make(chan bool, len(bots))
+  
+  for _, bot := range bots {
+    if bot.IsDegraded() == true {
+      log.Printf("[BotTeam WARNING] Bot %s is degraded, MoveGroup %d skipped\n", bot.Name, id)
+      continue
     }
-  }(index, id)
+
+    wg.Add(1)
+    go func(bot *Bot, id uint16) {
+      defer wg.Done()
+
+      moveGroup := bot.GetMoveGroup(id)
+      if moveGroup == nil {
+        log.Printf("[BotTeam WARNING] Bot %s MoveGroup %d is not found\n", bot.Name, id)
+        return
+      }
+
+      if isBreak, err := bot.MoveRound(moveGroup); err != nil {
+        log.Printf("[BotTeam ERROR] Bot %s MoveGroup error: %v\n", bot.Name, err)
+        errorChan <- fmt.Errorf("Bot %s %w", bot.Name, err)
+        breakChan <- isBreak
+      }
+    }(bot, id)
+  }
+
+  wg.Wait()
+  close(errorChan)
+  close(breakChan)
+
+  var isBreak bool = false
+  for value := range breakChan {
+    isBreak = isBreak || value
+  }
+
+  for err := range errorChan {
+    return isBreak, err
+  }
+
+  return false, nil
 }
 
-func (team *Team) oscResponsePosition(status OSCOutputStatus, index int32, positionId uint16) error {
-  if team.oscClient == nil {
+// oscResponsePosition replies to the request sender when oscReplyToSender is set, else to oscResponseAddress
+func (team *Team) oscResponsePosition(source net.Addr, status OSCOutputStatus, index int32, positionId uint16) error {
+  team.settingsMux.RLock()
+  path := team.OSCResponsePosition
+  team.settingsMux.RUnlock()
+  if path == nil {
     return nil
   }
 
-  if team.OSCResponsePosition == nil {
+  oscPacket, err := NewOSCResponsePosition(*path, status, index, positionId)
+  if err != nil {
+    return err
+  }
+
+  return team.oscReply(source, oscPacket)
+}
+
+// oscReply sends to the request sender when oscReplyToSender is set and source is known,
+// else to oscResponseAddress and every team destination
+func (team *Team) oscReply(source net.Addr, oscPacket *OSCPacket) error {
+  team.oscOutput(oscPacket)
+
+  team.settingsMux.RLock()
+  replyToSender, oscClient, destinations := team.OSCReplyToSender, team.oscClient, team.OSCDestinations
+  team.settingsMux.RUnlock()
+
+  if replyToSender != nil && *replyToSender == true && source != nil && team.oscServer != nil {
+    return team.oscServer.Reply(source, oscPacket)
+  }
+
+  var result error
+  if oscClient != nil {
+    result = oscClient.Send(oscPacket)
+  }
+  if err := oscDestinationsSend(destinations, oscPacket); err != nil && result == nil {
+    result = err
+  }
+  return result
+}
+
+// OSCMonitor observes every OSC packet the team and its bots send out
+type OSCMonitor interface {
+  OSCOutput(oscPacket *OSCPacket)
+}
+
+func (team *Team) AddOSCMonitor(monitor OSCMonitor) {
+  team.oscMonitorsMux.Lock()
+  team.oscMonitors = append(team.oscMonitors, monitor)
+  team.oscMonitorsMux.Unlock()
+}
+
+func (team *Team) RemoveOSCMonitor(monitor OSCMonitor) {
+  team.oscMonitorsMux.Lock()
+  defer team.oscMonitorsMux.Unlock()
+  for i, other := range team.oscMonitors {
+    if other == monitor {
+      team.oscMonitors = append(team.oscMonitors[:i], team.oscMonitors[i+1:]...)
+      return
+    }
+  }
+}
+
+func (team *Team) oscOutput(oscPacket *OSCPacket) {
+  team.oscMonitorsMux.RLock()
+  defer team.oscMonitorsMux.RUnlock()
+  for _, monitor := range team.oscMonitors {
+    monitor.OSCOutput(oscPacket)
+  }
+}
+
+// GetBots returns a snapshot of the bots, the list changes on config reload
+func (team *Team) GetBots() []*Bot {
+  team.botsMux.RLock()
+  defer team.botsMux.RUnlock()
+  return append([]*Bot(nil), team.Bots...)
+}
+
+func (team *Team) GetBot(id int) *Bot {
+  team.botsMux.RLock()
+  defer team.botsMux.RUnlock()
+  if id < 0 || id >= len(team.Bots) {
     return nil
   }
+  return team.Bots[id]
+}
 
-  return team.oscClient.ResponsePosition(*team.OSCResponsePosition, status, index, positionId)
+func (team *Team) GetBotByName(name string) *Bot {
+  team.botsMux.RLock()
+  defer team.botsMux.RUnlock()
+  for _, bot := range team.Bots {
+    if bot.Name == name {
+      return bot
+    }
+  }
+  return nil
+}
+
+func (team *Team) GetTimelinesAppData() []*TimelineApp {
+  timelinesAppData := make([]*TimelineApp, len(team.Timelines))
+  for i, timeline := range team.Timelines {
+    timelinesAppData[i] = timeline.GetAppData()
+  }
+  return timelinesAppData
 }
 
 func (team *Team) GetAppData() []*BotApp {
-  teamAppData := make([]*BotApp, len(team.Bots))
-  for i, bot := range team.Bots {
+  bots := team.GetBots()
+  teamAppData := make([]*BotApp, len(bots))
+  for i, bot := range bots {
     teamAppData[i] = bot.GetAppData()
   }
   return teamAppData
@@ -0,0 +1,233 @@
+package main
+
+import (
+  "fmt"
+  "math"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  Telemetry_BotPlaceholder = "{bot}"
+)
+
+type TelemetryFormat string
+
+const (
+  TelemetryFormat_Axes           TelemetryFormat = "axes"           // A1..A6
+  TelemetryFormat_AxesExternal   TelemetryFormat = "axesExternal"   // A1..A6, E1..E6
+  TelemetryFormat_Coords         TelemetryFormat = "coords"         // X, Y, Z, A, B, C
+  TelemetryFormat_Quaternion     TelemetryFormat = "quaternion"     // X, Y, Z, W, QX, QY, QZ
+  TelemetryFormat_Matrix         TelemetryFormat = "matrix"         // X, Y, Z, R11..R33 row-major
+  TelemetryFormat_AxesVelocity   TelemetryFormat = "axesVelocity"   // A1..A6 in deg/s
+  TelemetryFormat_CoordsVelocity TelemetryFormat = "coordsVelocity" // X, Y, Z in mm/s, A, B, C in deg/s
+)
+
+// TelemetrySample is one polled bot pose, velocities are derived from the previous sample
+type TelemetrySample struct {
+  Seq            uint64
+  Time           time.Time
+  Axis           *Position
+  Coords         *Position
+  AxisVelocity   [6]float32
+  CoordsVelocity [6]float32
+}
+
+func NewTelemetrySample(seq uint64, axis *Position, coords *Position, previous *TelemetrySample) *TelemetrySample {
+  sample := &TelemetrySample{
+    Seq:    seq,
+    Time:   time.Now(),
+    Axis:   axis,
+    Coords: coords,
+  }
+
+  if previous == nil {
+    return sample
+  }
+
+  dt := sample.Time.Sub(previous.Time).Seconds()
+  if dt <= 0 {
+    return sample
+  }
+
+  for i := 0; i < 6; i++ {
+    sample.AxisVelocity[i] = float32(float64(axis.Get(i) - previous.Axis.Get(i)) / dt)
+    delta := float64(coords.Get(i) - previous.Coords.Get(i))
+    if i >= 3 {
+      // A, B, C wrap at +-180 degrees
+      delta = math.Remainder(delta, 360)
+    }
+    sample.CoordsVelocity[i] = float32(delta / dt)
+  }
+  return sample
+}
+
+// Telemetry is a pose payload sent on every sample with an optional rate cap and change-only deadband.
+// Path may contain {bot} which is replaced by the bot name.
+type Telemetry struct {
+  Path      string          `json:"path"`
+  Format    TelemetryFormat `json:"format"`
+  Timestamp bool            `json:"timestamp"` // Prepend sample time as OSC timetag
+  Sequence  bool            `json:"sequence"`  // Prepend sample sequence number as int64
+  MaxRate   *float64        `json:"maxRate"`   // Maximum messages per second per bot, null is unlimited
+  Deadband  *float32        `json:"deadband"`  // Send only when a value changes by more than deadband
+
+  state    map[string]*telemetryState
+  stateMux sync.Mutex
+}
+
+type telemetryState struct {
+  lastTime   time.Time
+  lastValues []float32
+}
+
+func (t *Telemetry) Check() error {
+  if t.Path == "" {
+    return fmt.Errorf("Telemetry path is empty")
+  }
+
+  switch t.Format {
+    case TelemetryFormat_Axes, TelemetryFormat_AxesExternal, TelemetryFormat_Coords, TelemetryFormat_Quaternion,
+      TelemetryFormat_Matrix, TelemetryFormat_AxesVelocity, TelemetryFormat_CoordsVelocity:
+    default:
+      return fmt.Errorf("Telemetry %s incorrect format %q", t.Path, t.Format)
+  }
+
+  if t.MaxRate != nil && *t.MaxRate <= 0 {
+    return fmt.Errorf("Telemetry %s incorrect maxRate %f", t.Path, *t.MaxRate)
+  }
+
+  if t.Deadband != nil && *t.Deadband < 0 {
+    return fmt.Errorf("Telemetry %s incorrect deadband %f", t.Path, *t.Deadband)
+  }
+  return nil
+}
+
+func (t *Telemetry) Values(sample *TelemetrySample) []float32 {
+  switch t.Format {
+    case TelemetryFormat_Axes:
+      return []float32{
+        sample.Axis.A1(nil), sample.Axis.A2(nil), sample.Axis.A3(nil), sample.Axis.A4(nil), sample.Axis.A5(nil), sample.Axis.A6(nil),
+      }
+
+    case TelemetryFormat_AxesExternal:
+      return []float32{
+        sample.Axis.A1(nil), sample.Axis.A2(nil), sample.Axis.A3(nil), sample.Axis.A4(nil), sample.Axis.A5(nil), sample.Axis.A6(nil),
+        sample.Axis.E1(nil), sample.Axis.E2(nil), sample.Axis.E3(nil), sample.Axis.E4(nil), sample.Axis.E5(nil), sample.Axis.E6(nil),
+      }
+
+    case TelemetryFormat_Coords:
+      return []float32{
+        sample.Coords.X(nil), sample.Coords.Y(nil), sample.Coords.Z(nil), sample.Coords.A(nil), sample.Coords.B(nil), sample.Coords.C(nil),
+      }
+
+    case TelemetryFormat_Quaternion:
+      values := []float32{sample.Coords.X(nil), sample.Coords.Y(nil), sample.Coords.Z(nil)}
+      for _, q := range sample.Coords.Quaternion() {
+        values = append(values, float32(q))
+      }
+      return values
+
+    case TelemetryFormat_Matrix:
+      values := []float32{sample.Coords.X(nil), sample.Coords.Y(nil), sample.Coords.Z(nil)}
+      for _, r := range sample.Coords.RotationMatrix() {
+        values = append(values, float32(r))
+      }
+      return values
+
+    case TelemetryFormat_AxesVelocity:
+      return append([]float32(nil), sample.AxisVelocity[:]...)
+
+    case TelemetryFormat_CoordsVelocity:
+      return append([]float32(nil), sample.CoordsVelocity[:]...)
+  }
+  return nil
+}
+
+// Packet returns nil when the sample is skipped by the rate cap or the deadband
+func (t *Telemetry) Packet(bot string, sample *TelemetrySample) (*OSCPacket, error) {
+  values := t.Values(sample)
+
+  t.stateMux.Lock()
+  if t.state == nil {
+    t.state = make(map[string]*telemetryState)
+  }
+  state, ok := t.state[bot]
+  if ok == false {
+    state = &telemetryState{}
+    t.state[bot] = state
+  }
+
+  if t.MaxRate != nil && state.lastValues != nil &&
+    sample.Time.Sub(state.lastTime) < time.Duration(float64(time.Second) / *t.MaxRate) {
+    t.stateMux.Unlock()
+    return nil, nil
+  }
+
+  // Deadband is compared with the last sent values, so slow drift is sent once it adds up past the deadband.
+  // A pose dropped by maxRate is sent with a later sample, a resting pose within the deadband of the last sent one is not.
+  if t.Deadband != nil && state.lastValues != nil && telemetryChange(state.lastValues, values) <= *t.Deadband {
+    t.stateMux.Unlock()
+    return nil, nil
+  }
+
+  state.lastTime = sample.Time
+  state.lastValues = values
+  t.stateMux.Unlock()
+
+  oscPacket := NewOSCPacket()
+  oscPacket.Path = strings.ReplaceAll(t.Path, Telemetry_BotPlaceholder, bot)
+  if t.Timestamp == true {
+    if err := oscPacket.Append(NewOSCTimetag(sample.Time)); err != nil {
+      return nil, err
+    }
+  }
+  if t.Sequence == true {
+    if err := oscPacket.Append(int64(sample.Seq)); err != nil {
+      return nil, err
+    }
+  }
+  for _, value := range values {
+    if err := oscPacket.Append(value); err != nil {
+      return nil, err
+    }
+  }
+  return oscPacket, nil
+}
+
+func telemetryChange(last []float32, values []float32) float32 {
+  var change float32 = 0
+  for i := range values {
+    if i >= len(last) {
+      return float32(math.Inf(1))
+    }
+    if delta := float32(math.Abs(float64(values[i] - last[i]))); delta > change {
+      change = delta
+    }
+  }
+  return change
+}
+
+func telemetryCheck(telemetry []*Telemetry) error {
+  for _, t := range telemetry {
+    if err := t.Check(); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func telemetrySend(telemetry []*Telemetry, bot string, sample *TelemetrySample, send func(*OSCPacket) error) error {
+  var result error
+  for _, t := range telemetry {
+    oscPacket, err := t.Packet(bot, sample)
+    if err == nil && oscPacket != nil {
+      err = send(oscPacket)
+    }
+    if err != nil && result == nil {
+      result = err
+    }
+  }
+  return result
+}
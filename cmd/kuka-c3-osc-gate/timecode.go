@@ -0,0 +1,381 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+  Timecode_FPS = 25
+  Timecode_Freewheel = 2 * time.Second
+  Timecode_JumpThreshold = 0.1
+  Timecode_UDPBuffer = 256
+  Timecode_UDPAddress = "/timecode" // Guard address of UDP timecode when oscPath is not set
+)
+
+type TimecodeSource string
+
+const (
+  TimecodeSource_OSC TimecodeSource = "osc"
+  TimecodeSource_UDP TimecodeSource = "udp"
+)
+
+type TimecodeState string
+
+const (
+  TimecodeState_Stopped   TimecodeState = "stopped"   // No timecode, timeline is not chased
+  TimecodeState_Locked    TimecodeState = "locked"    // Timecode is received, timeline chases it
+  TimecodeState_Paused    TimecodeState = "paused"    // Repeated frames of a paused transport, timeline holds
+  TimecodeState_Freewheel TimecodeState = "freewheel" // Timecode dropout, timeline runs on the local clock
+)
+
+var Timecode_RE = regexp.MustCompile(`^\s*(\d{1,2})[:;.](\d{2})[:;.](\d{2})[:;.](\d{2})\s*$`)
+
+type Timecode struct {
+  FPS           *float64 `json:"fps"`
+  OSCPath       *string  `json:"oscPath"`
+  UDPPort       *uint16  `json:"udpPort"`
+  Timeline      string   `json:"timeline"`
+  Offset        float64  `json:"offset"`
+  Freewheel     *float64 `json:"freewheel"`
+  JumpThreshold *float64 `json:"jumpThreshold"`
+  Sources       []string `json:"sources"` // CIDRs or single IPs allowed to send UDP timecode, guard allow rules apply when not set
+
+  timeline *Timeline
+  events   *GateEvents
+  guard    *OSCGuard
+  nets     []*net.IPNet
+
+  state     TimecodeState
+  source    TimecodeSource
+  frame     string
+  position  float64
+  lastTime  time.Time // Last received frame, keeps the lock
+  moveTime  time.Time // Last frame which advanced the position, the local clock runs from it
+  relocates uint64
+  mux       sync.RWMutex
+
+  conn     *net.UDPConn
+  doneChan chan struct{}
+  wg       sync.WaitGroup
+}
+
+type TimecodeApp struct {
+  State     TimecodeState  `json:"state"`
+  IsLocked  bool           `json:"isLocked"`
+  Source    TimecodeSource `json:"source"`
+  Timecode  string         `json:"timecode"`
+  Position  float64        `json:"position"`
+  FPS       float64        `json:"fps"`
+  Timeline  string         `json:"timeline"`
+  Age       float64        `json:"age"`
+  Relocates uint64         `json:"relocates"`
+}
+
+func (tc *Timecode) fps() float64 {
+  if tc.FPS != nil {
+    return *tc.FPS
+  }
+  return Timecode_FPS
+}
+
+func (tc *Timecode) freewheel() time.Duration {
+  if tc.Freewheel != nil {
+    return time.Duration(*tc.Freewheel * float64(time.Second))
+  }
+  return Timecode_Freewheel
+}
+
+func (tc *Timecode) jumpThreshold() float64 {
+  if tc.JumpThreshold != nil {
+    return *tc.JumpThreshold
+  }
+  return Timecode_JumpThreshold
+}
+
+// Parse returns seconds of hh:mm:ss:ff, drop-frame separators are accepted but not compensated
+func (tc *Timecode) Parse(value string) (float64, error) {
+  matches := Timecode_RE.FindStringSubmatch(value)
+  if matches == nil {
+    return 0, fmt.Errorf(`Timecode "%s" does not match hh:mm:ss:ff format`, value)
+  }
+
+  var parts [4]int
+  for i := range parts {
+    parts[i], _ = strconv.Atoi(matches[i + 1])
+  }
+  return tc.seconds(parts[0], parts[1], parts[2], parts[3])
+}
+
+func (tc *Timecode) seconds(hh int, mm int, ss int, ff int) (float64, error) {
+  if mm > 59 || ss > 59 || float64(ff) >= math.Ceil(tc.fps()) {
+    return 0, fmt.Errorf("Timecode %02d:%02d:%02d:%02d is out of range", hh, mm, ss, ff)
+  }
+  return float64(hh * 3600 + mm * 60 + ss) + float64(ff) / tc.fps(), nil
+}
+
+func (tc *Timecode) Up(team *Team) error {
+  if tc.fps() <= 0 {
+    return fmt.Errorf("Timecode incorrect fps %f", tc.fps())
+  }
+
+  if tc.timeline = team.GetTimeline(tc.Timeline); tc.timeline == nil {
+    return fmt.Errorf("Timecode timeline %s is not found", tc.Timeline)
+  }
+
+  tc.nets = make([]*net.IPNet, 0, len(tc.Sources))
+  for _, source := range tc.Sources {
+    ipNet, err := oscGuardNet(source)
+    if err != nil {
+      return fmt.Errorf("Timecode %w", err)
+    }
+    tc.nets = append(tc.nets, ipNet)
+  }
+
+  tc.events = team.Events
+  tc.guard = team.OSCGuard
+  tc.state = TimecodeState_Stopped
+  tc.doneChan = make(chan struct{})
+
+  if tc.UDPPort != nil {
+    port := PortValue(*tc.UDPPort)
+    addr := port.UDPAddr()
+    conn, err := net.ListenUDP("udp", &addr)
+    if err != nil {
+      return fmt.Errorf("Timecode UDP listen error: %w", err)
+    }
+    tc.conn = conn
+
+    tc.wg.Add(1)
+    go tc.serveUDP()
+    log.Printf("[Timecode INFO] UDP timecode listening at %s\n", addr.String())
+  }
+
+  tc.wg.Add(1)
+  go tc.processWatchdog()
+
+  return nil
+}
+
+func (tc *Timecode) Shutdown() {
+  close(tc.doneChan)
+  if tc.conn != nil {
+    tc.conn.Close()
+  }
+  tc.wg.Wait()
+}
+
+func (tc *Timecode) serveUDP() {
+  defer tc.wg.Done()
+
+  buffer := make([]byte, Timecode_UDPBuffer)
+  for {
+    n, source, err := tc.conn.ReadFromUDP(buffer)
+    if err != nil {
+      select {
+        case <-tc.doneChan:
+        default:
+          log.Printf("[Timecode ERROR] Error reading from UDP: %v\n", err)
+      }
+      return
+    }
+    if tc.admit(source) == false {
+      continue
+    }
+
+    position, err := tc.Parse(strings.TrimRight(string(buffer[:n]), "\x00\r\n"))
+    if err != nil {
+      log.Printf("[Timecode ERROR] UDP timecode error: %v\n", err)
+      continue
+    }
+    tc.Frame(TimecodeSource_UDP, position)
+  }
+}
+
+// admit checks UDP timecode as OSC timecode is checked by the guard, it chases timelines so it moves robots
+// and is rejected while the gate is disarmed
+func (tc *Timecode) admit(source *net.UDPAddr) bool {
+  address := Timecode_UDPAddress
+  if tc.OSCPath != nil {
+    address = *tc.OSCPath
+  }
+
+  isAllowed := len(tc.nets) == 0 && tc.guard.Allows(source, address) == true
+  for _, ipNet := range tc.nets {
+    if ipNet.Contains(source.IP) == true {
+      isAllowed = true
+      break
+    }
+  }
+  if isAllowed == false {
+    tc.guard.Reject(OSCGuardReason_Source, source, address)
+    return false
+  }
+
+  if tc.guard.IsArmed() == false {
+    tc.guard.Reject(OSCGuardReason_Disarmed, source, address)
+    return false
+  }
+  return true
+}
+
+func (tc *Timecode) OSCPacket(oscPacket *OSCPacket) {
+  values := oscPacket.Values()
+
+  var position float64
+  var err error
+  switch len(values) {
+    case 1:
+      switch value := values[0].(type) {
+        case string:
+          position, err = tc.Parse(value)
+        case OSCSymbol:
+          position, err = tc.Parse(string(value))
+        default:
+          log.Printf("[Timecode ERROR] OSC timecode is not of string value\n")
+          tc.events.Error(oscPacket.Source, GateError_BadType, "", "Timecode is not of string value")
+          return
+      }
+
+    case 4:
+      var parts [4]int
+      for i, value := range values {
+        part, err := OSCInt32(value)
+        if err != nil {
+          log.Printf("[Timecode ERROR] OSC timecode values[%d] error: %v\n", i, err)
+          tc.events.Error(oscPacket.Source, GateError_BadType, "", fmt.Sprintf("Timecode values[%d] error: %v", i, err))
+          return
+        }
+        parts[i] = int(part)
+      }
+      position, err = tc.seconds(parts[0], parts[1], parts[2], parts[3])
+
+    default:
+      log.Printf("[Timecode ERROR] Incorrect OSC timecode values length of %+v\n", values)
+      tc.events.Error(oscPacket.Source, GateError_BadArity, "", fmt.Sprintf("Timecode expects 1 or 4 values, got %d", len(values)))
+      return
+  }
+
+  if err != nil {
+    log.Printf("[Timecode ERROR] OSC timecode error: %v\n", err)
+    tc.events.Error(oscPacket.Source, GateError_BadType, "", err.Error())
+    return
+  }
+  tc.Frame(TimecodeSource_OSC, position)
+}
+
+// Frame chases the timeline to a received timecode position in seconds
+func (tc *Timecode) Frame(source TimecodeSource, position float64) {
+  tc.mux.Lock()
+  defer tc.mux.Unlock()
+
+  now := time.Now()
+  tc.source = source
+  tc.lastTime = now
+
+  // Repeated frames keep the lock, the timeline holds once they last longer than a sender jitter
+  if tc.state != TimecodeState_Stopped && position == tc.position {
+    if tc.state != TimecodeState_Paused && now.Sub(tc.moveTime) > tc.dropout() {
+      log.Printf("[Timecode INFO] Paused at %s\n", tc.frame)
+      tc.timeline.Hold()
+      tc.state = TimecodeState_Paused
+    }
+    return
+  }
+
+  expected := tc.position + now.Sub(tc.moveTime).Seconds()
+  tc.frame = tc.format(position)
+  tc.position = position
+  tc.moveTime = now
+
+  if tc.state == TimecodeState_Stopped || tc.state == TimecodeState_Paused || math.Abs(position - expected) > tc.jumpThreshold() {
+    switch tc.state {
+      case TimecodeState_Stopped:
+        log.Printf("[Timecode INFO] Locked at %s\n", tc.frame)
+      case TimecodeState_Paused:
+        log.Printf("[Timecode INFO] Resumed at %s\n", tc.frame)
+      default:
+        log.Printf("[Timecode INFO] Relocate to %s, drift %.3f seconds\n", tc.frame, position - expected)
+    }
+    tc.relocates++
+    tc.timeline.Locate(position - tc.Offset)
+  }
+
+  tc.state = TimecodeState_Locked
+}
+
+// dropout is the frame gap after which timecode is considered interrupted
+func (tc *Timecode) dropout() time.Duration {
+  return 2 * time.Duration(float64(time.Second) / tc.fps())
+}
+
+func (tc *Timecode) processWatchdog() {
+  defer tc.wg.Done()
+
+  ticker := time.NewTicker(time.Duration(float64(time.Second) / tc.fps()))
+  defer ticker.Stop()
+
+  for {
+    select {
+      case <-tc.doneChan:
+        return
+
+      case <-ticker.C:
+        tc.mux.Lock()
+        age := time.Since(tc.lastTime)
+        switch {
+          case tc.state == TimecodeState_Stopped:
+
+          case age > tc.freewheel():
+            log.Printf("[Timecode WARNING] Lock lost after %s dropout, timeline %s is stopped\n", age, tc.timeline.Name)
+            tc.state = TimecodeState_Stopped
+            tc.timeline.Command(TimelineCommand_Stop, "")
+
+          case age > tc.dropout() && tc.state == TimecodeState_Locked:
+            log.Printf("[Timecode WARNING] Timecode dropout, freewheel\n")
+            tc.state = TimecodeState_Freewheel
+        }
+        tc.mux.Unlock()
+    }
+  }
+}
+
+func (tc *Timecode) format(position float64) string {
+  frames := int(math.Round(position * tc.fps()))
+  fps := int(math.Ceil(tc.fps()))
+  return fmt.Sprintf("%02d:%02d:%02d:%02d", frames / fps / 3600, frames / fps / 60 % 60, frames / fps % 60, frames % fps)
+}
+
+func (tc *Timecode) GetAppData() *TimecodeApp {
+  tc.mux.RLock()
+  defer tc.mux.RUnlock()
+
+  timecodeApp := &TimecodeApp{
+    State:     tc.state,
+    IsLocked:  tc.state != TimecodeState_Stopped,
+    Source:    tc.source,
+    Timecode:  tc.frame,
+    Position:  tc.position,
+    FPS:       tc.fps(),
+    Timeline:  tc.Timeline,
+    Relocates: tc.relocates,
+  }
+
+  if tc.lastTime.IsZero() != true {
+    timecodeApp.Age = time.Since(tc.lastTime).Seconds()
+  }
+
+  if tc.state == TimecodeState_Locked || tc.state == TimecodeState_Freewheel {
+    timecodeApp.Position += time.Since(tc.moveTime).Seconds()
+    timecodeApp.Timecode = tc.format(timecodeApp.Position)
+  }
+
+  return timecodeApp
+}
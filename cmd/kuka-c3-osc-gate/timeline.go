@@ -0,0 +1,490 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type CueActionType string
+
+const (
+  CueActionType_MoveGroup CueActionType = "moveGroup" // Run MoveGroup on bots or on the team
+  CueActionType_Speed     CueActionType = "speed"     // Set axis speed of bots
+  CueActionType_Variable  CueActionType = "variable"  // Write C3 variable of bots
+  CueActionType_OSC       CueActionType = "osc"       // Send OSC message
+)
+
+type TimelineCommand string
+
+const (
+  TimelineCommand_Go   TimelineCommand = "go"   // Fire standby cue and move standby to the next cue
+  TimelineCommand_Back TimelineCommand = "back" // Move standby to the previous cue
+  TimelineCommand_Jump TimelineCommand = "jump" // Move standby to the cue
+  TimelineCommand_Play TimelineCommand = "play" // Fire cues by their absolute time from the standby cue
+  TimelineCommand_Stop TimelineCommand = "stop" // Cancel all pending cues and actions
+)
+
+type CueAction struct {
+  Type  CueActionType `json:"type"`
+  Delay float64       `json:"delay"`
+  Bots  []string      `json:"bots"`
+
+  MoveGroupId  *uint16 `json:"moveGroupId,omitempty"`
+  Velocity     *uint8  `json:"velocity,omitempty"`
+  Acceleration *uint8  `json:"acceleration,omitempty"`
+  Variable     *string `json:"variable,omitempty"`
+  Value        *string `json:"value,omitempty"`
+  Address      *string `json:"address,omitempty"`
+  Path         *string `json:"path,omitempty"`
+  Args         []any   `json:"args,omitempty"`
+}
+
+type Cue struct {
+  Name    string       `json:"name"`
+  At      *float64     `json:"at"`
+  Follow  *float64     `json:"follow"`
+  Actions []*CueAction `json:"actions"`
+}
+
+type Timeline struct {
+  Name string `json:"name"`
+  Cues []*Cue `json:"cues"`
+
+  team *Team
+
+  standby   int
+  lastCue   int
+  isPlaying bool
+  startTime time.Time
+  timers    []*timelineTimer
+  mux       sync.Mutex
+}
+
+// timelineTimer is a pending cue or delayed action, a cancelled timer which already fired does nothing
+type timelineTimer struct {
+  timer       *time.Timer
+  isAction    bool
+  isCancelled bool
+}
+
+type TimelineApp struct {
+  Name      string   `json:"name"`
+  Cues      []string `json:"cues"`
+  Standby   int      `json:"standby"`
+  LastCue   int      `json:"lastCue"`
+  IsPlaying bool     `json:"isPlaying"`
+  Elapsed   float64  `json:"elapsed"`
+}
+
+func (action *CueAction) Check(team *Team) error {
+  for _, name := range action.Bots {
+    if team.GetBotByName(name) == nil {
+      return fmt.Errorf("bot %s is not found", name)
+    }
+  }
+
+  if action.Delay < 0 {
+    return fmt.Errorf("negative delay %f", action.Delay)
+  }
+
+  switch action.Type {
+    case CueActionType_MoveGroup:
+      if action.MoveGroupId == nil {
+        return fmt.Errorf("moveGroup action without moveGroupId")
+      }
+    case CueActionType_Speed:
+      if action.Velocity == nil {
+        return fmt.Errorf("speed action without velocity")
+      }
+    case CueActionType_Variable:
+      if action.Variable == nil || action.Value == nil {
+        return fmt.Errorf("variable action without variable or value")
+      }
+    case CueActionType_OSC:
+      if action.Path == nil {
+        return fmt.Errorf("osc action without path")
+      }
+      if action.Address == nil && team.OSCResponseAddress == nil {
+        return fmt.Errorf("osc action without address")
+      }
+    default:
+      return fmt.Errorf("incorrect action type %q", action.Type)
+  }
+
+  return nil
+}
+
+func (tl *Timeline) Up(team *Team) error {
+  tl.team = team
+  tl.standby = 0
+  tl.lastCue = -1
+  tl.isPlaying = false
+  tl.timers = make([]*timelineTimer, 0)
+
+  if tl.Name == "" {
+    return fmt.Errorf("Timeline name is empty")
+  }
+
+  for i, cue := range tl.Cues {
+    if cue.At != nil && *cue.At < 0 {
+      return fmt.Errorf("Timeline %s cue %d negative time", tl.Name, i)
+    }
+    if cue.Follow != nil && *cue.Follow < 0 {
+      return fmt.Errorf("Timeline %s cue %d negative follow", tl.Name, i)
+    }
+    for j, action := range cue.Actions {
+      if err := action.Check(team); err != nil {
+        return fmt.Errorf("Timeline %s cue %d action %d error: %w", tl.Name, i, j, err)
+      }
+    }
+  }
+
+  return nil
+}
+
+func (tl *Timeline) Shutdown() {
+  tl.mux.Lock()
+  defer tl.mux.Unlock()
+  tl.cancel(true)
+}
+
+// cancel stops pending cues, delayed actions of fired cues are stopped only when isActions is set,
+// timeline mutex must be locked
+func (tl *Timeline) cancel(isActions bool) {
+  timers := tl.timers[:0]
+  for _, t := range tl.timers {
+    if t.isAction == true && isActions == false {
+      timers = append(timers, t)
+      continue
+    }
+    t.timer.Stop()
+    t.isCancelled = true
+  }
+  tl.timers = timers
+  tl.isPlaying = false
+}
+
+// schedule runs fn with locked timeline mutex after delay until the timer is cancelled,
+// timeline mutex must be locked
+func (tl *Timeline) schedule(delay time.Duration, isAction bool, fn func()) {
+  t := &timelineTimer{isAction: isAction}
+  t.timer = time.AfterFunc(delay, func() {
+    tl.mux.Lock()
+    defer tl.mux.Unlock()
+    if t.isCancelled == true {
+      return
+    }
+    for i, other := range tl.timers {
+      if other == t {
+        tl.timers = append(tl.timers[:i], tl.timers[i+1:]...)
+        break
+      }
+    }
+    fn()
+  })
+  tl.timers = append(tl.timers, t)
+}
+
+func (tl *Timeline) CueIndex(cue string) (int, error) {
+  for i, other := range tl.Cues {
+    if other.Name == cue {
+      return i, nil
+    }
+  }
+
+  index, err := strconv.Atoi(cue)
+  if err != nil || index < 0 || index >= len(tl.Cues) {
+    return -1, fmt.Errorf("Timeline %s cue %s is not found", tl.Name, cue)
+  }
+  return index, nil
+}
+
+func (tl *Timeline) Command(command TimelineCommand, cue string) error {
+  tl.mux.Lock()
+  defer tl.mux.Unlock()
+
+  switch command {
+    case TimelineCommand_Go:
+      if tl.standby >= len(tl.Cues) {
+        return fmt.Errorf("Timeline %s has no standby cue", tl.Name)
+      }
+      tl.cancel(false)
+      tl.fire(tl.standby)
+      tl.follow()
+
+    case TimelineCommand_Back:
+      tl.cancel(false)
+      if tl.standby > 0 {
+        tl.standby--
+      }
+
+    case TimelineCommand_Jump:
+      index, err := tl.CueIndex(cue)
+      if err != nil {
+        return err
+      }
+      tl.cancel(false)
+      tl.standby = index
+
+    case TimelineCommand_Play:
+      if cue != "" {
+        index, err := tl.CueIndex(cue)
+        if err != nil {
+          return err
+        }
+        tl.standby = index
+      }
+      tl.cancel(false)
+      tl.play(tl.cueTime(tl.standby))
+
+    case TimelineCommand_Stop:
+      tl.cancel(true)
+
+    default:
+      return fmt.Errorf("Timeline %s incorrect command %q", tl.Name, command)
+  }
+
+  return nil
+}
+
+// Locate plays the timeline from position seconds, standby moves to the first cue at or after it
+func (tl *Timeline) Locate(position float64) {
+  tl.mux.Lock()
+  defer tl.mux.Unlock()
+
+  tl.cancel(false)
+  tl.standby = len(tl.Cues)
+  for i, cue := range tl.Cues {
+    if cue.At != nil && *cue.At >= position {
+      tl.standby = i
+      break
+    }
+  }
+  tl.play(position)
+}
+
+// Hold stops pending cues while chased timecode is paused, delayed actions of fired cues keep running
+func (tl *Timeline) Hold() {
+  tl.mux.Lock()
+  defer tl.mux.Unlock()
+  tl.cancel(false)
+}
+
+// cueTime is the absolute time of cue index or of the nearest earlier timed cue
+func (tl *Timeline) cueTime(index int) float64 {
+  if index >= len(tl.Cues) {
+    index = len(tl.Cues) - 1
+  }
+  for i := index; i >= 0; i-- {
+    if tl.Cues[i].At != nil {
+      return *tl.Cues[i].At
+    }
+  }
+  return 0
+}
+
+// play schedules every timed cue from the standby cue relative to position seconds, timeline mutex must be locked
+func (tl *Timeline) play(position float64) {
+  tl.isPlaying = true
+  tl.startTime = time.Now().Add(-time.Duration(position * float64(time.Second)))
+
+  for i := tl.standby; i < len(tl.Cues); i++ {
+    cue := tl.Cues[i]
+    if cue.At == nil || *cue.At < position {
+      continue
+    }
+
+    index := i
+    tl.schedule(time.Duration((*cue.At - position) * float64(time.Second)), false, func() {
+      tl.fire(index)
+    })
+  }
+}
+
+// follow schedules auto-follow of the standby cue, timeline mutex must be locked
+func (tl *Timeline) follow() {
+  if tl.standby >= len(tl.Cues) || tl.Cues[tl.standby].Follow == nil {
+    return
+  }
+
+  index := tl.standby
+  tl.schedule(time.Duration(*tl.Cues[index].Follow * float64(time.Second)), false, func() {
+    if tl.standby != index {
+      return
+    }
+    tl.fire(index)
+    tl.follow()
+  })
+}
+
+// fire runs every action of cue index and moves standby after it, timeline mutex must be locked
+func (tl *Timeline) fire(index int) {
+  cue := tl.Cues[index]
+  log.Printf("[Timeline %s INFO] GO cue %d %s\n", tl.Name, index, cue.Name)
+
+  tl.lastCue = index
+  tl.standby = index + 1
+
+  for _, action := range cue.Actions {
+    action := action
+    if action.Delay == 0 {
+      go tl.run(cue, action)
+      continue
+    }
+    tl.schedule(time.Duration(action.Delay * float64(time.Second)), true, func() {
+      go tl.run(cue, action)
+    })
+  }
+}
+
+func (tl *Timeline) run(cue *Cue, action *CueAction) {
+  if err := tl.team.RunCueAction(action); err != nil {
+    log.Printf("[Timeline %s ERROR] Cue %s action %s error: %v\n", tl.Name, cue.Name, action.Type, err)
+  }
+}
+
+func (tl *Timeline) GetAppData() *TimelineApp {
+  tl.mux.Lock()
+  defer tl.mux.Unlock()
+
+  timelineApp := &TimelineApp{
+    Name:      tl.Name,
+    Cues:      make([]string, len(tl.Cues)),
+    Standby:   tl.standby,
+    LastCue:   tl.lastCue,
+    IsPlaying: tl.isPlaying,
+  }
+
+  for i, cue := range tl.Cues {
+    timelineApp.Cues[i] = cue.Name
+  }
+
+  if tl.isPlaying == true {
+    timelineApp.Elapsed = time.Since(tl.startTime).Seconds()
+  }
+
+  return timelineApp
+}
+
+func (team *Team) GetTimeline(name string) *Timeline {
+  for _, timeline := range team.Timelines {
+    if timeline.Name == name {
+      return timeline
+    }
+  }
+
+  index, err := strconv.Atoi(name)
+  if err != nil || index < 0 || index >= len(team.Timelines) {
+    return nil
+  }
+  return team.Timelines[index]
+}
+
+func (team *Team) RunCueAction(action *CueAction) error {
+  if action.Type == CueActionType_OSC {
+    return team.cueOSC(action)
+  }
+
+  if action.Type == CueActionType_MoveGroup && len(action.Bots) == 0 {
+    _, err := team.RunMoveGroup(*action.MoveGroupId)
+    return err
+  }
+
+  bots := team.GetBots()
+  if len(action.Bots) > 0 {
+    bots = make([]*Bot, len(action.Bots))
+    for i, name := range action.Bots {
+      bots[i] = team.GetBotByName(name)
+    }
+  }
+
+  var wg sync.WaitGroup
+  errorChan := make(chan error, len(bots))
+  for _, bot := range bots {
+    if bot == nil || bot.IsDegraded() == true {
+      continue
+    }
+
+    wg.Add(1)
+    go func(bot *Bot) {
+      defer wg.Done()
+      var err error
+      switch action.Type {
+        case CueActionType_MoveGroup:
+          err = bot.RunMoveGroup(*action.MoveGroupId)
+        case CueActionType_Speed:
+          acceleration := *action.Velocity
+          if action.Acceleration != nil {
+            acceleration = *action.Acceleration
+          }
+          err = bot.SetAXISSpeed(*action.Velocity, acceleration)
+        case CueActionType_Variable:
+          err = bot.WriteVariable(C3VariableType(*action.Variable), *action.Value)
+      }
+      if err != nil {
+        errorChan <- fmt.Errorf("Bot %s %w", bot.Name, err)
+      }
+    }(bot)
+  }
+  wg.Wait()
+  close(errorChan)
+
+  for err := range errorChan {
+    return err
+  }
+  return nil
+}
+
+func (team *Team) cueOSC(action *CueAction) error {
+  oscPacket := NewOSCPacket()
+  oscPacket.Path = *action.Path
+  for i, arg := range action.Args {
+    // JSON numbers are float64, integral values are sent as int32
+    if value, ok := arg.(float64); ok {
+      if value == math.Trunc(value) && value >= math.MinInt32 && value <= math.MaxInt32 {
+        arg = int32(value)
+      } else {
+        arg = float32(value)
+      }
+    }
+    if err := oscPacket.Append(arg); err != nil {
+      return fmt.Errorf("OSC argument %d error: %w", i, err)
+    }
+  }
+
+  team.settingsMux.RLock()
+  address := team.OSCResponseAddress
+  team.settingsMux.RUnlock()
+  if action.Address != nil {
+    address = action.Address
+  }
+  if address == nil {
+    return fmt.Errorf("OSC action without address")
+  }
+
+  oscClient, err := team.cueOSCClient(*address)
+  if err != nil {
+    return err
+  }
+  return oscClient.Send(oscPacket)
+}
+
+func (team *Team) cueOSCClient(address string) (*OSCClient, error) {
+  team.cueClientsMux.Lock()
+  defer team.cueClientsMux.Unlock()
+
+  if oscClient, ok := team.cueClients[address]; ok {
+    return oscClient, nil
+  }
+
+  oscClient, err := NewOSCClient(address)
+  if err != nil {
+    return nil, fmt.Errorf("OSCClient creation error: %w", err)
+  }
+  team.cueClients[address] = oscClient
+  return oscClient, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+  "bufio"
+  "crypto/sha1"
+  "encoding/base64"
+  "encoding/binary"
+  "fmt"
+  "io"
+  "net"
+  "net/http"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  WebSocket_GUID         = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+  WebSocket_MaxMessage   = 1 << 20
+  WebSocket_WriteTimeout = 5 * time.Second
+
+  WebSocket_Text   byte = 0x1
+  WebSocket_Binary byte = 0x2
+  WebSocket_Close  byte = 0x8
+  WebSocket_Ping   byte = 0x9
+  WebSocket_Pong   byte = 0xA
+)
+
+// WebSocket is a minimal RFC 6455 server connection: no extensions, fragmented messages are joined
+type WebSocket struct {
+  conn     net.Conn
+  reader   *bufio.Reader
+  writeMux sync.Mutex
+}
+
+func IsWebSocketRequest(r *http.Request) bool {
+  return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+    strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocket, error) {
+  if IsWebSocketRequest(r) == false {
+    http.Error(w, "WebSocket upgrade required", http.StatusBadRequest)
+    return nil, fmt.Errorf("WebSocket upgrade headers are not found")
+  }
+
+  key := r.Header.Get("Sec-WebSocket-Key")
+  if key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+    w.Header().Set("Sec-WebSocket-Version", "13")
+    http.Error(w, "Unsupported WebSocket version", http.StatusBadRequest)
+    return nil, fmt.Errorf("WebSocket unsupported version or empty key")
+  }
+
+  hijacker, ok := w.(http.Hijacker)
+  if ok == false {
+    http.Error(w, "WebSocket is not supported", http.StatusInternalServerError)
+    return nil, fmt.Errorf("WebSocket response does not support hijacking")
+  }
+
+  conn, rw, err := hijacker.Hijack()
+  if err != nil {
+    return nil, fmt.Errorf("WebSocket hijack error: %w", err)
+  }
+
+  hash := sha1.Sum([]byte(key + WebSocket_GUID))
+  response := "HTTP/1.1 101 Switching Protocols\r\n" +
+    "Upgrade: websocket\r\n" +
+    "Connection: Upgrade\r\n" +
+    "Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(hash[:]) + "\r\n"
+  if protocol := r.Header.Get("Sec-WebSocket-Protocol"); protocol != "" {
+    response += "Sec-WebSocket-Protocol: " + strings.TrimSpace(strings.Split(protocol, ",")[0]) + "\r\n"
+  }
+  response += "\r\n"
+
+  if _, err := rw.WriteString(response); err != nil {
+    conn.Close()
+    return nil, fmt.Errorf("WebSocket handshake error: %w", err)
+  }
+  if err := rw.Flush(); err != nil {
+    conn.Close()
+    return nil, fmt.Errorf("WebSocket handshake error: %w", err)
+  }
+
+  conn.SetDeadline(time.Time{})
+  return &WebSocket{conn: conn, reader: rw.Reader}, nil
+}
+
+// ReadMessage returns the next text or binary message, control frames are answered internally
+func (ws *WebSocket) ReadMessage() (byte, []byte, error) {
+  var opcode byte = 0
+  var message []byte
+
+  for {
+    fin, frameOpcode, payload, err := ws.readFrame()
+    if err != nil {
+      return 0, nil, err
+    }
+
+    switch frameOpcode {
+      case WebSocket_Ping:
+        if err := ws.WriteMessage(WebSocket_Pong, payload); err != nil {
+          return 0, nil, err
+        }
+        continue
+
+      case WebSocket_Pong:
+        continue
+
+      case WebSocket_Close:
+        ws.WriteMessage(WebSocket_Close, payload)
+        return 0, nil, io.EOF
+
+      case WebSocket_Text, WebSocket_Binary:
+        opcode = frameOpcode
+        message = payload
+
+      case 0x0: // Continuation
+        if opcode == 0 {
+          return 0, nil, fmt.Errorf("WebSocket unexpected continuation frame")
+        }
+        message = append(message, payload...)
+        if len(message) > WebSocket_MaxMessage {
+          return 0, nil, fmt.Errorf("WebSocket message exceeds %d bytes", WebSocket_MaxMessage)
+        }
+
+      default:
+        return 0, nil, fmt.Errorf("WebSocket unsupported opcode 0x%X", frameOpcode)
+    }
+
+    if fin == true {
+      return opcode, message, nil
+    }
+  }
+}
+
+func (ws *WebSocket) readFrame() (bool, byte, []byte, error) {
+  var header [2]byte
+  if _, err := io.ReadFull(ws.reader, header[:]); err != nil {
+    return false, 0, nil, err
+  }
+
+  fin := header[0] & 0x80 != 0
+  opcode := header[0] & 0x0F
+  masked := header[1] & 0x80 != 0
+  size := uint64(header[1] & 0x7F)
+
+  switch size {
+    case 126:
+      var extended uint16
+      if err := binary.Read(ws.reader, binary.BigEndian, &extended); err != nil {
+        return false, 0, nil, err
+      }
+      size = uint64(extended)
+    case 127:
+      if err := binary.Read(ws.reader, binary.BigEndian, &size); err != nil {
+        return false, 0, nil, err
+      }
+  }
+
+  if size > WebSocket_MaxMessage {
+    return false, 0, nil, fmt.Errorf("WebSocket frame exceeds %d bytes", WebSocket_MaxMessage)
+  }
+
+  // Client frames must be masked
+  if masked == false {
+    return false, 0, nil, fmt.Errorf("WebSocket client frame is not masked")
+  }
+
+  var mask [4]byte
+  if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+    return false, 0, nil, err
+  }
+
+  payload := make([]byte, size)
+  if _, err := io.ReadFull(ws.reader, payload); err != nil {
+    return false, 0, nil, err
+  }
+  for i := range payload {
+    payload[i] ^= mask[i % 4]
+  }
+
+  return fin, opcode, payload, nil
+}
+
+func (ws *WebSocket) WriteMessage(opcode byte, data []byte) error {
+  ws.writeMux.Lock()
+  defer ws.writeMux.Unlock()
+
+  frame := make([]byte, 0, len(data) + 10)
+  frame = append(frame, 0x80 | opcode)
+
+  switch size := len(data); {
+    case size < 126:
+      frame = append(frame, byte(size))
+    case size <= 0xFFFF:
+      frame = append(frame, 126)
+      frame = binary.BigEndian.AppendUint16(frame, uint16(size))
+    default:
+      frame = append(frame, 127)
+      frame = binary.BigEndian.AppendUint64(frame, uint64(size))
+  }
+  frame = append(frame, data...)
+
+  ws.conn.SetWriteDeadline(time.Now().Add(WebSocket_WriteTimeout))
+  _, err := ws.conn.Write(frame)
+  return err
+}
+
+func (ws *WebSocket) Close() error {
+  return ws.conn.Close()
+}